@@ -0,0 +1,106 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// SignFunc ký đường dẫn tải file, trả về path kèm chữ ký và thời hạn
+type SignFunc func(path string, ttl time.Duration) string
+
+// Local lưu file trong một thư mục trên máy chủ. Link tải là route của chính ứng dụng
+// (URLPrefix + key) được ký bằng Sign; handler của route phải kiểm tra chữ ký.
+type Local struct {
+	Dir       string
+	URLPrefix string
+	Sign      SignFunc
+}
+
+// NewLocal tạo storage trên thư mục dir, link tải có dạng urlPrefix/<key>
+func NewLocal(dir, urlPrefix string, sign SignFunc) *Local {
+	return &Local{Dir: dir, URLPrefix: urlPrefix, Sign: sign}
+}
+
+func (l *Local) path(key string) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(l.Dir, filepath.FromSlash(key)), nil
+}
+
+// Put ghi vào file tạm rồi đổi tên, nên người đọc không bao giờ thấy file ghi dở
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	target, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if size >= 0 && written != size {
+		return io.ErrUnexpectedEOF
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+// Open mở file; content type đoán theo phần mở rộng của key
+func (l *Local) Open(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	target, err := l.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := os.Open(target)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return file, &Object{Key: key, Size: info.Size(), ContentType: contentType}, nil
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	target, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (l *Local) URL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	return l.Sign(l.URLPrefix+"/"+key, ttl), nil
+}
@@ -0,0 +1,67 @@
+package blobstore
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidKey(t *testing.T) {
+	assert.True(t, ValidKey("attachments/1/abc.jpg"))
+	assert.False(t, ValidKey(""))
+	assert.False(t, ValidKey("/etc/passwd"))
+	assert.False(t, ValidKey("../secret"))
+	assert.False(t, ValidKey("a/../../b"))
+	assert.False(t, ValidKey("a//b"))
+	assert.False(t, ValidKey(`a\b`))
+}
+
+func TestLocal_PutOpenDelete(t *testing.T) {
+	ctx := context.Background()
+	store := NewLocal(t.TempDir(), "/api/files", func(path string, ttl time.Duration) string {
+		return path + "?signed=" + ttl.String()
+	})
+
+	require.NoError(t, store.Put(ctx, "attachments/1/a.txt", strings.NewReader("hello"), 5, "text/plain"))
+
+	r, obj, err := store.Open(ctx, "attachments/1/a.txt")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "hello", string(body))
+	assert.Equal(t, int64(5), obj.Size)
+	assert.True(t, strings.HasPrefix(obj.ContentType, "text/plain"))
+
+	url, err := store.URL(ctx, "attachments/1/a.txt", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/files/attachments/1/a.txt?signed=1m0s", url)
+
+	require.NoError(t, store.Delete(ctx, "attachments/1/a.txt"))
+	require.NoError(t, store.Delete(ctx, "attachments/1/a.txt"))
+	_, _, err = store.Open(ctx, "attachments/1/a.txt")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestLocal_PutShortBodyLeavesNoFile(t *testing.T) {
+	ctx := context.Background()
+	store := NewLocal(t.TempDir(), "/api/files", nil)
+
+	err := store.Put(ctx, "a.txt", strings.NewReader("hel"), 5, "text/plain")
+
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	_, _, err = store.Open(ctx, "a.txt")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestLocal_RejectsInvalidKey(t *testing.T) {
+	store := NewLocal(t.TempDir(), "/api/files", nil)
+
+	err := store.Put(context.Background(), "../escape.txt", strings.NewReader("x"), 1, "text/plain")
+
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
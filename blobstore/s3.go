@@ -0,0 +1,150 @@
+package blobstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxPresignTTL là thời hạn tối đa S3 chấp nhận cho URL đã ký
+const maxPresignTTL = 7 * 24 * time.Hour
+
+// S3Config là cấu hình kết nối tới dịch vụ tương thích S3 (AWS S3, MinIO...)
+type S3Config struct {
+	Endpoint  string // ví dụ "https://s3.amazonaws.com" hoặc "http://localhost:9000"
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PathStyle dùng endpoint/bucket/key thay vì bucket.endpoint/key (MinIO cần bật)
+	PathStyle bool
+}
+
+// S3 lưu file trên bucket tương thích S3, gọi thẳng REST API (PUT/GET/DELETE object)
+// với chữ ký SigV4. Link tải là URL đã ký của chính S3 nên file không đi qua ứng dụng.
+type S3 struct {
+	cfg      S3Config
+	endpoint *url.URL
+	signer   sigV4
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewS3 tạo storage S3, lỗi nếu thiếu cấu hình
+func NewS3(cfg S3Config) (*S3, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, fmt.Errorf("storage: s3 needs endpoint, bucket, access key and secret key")
+	}
+	endpoint, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("storage: invalid s3 endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	return &S3{
+		cfg:      cfg,
+		endpoint: endpoint,
+		signer:   sigV4{accessKey: cfg.AccessKey, secretKey: cfg.SecretKey, region: cfg.Region},
+		client:   &http.Client{Timeout: 5 * time.Minute},
+		now:      time.Now,
+	}, nil
+}
+
+// objectURL là URL của object key theo kiểu path-style hoặc virtual-hosted
+func (s *S3) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	if s.cfg.PathStyle {
+		u.Path = "/" + s.cfg.Bucket + "/" + key
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+	return &u
+}
+
+func (s *S3) do(ctx context.Context, method, key string, body io.Reader, size int64, contentType string) (*http.Response, error) {
+	if !ValidKey(key) {
+		return nil, ErrInvalidKey
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key).String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+		if size == 0 {
+			req.Body = http.NoBody
+		}
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	s.signer.signHeaders(req, s.now())
+	return s.client.Do(req)
+}
+
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if size < 0 {
+		return fmt.Errorf("storage: s3 needs the object size")
+	}
+	resp, err := s.do(ctx, http.MethodPut, key, r, size, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return s3Error(resp, http.StatusOK)
+}
+
+func (s *S3) Open(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, 0, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s3Error(resp, http.StatusOK); err != nil {
+		resp.Body.Close()
+		return nil, nil, err
+	}
+	return resp.Body, &Object{Key: key, Size: resp.ContentLength, ContentType: resp.Header.Get("Content-Type")}, nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, 0, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// S3 trả 204 kể cả khi object không tồn tại
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return s3Error(resp, http.StatusNoContent, http.StatusOK)
+}
+
+func (s *S3) URL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+	if ttl > maxPresignTTL {
+		ttl = maxPresignTTL
+	}
+	return s.signer.presign(http.MethodGet, s.objectURL(key), s.now(), ttl), nil
+}
+
+// s3Error đổi response lỗi của S3 thành error (ErrNotFound với 404)
+func s3Error(resp *http.Response, ok ...int) error {
+	for _, code := range ok {
+		if resp.StatusCode == code {
+			return nil
+		}
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("storage: s3 %s %s: %s %s", resp.Request.Method, resp.Request.URL.Path, resp.Status, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,160 @@
+package blobstore
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Ký request theo AWS Signature Version 4 cho dịch vụ s3
+// (https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-authenticating-requests.html)
+
+const (
+	sigAlgorithm    = "AWS4-HMAC-SHA256"
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	amzDateFormat   = "20060102T150405Z"
+)
+
+type sigV4 struct {
+	accessKey string
+	secretKey string
+	region    string
+}
+
+func (s sigV4) scope(t time.Time) string {
+	return t.Format("20060102") + "/" + s.region + "/s3/aws4_request"
+}
+
+// signHeaders thêm header Authorization cho request, payload không ký (UNSIGNED-PAYLOAD)
+func (s sigV4) signHeaders(req *http.Request, now time.Time) {
+	now = now.UTC()
+	req.Header.Set("X-Amz-Date", now.Format(amzDateFormat))
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	canonical, signed := canonicalHeaders(headers)
+	signature := s.signature(now, req.Method, req.URL, req.URL.Query(), canonical, signed, unsignedPayload)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigAlgorithm, s.accessKey, s.scope(now), signed, signature))
+}
+
+// presign trả về URL đã ký qua query string, hết hạn sau ttl (tối đa 7 ngày)
+func (s sigV4) presign(method string, u *url.URL, now time.Time, ttl time.Duration) string {
+	now = now.UTC()
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", sigAlgorithm)
+	query.Set("X-Amz-Credential", s.accessKey+"/"+s.scope(now))
+	query.Set("X-Amz-Date", now.Format(amzDateFormat))
+	query.Set("X-Amz-Expires", fmt.Sprint(int64(ttl/time.Second)))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	canonical, signed := canonicalHeaders(map[string]string{"host": u.Host})
+	signature := s.signature(now, method, u, query, canonical, signed, unsignedPayload)
+
+	signedURL := *u
+	signedURL.RawQuery = canonicalQuery(query) + "&X-Amz-Signature=" + signature
+	return signedURL.String()
+}
+
+func (s sigV4) signature(now time.Time, method string, u *url.URL, query url.Values, headers, signed, payload string) string {
+	request := strings.Join([]string{
+		method,
+		canonicalURI(u),
+		canonicalQuery(query),
+		headers,
+		signed,
+		payload,
+	}, "\n")
+	toSign := strings.Join([]string{
+		sigAlgorithm,
+		now.Format(amzDateFormat),
+		s.scope(now),
+		hexSHA256(request),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, toSign))
+}
+
+// canonicalHeaders trả về khối header đã chuẩn hóa (mỗi dòng kết thúc bằng \n) và danh sách tên header đã ký
+func canonicalHeaders(headers map[string]string) (string, string) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name + ":" + headers[name] + "\n")
+	}
+	return b.String(), strings.Join(names, ";")
+}
+
+func canonicalURI(u *url.URL) string {
+	if u.Path == "" {
+		return "/"
+	}
+	segments := strings.Split(u.Path, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, key := range keys {
+		values := append([]string{}, query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, uriEncode(key)+"="+uriEncode(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode encode theo quy tắc của SigV4: chỉ giữ A-Z a-z 0-9 - _ . ~
+func uriEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hexSHA256(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,56 @@
+package blobstore
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// Lỗi chung của các backend
+var (
+	ErrNotFound   = errors.New("storage: object not found")
+	ErrInvalidKey = errors.New("storage: invalid key")
+)
+
+// Object là thông tin của một file đã lưu
+type Object struct {
+	Key         string
+	Size        int64
+	ContentType string
+}
+
+// Storage lưu file theo key dạng đường dẫn tương đối ("attachments/12/4f9c....jpg").
+// Backend phải an toàn khi gọi đồng thời.
+type Storage interface {
+	// Put ghi size byte từ r vào key (ghi đè nếu đã có); size < 0 khi chưa biết trước,
+	// backend cần kích thước (S3) sẽ trả lỗi
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open mở file để đọc, ErrNotFound nếu không có
+	Open(ctx context.Context, key string) (io.ReadCloser, *Object, error)
+	// Delete xóa file, không lỗi nếu file không tồn tại
+	Delete(ctx context.Context, key string) error
+	// URL trả về link tải đã ký, hết hạn sau ttl
+	URL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// ValidKey kiểm tra key là đường dẫn tương đối đã chuẩn hóa, không thoát ra ngoài thư mục gốc
+func ValidKey(key string) bool {
+	if key == "" || len(key) > 255 || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	return path.Clean(key) == key && key != "." && !strings.HasPrefix(key, "../") && key != ".."
+}
+
+// NewKey sinh key ngẫu nhiên trong thư mục prefix, ext gồm cả dấu chấm (".jpg") hoặc rỗng
+func NewKey(prefix, ext string) string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return path.Join(prefix, hex.EncodeToString(buf)+ext)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"myapp/database"
+	"myapp/services"
+)
+
+// Danh sách lệnh CLI: go run . <command> [flags]
+var commands = map[string]func(args []string) error{
+	"import-users":           importUsersCommand,
+	"render-posts":           renderPostsCommand,
+	"repair-reaction-counts": repairReactionCountsCommand,
+	"repair-follow-counts":   repairFollowCountsCommand,
+	"slug-posts":             slugPostsCommand,
+	"assign-handles":         assignHandlesCommand,
+}
+
+func runCommand(name string, args []string) error {
+	command, ok := commands[name]
+	if !ok {
+		return fmt.Errorf("unknown command %q", name)
+	}
+	return command(args)
+}
+
+// import-users -file users.csv [-format csv|ndjson] [-dry-run] [-upsert] [-skip-on-error] [-report report.csv]
+func importUsersCommand(args []string) error {
+	fs := flag.NewFlagSet("import-users", flag.ExitOnError)
+	file := fs.String("file", "", "đường dẫn file CSV hoặc NDJSON")
+	format := fs.String("format", "", "csv hoặc ndjson (mặc định đoán theo đuôi file)")
+	dryRun := fs.Bool("dry-run", false, "chỉ kiểm tra, không ghi DB")
+	upsert := fs.Bool("upsert", false, "cập nhật user đã tồn tại theo email")
+	skipOnError := fs.Bool("skip-on-error", false, "bỏ qua dòng lỗi thay vì dừng import")
+	batchSize := fs.Int("batch-size", services.DefaultImportBatchSize, "số dòng mỗi transaction")
+	reportPath := fs.String("report", "", "ghi report từng dòng ra file CSV")
+	fs.Parse(args)
+
+	if *file == "" {
+		return fmt.Errorf("-file is required")
+	}
+	if *format == "" {
+		*format = services.FormatCSV
+		if ext := filepath.Ext(*file); ext == ".ndjson" || ext == ".jsonl" {
+			*format = services.FormatNDJSON
+		}
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	opts := services.UserImportOptions{
+		Format:      *format,
+		DryRun:      *dryRun,
+		Upsert:      *upsert,
+		SkipOnError: *skipOnError,
+		BatchSize:   *batchSize,
+	}
+	report, err := services.ImportUsers(database.DB, f, opts, func(processed int) {
+		log.Printf("🔄 Đã xử lý %d dòng...", processed)
+	})
+	if report == nil {
+		return err
+	}
+
+	if *reportPath != "" {
+		out, createErr := os.Create(*reportPath)
+		if createErr != nil {
+			return createErr
+		}
+		defer out.Close()
+		if writeErr := report.WriteCSV(out); writeErr != nil {
+			return writeErr
+		}
+	}
+
+	summary, _ := json.MarshalIndent(report.Summary(), "", "  ")
+	log.Printf("✅ Import hoàn thành:\n%s", summary)
+	return err
+}
+
+// repair-reaction-counts: tính lại bảng reaction_counts từ bảng reactions
+func repairReactionCountsCommand(args []string) error {
+	fs := flag.NewFlagSet("repair-reaction-counts", flag.ExitOnError)
+	fs.Parse(args)
+
+	rows, err := services.RepairReactionCounts(database.DB)
+	if err != nil {
+		return err
+	}
+	log.Printf("✅ Đã tính lại %d bộ đếm reaction", rows)
+	return nil
+}
+
+// repair-follow-counts: tính lại follower_count/following_count của users từ bảng follows
+func repairFollowCountsCommand(args []string) error {
+	fs := flag.NewFlagSet("repair-follow-counts", flag.ExitOnError)
+	fs.Parse(args)
+
+	rows, err := services.RepairFollowCounts(database.DB)
+	if err != nil {
+		return err
+	}
+	log.Printf("✅ Đã sửa bộ đếm follow của %d user", rows)
+	return nil
+}
+
+// render-posts: render lại content_html của posts từ Markdown
+func renderPostsCommand(args []string) error {
+	fs := flag.NewFlagSet("render-posts", flag.ExitOnError)
+	all := fs.Bool("all", false, "render lại tất cả post, không chỉ các post chưa có HTML")
+	fs.Parse(args)
+
+	rendered, err := services.RenderPostContent(database.DB, *all)
+	if err != nil {
+		return err
+	}
+	log.Printf("✅ Đã render %d post", rendered)
+	return nil
+}
+
+// slug-posts: sinh slug cho các post chưa có (tạo trước khi có cột slug)
+func slugPostsCommand(args []string) error {
+	fs := flag.NewFlagSet("slug-posts", flag.ExitOnError)
+	fs.Parse(args)
+
+	updated, err := services.SlugPosts(database.DB)
+	if err != nil {
+		return err
+	}
+	log.Printf("✅ Đã sinh slug cho %d post", updated)
+	return nil
+}
+
+// assign-handles: sinh handle cho các user chưa có (tạo trước khi có cột handle)
+func assignHandlesCommand(args []string) error {
+	fs := flag.NewFlagSet("assign-handles", flag.ExitOnError)
+	fs.Parse(args)
+
+	updated, err := services.AssignHandles(database.DB)
+	if err != nil {
+		return err
+	}
+	log.Printf("✅ Đã sinh handle cho %d user", updated)
+	return nil
+}
@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"myapp/database"
+	"myapp/middleware"
+	"myapp/models"
+	"myapp/services"
+)
+
+// maxFilenameLength là độ dài tối đa của tên file gốc được lưu (cột VARCHAR(255))
+const maxFilenameLength = 255
+
+// GET /posts/:id/attachments — file đính kèm của post (ai xem được post thì xem được), cũ trước
+func GetPostAttachments(c *gin.Context) {
+	if !requireFiles(c) {
+		return
+	}
+	post, ok := findPost(c, database.DB.Select("id", "author_id", "status"))
+	if !ok {
+		return
+	}
+
+	var attachments []models.Attachment
+	if err := database.DB.Where("post_id = ?", post.ID).Order("id").Find(&attachments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	views := make([]models.AttachmentView, 0, len(attachments))
+	for i := range attachments {
+		view, err := attachmentView(c, &attachments[i])
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		views = append(views, *view)
+	}
+	c.JSON(http.StatusOK, views)
+}
+
+// POST /posts/:id/attachments — upload file đính kèm (multipart field "file", tối đa
+// ATTACHMENT_MAX_SIZE byte, mặc định 25 MB; chỉ tác giả hoặc admin). Định dạng được phép:
+// JPEG, PNG, GIF, WebP, PDF, text, ZIP; ảnh được bỏ EXIF và tạo thumbnail.
+func UploadPostAttachment(c *gin.Context) {
+	if !requireFiles(c) {
+		return
+	}
+	post, ok := findPost(c, database.DB.Select("id", "author_id", "status"))
+	if !ok || !canModifyPost(c, post) {
+		return
+	}
+	user, _ := middleware.CurrentUser(c)
+
+	header, ok := readUpload(c, uploadLimit("ATTACHMENT_MAX_SIZE", 25<<20))
+	if !ok {
+		return
+	}
+	upload, closeFile, ok := prepareUpload(c, header, services.UploadAttachment)
+	if !ok {
+		return
+	}
+	defer closeFile()
+
+	attachment, err := saveAttachment(c, post.ID, user.ID, header.Filename, upload)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	view, err := attachmentView(c, attachment)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, view)
+}
+
+// DELETE /posts/:id/attachments/:attachment_id — chỉ tác giả hoặc admin
+func DeletePostAttachment(c *gin.Context) {
+	if !requireFiles(c) {
+		return
+	}
+	post, ok := findPost(c, database.DB.Select("id", "author_id", "status"))
+	if !ok || !canModifyPost(c, post) {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("attachment_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment id"})
+		return
+	}
+
+	var attachment models.Attachment
+	if err := database.DB.Where("post_id = ?", post.ID).First(&attachment, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	if err := database.DB.Delete(&attachment).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	services.DeleteFiles(c.Request.Context(), Files, attachment.Keys()...)
+	c.Status(http.StatusNoContent)
+}
+
+// saveAttachment ghi file vào storage rồi lưu dòng attachment; lưu DB lỗi thì xóa file đã ghi
+func saveAttachment(c *gin.Context, postID, uploaderID uint, filename string, upload *services.PreparedUpload) (*models.Attachment, error) {
+	ctx := c.Request.Context()
+	key, thumbKey, err := services.StoreUpload(ctx, Files, upload, fmt.Sprintf("attachments/%d", postID))
+	if err != nil {
+		return nil, err
+	}
+
+	attachment := models.Attachment{
+		PostID:       postID,
+		UploaderID:   uploaderID,
+		StorageKey:   key,
+		ThumbnailKey: thumbKey,
+		Filename:     attachmentFilename(filename, upload.Ext),
+		ContentType:  upload.ContentType,
+		Size:         upload.Size,
+	}
+	if upload.Width > 0 {
+		attachment.Width, attachment.Height = &upload.Width, &upload.Height
+	}
+	if err := database.DB.Create(&attachment).Error; err != nil {
+		services.DeleteFiles(ctx, Files, attachment.Keys()...)
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// attachmentFilename chỉ giữ phần tên file (bỏ đường dẫn client gửi kèm), cắt bớt nếu quá dài
+func attachmentFilename(name, ext string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" || name == "" {
+		name = "file" + ext
+	}
+	if len(name) > maxFilenameLength {
+		// Giữ phần cuối để không mất phần mở rộng
+		name = strings.ToValidUTF8(name[len(name)-maxFilenameLength:], "")
+	}
+	return name
+}
+
+// attachmentView gắn link tải đã ký vào attachment
+func attachmentView(c *gin.Context, attachment *models.Attachment) (*models.AttachmentView, error) {
+	url, err := fileURL(c, &attachment.StorageKey)
+	if err != nil {
+		return nil, err
+	}
+	thumbURL, err := fileURL(c, attachment.ThumbnailKey)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AttachmentView{Attachment: *attachment, URL: url, ThumbnailURL: thumbURL}, nil
+}
@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"myapp/blobstore"
+	"myapp/database"
+	"myapp/models"
+	"myapp/services"
+)
+
+// useLocalFiles cấu hình Files là storage local trong thư mục tạm cho đến hết test
+func useLocalFiles(t *testing.T) blobstore.Storage {
+	store := blobstore.NewLocal(t.TempDir(), "/api/files", services.SignPath)
+	Files = store
+	t.Cleanup(func() { Files = nil })
+	return store
+}
+
+// newUploadContext tạo request multipart với field "file"
+func newUploadContext(t *testing.T, user *models.User, path, filename string, data []byte) (*gin.Context, *httptest.ResponseRecorder) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", filename)
+	require.NoError(t, err)
+	part.Write(data)
+	require.NoError(t, form.Close())
+
+	c, w := newAuthedContext(user, "POST", path, nil)
+	c.Request.Body = io.NopCloser(&body)
+	c.Request.ContentLength = int64(body.Len())
+	c.Request.Header.Set("Content-Type", form.FormDataContentType())
+	return c, w
+}
+
+func TestUploadPostAttachment_StoresFileAndReturnsSignedLink(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	useLocalFiles(t)
+
+	var img bytes.Buffer
+	require.NoError(t, png.Encode(&img, image.NewGray(image.Rect(0, 0, 20, 10))))
+
+	mock.ExpectQuery("SELECT `id`,`author_id`,`status` FROM `posts` WHERE `posts`.`id` = \\?").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "author_id", "status"}).AddRow(1, 7, models.PostPublished))
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `attachments`").
+		WithArgs(1, 7, sqlmock.AnyArg(), sqlmock.AnyArg(), "photo.png", "image/png", sqlmock.AnyArg(), 20, 10, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(5, 1))
+	mock.ExpectCommit()
+
+	c, w := newUploadContext(t, &models.User{ID: 7}, "/posts/1/attachments", `C:\Users\me\photo.png`, img.Bytes())
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	UploadPostAttachment(c)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var view models.AttachmentView
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &view))
+	assert.Equal(t, uint(5), view.ID)
+	assert.Equal(t, "photo.png", view.Filename)
+	assert.NotEmpty(t, view.ThumbnailURL)
+
+	// Link đã ký tải được file vừa upload
+	link, err := url.Parse(view.URL)
+	require.NoError(t, err)
+	dc, dw := newAuthedContext(nil, "GET", view.URL, nil)
+	dc.Params = gin.Params{{Key: "key", Value: link.Path[len("/api/files"):]}}
+	DownloadFile(dc)
+	assert.Equal(t, http.StatusOK, dw.Code)
+	assert.Equal(t, "image/png", dw.Header().Get("Content-Type"))
+	assert.Equal(t, "nosniff", dw.Header().Get("X-Content-Type-Options"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUploadPostAttachment_RejectsUnsupportedType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	useLocalFiles(t)
+
+	mock.ExpectQuery("SELECT `id`,`author_id`,`status` FROM `posts`").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "author_id", "status"}).AddRow(1, 7, models.PostPublished))
+
+	// Đuôi .png nhưng nội dung là file thực thi
+	c, w := newUploadContext(t, &models.User{ID: 7}, "/posts/1/attachments", "photo.png", []byte("MZ\x90\x00\x03\x00\x00\x00"))
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	UploadPostAttachment(c)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUploadAvatar_WithoutStorage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	Files = nil
+
+	c, w := newUploadContext(t, &models.User{ID: 7}, "/users/me/avatar", "me.png", []byte("x"))
+	UploadAvatar(c)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestDownloadFile_RejectsUnsignedLink(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := useLocalFiles(t)
+	require.NoError(t, store.Put(t.Context(), "attachments/1/a.txt", bytes.NewReader([]byte("hi")), 2, "text/plain"))
+
+	c, w := newAuthedContext(nil, "GET", "/api/files/attachments/1/a.txt?expires=9999999999&signature=bad", nil)
+	c.Params = gin.Params{{Key: "key", Value: "/attachments/1/a.txt"}}
+	DownloadFile(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -6,6 +6,7 @@ import (
 
 	"myapp/config"
 	"myapp/database"
+	"myapp/middleware"
 	"myapp/models"
 
 	"github.com/gin-gonic/gin"
@@ -17,27 +18,27 @@ var jwtSecret = []byte(config.GetEnv("JWT_SECRET", "my_secret_key"))
 // POST /auth/login
 func Login(c *gin.Context) {
 	var body struct {
-		Email string `json:"email"`
+		Email    string `json:"email"`
+		Password string `json:"password" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&body); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Kiểm tra user trong DB
+	// Kiểm tra user trong DB; sai email hay sai mật khẩu đều trả cùng một lỗi
 	var user models.User
-	if err := database.DB.Where("email = ?", body.Email).First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email"})
+	if err := database.DB.Where("email = ?", body.Email).First(&user).Error; err != nil || !user.CheckPassword(body.Password) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+		return
+	}
+	if user.IsSuspended(time.Now()) {
+		c.JSON(http.StatusForbidden, gin.H{"error": middleware.SuspendedMessage(&user)})
 		return
 	}
 
 	// Tạo JWT token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.ID,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(), // hết hạn sau 24h
-	})
-
-	tokenString, err := token.SignedString(jwtSecret)
+	tokenString, err := generateToken(&user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create token"})
 		return
@@ -45,3 +46,13 @@ func Login(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"token": tokenString})
 }
+
+// generateToken tạo JWT cho user, gắn kèm token_version để có thể thu hồi
+func generateToken(user *models.User) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": user.ID,
+		"ver":     user.TokenVersion,
+		"exp":     time.Now().Add(time.Hour * 24).Unix(), // hết hạn sau 24h
+	})
+	return token.SignedString(jwtSecret)
+}
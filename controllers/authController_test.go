@@ -15,6 +15,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"myapp/database"
+	"myapp/models"
 
 	"gorm.io/gorm"
 )
@@ -39,8 +40,8 @@ func TestLogin_Success(t *testing.T) {
 	database.DB = gormDB
 
 	// Mock SQL expectations - tìm user theo email
-	rows := sqlmock.NewRows([]string{"id", "name", "email", "phone"}).
-		AddRow(1, "John Doe", "john@example.com", "1234567890")
+	rows := sqlmock.NewRows([]string{"id", "name", "email", "phone", "password"}).
+		AddRow(1, "John Doe", "john@example.com", "1234567890", "secret123")
 
 	mock.ExpectQuery("SELECT \\* FROM `users` WHERE email = \\? ORDER BY `users`.`id` LIMIT \\?").
 		WithArgs("john@example.com", 1).
@@ -48,7 +49,8 @@ func TestLogin_Success(t *testing.T) {
 
 	// Create request
 	loginData := map[string]string{
-		"email": "john@example.com",
+		"email":    "john@example.com",
+		"password": "secret123",
 	}
 	body, _ := json.Marshal(loginData)
 	req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
@@ -127,7 +129,8 @@ func TestLogin_UserNotFound(t *testing.T) {
 
 	// Create request
 	loginData := map[string]string{
-		"email": "notfound@example.com",
+		"email":    "notfound@example.com",
+		"password": "secret123",
 	}
 	body, _ := json.Marshal(loginData)
 	req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
@@ -147,7 +150,7 @@ func TestLogin_UserNotFound(t *testing.T) {
 	var response map[string]string
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Equal(t, "Invalid email", response["error"])
+	assert.Equal(t, "Invalid email or password", response["error"])
 
 	// Verify all expectations were met
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -161,7 +164,8 @@ func TestLogin_EmptyEmail(t *testing.T) {
 
 	// Create request with empty email
 	loginData := map[string]string{
-		"email": "",
+		"email":    "",
+		"password": "secret123",
 	}
 	body, _ := json.Marshal(loginData)
 	req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
@@ -190,8 +194,8 @@ func TestLogin_TokenExpiration(t *testing.T) {
 	defer os.Unsetenv("JWT_SECRET")
 
 	// Mock SQL expectations
-	rows := sqlmock.NewRows([]string{"id", "name", "email", "phone"}).
-		AddRow(1, "John Doe", "john@example.com", "1234567890")
+	rows := sqlmock.NewRows([]string{"id", "name", "email", "phone", "password"}).
+		AddRow(1, "John Doe", "john@example.com", "1234567890", "secret123")
 
 	mock.ExpectQuery("SELECT \\* FROM `users` WHERE email = \\? ORDER BY `users`.`id` LIMIT \\?").
 		WithArgs("john@example.com", 1).
@@ -199,7 +203,8 @@ func TestLogin_TokenExpiration(t *testing.T) {
 
 	// Create request
 	loginData := map[string]string{
-		"email": "john@example.com",
+		"email":    "john@example.com",
+		"password": "secret123",
 	}
 	body, _ := json.Marshal(loginData)
 	req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
@@ -243,7 +248,8 @@ func TestLogin_DatabaseError(t *testing.T) {
 
 	// Create request
 	loginData := map[string]string{
-		"email": "john@example.com",
+		"email":    "john@example.com",
+		"password": "secret123",
 	}
 	body, _ := json.Marshal(loginData)
 	req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
@@ -263,5 +269,64 @@ func TestLogin_DatabaseError(t *testing.T) {
 	var response map[string]string
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Equal(t, "Invalid email", response["error"])
+	assert.Equal(t, "Invalid email or password", response["error"])
+}
+
+// newLoginContext tạo context cho POST /auth/login với email và mật khẩu
+func newLoginContext(email, password string) (*gin.Context, *httptest.ResponseRecorder) {
+	body, _ := json.Marshal(map[string]string{"email": email, "password": password})
+	req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	return c, w
+}
+
+func TestLogin_MissingPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	c, w := newLoginContext("john@example.com", "")
+	Login(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLogin_OldPasswordRejectedAfterChange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `users` SET `password`=\\?,`token_version`=\\?").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	user := &models.User{ID: 1, Email: "john@example.com", Password: "old-password"}
+	body, _ := json.Marshal(map[string]string{"current_password": "old-password", "new_password": "new-password"})
+	c, w := newAuthedContext(user, "POST", "/users/me/password", body)
+	ChangePassword(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	for _, tt := range []struct {
+		password string
+		status   int
+	}{
+		{"old-password", http.StatusUnauthorized},
+		{"new-password", http.StatusOK},
+	} {
+		mock.ExpectQuery("SELECT \\* FROM `users` WHERE email = \\?").
+			WithArgs("john@example.com", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "password", "token_version"}).
+				AddRow(1, "john@example.com", user.Password, 1))
+
+		c, w := newLoginContext("john@example.com", tt.password)
+		Login(c)
+
+		assert.Equal(t, tt.status, w.Code, tt.password)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"myapp/database"
+	"myapp/middleware"
+	"myapp/services"
+)
+
+// PUT /users/me/avatar — upload avatar (multipart field "file", JPEG/PNG/GIF, tối đa
+// AVATAR_MAX_SIZE byte, mặc định 5 MB). Ảnh được cắt vuông, bỏ EXIF và tạo thumbnail.
+func UploadAvatar(c *gin.Context) {
+	if !requireFiles(c) {
+		return
+	}
+	user, _ := middleware.CurrentUser(c)
+	header, ok := readUpload(c, uploadLimit("AVATAR_MAX_SIZE", 5<<20))
+	if !ok {
+		return
+	}
+	upload, closeFile, ok := prepareUpload(c, header, services.UploadAvatar)
+	if !ok {
+		return
+	}
+	defer closeFile()
+
+	ctx := c.Request.Context()
+	key, thumbKey, err := services.StoreUpload(ctx, Files, upload, fmt.Sprintf("avatars/%d", user.ID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	// avatar_key là cột chỉ đọc với GORM; không tăng version của user
+	if err := database.DB.Exec("UPDATE users SET avatar_key = ?, avatar_thumb_key = ? WHERE id = ?", key, thumbKey, user.ID).Error; err != nil {
+		services.DeleteFiles(ctx, Files, avatarKeys(&key, thumbKey)...)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	services.DeleteFiles(ctx, Files, avatarKeys(user.AvatarKey, user.AvatarThumbKey)...)
+
+	writeAvatar(c, &key, thumbKey)
+}
+
+// DELETE /users/me/avatar
+func DeleteAvatar(c *gin.Context) {
+	if !requireFiles(c) {
+		return
+	}
+	user, _ := middleware.CurrentUser(c)
+	if err := database.DB.Exec("UPDATE users SET avatar_key = NULL, avatar_thumb_key = NULL WHERE id = ?", user.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	services.DeleteFiles(c.Request.Context(), Files, avatarKeys(user.AvatarKey, user.AvatarThumbKey)...)
+	c.Status(http.StatusNoContent)
+}
+
+// GET /users/:id/avatar — chuyển hướng 302 tới link tải đã ký của avatar (?size=thumb cho thumbnail)
+func GetAvatar(c *gin.Context) {
+	if !requireFiles(c) {
+		return
+	}
+	user, ok := findUser(c, database.DB.Select("id", "avatar_key", "avatar_thumb_key"))
+	if !ok {
+		return
+	}
+	key := user.AvatarKey
+	if c.Query("size") == "thumb" {
+		key = user.AvatarThumbKey
+	}
+	if key == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User has no avatar"})
+		return
+	}
+	url, err := fileURL(c, key)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Cache-Control", "private, max-age=60")
+	c.Redirect(http.StatusFound, url)
+}
+
+// writeAvatar trả về link tải đã ký của avatar và thumbnail
+func writeAvatar(c *gin.Context, key, thumbKey *string) {
+	url, err := fileURL(c, key)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	thumbURL, err := fileURL(c, thumbKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"avatar_url": url, "thumbnail_url": thumbURL})
+}
+
+func avatarKeys(keys ...*string) []string {
+	var out []string
+	for _, key := range keys {
+		if key != nil {
+			out = append(out, *key)
+		}
+	}
+	return out
+}
@@ -0,0 +1,296 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"myapp/database"
+	"myapp/models"
+	"myapp/services"
+)
+
+// errCategoryCycle trả về khi đổi parent tạo thành vòng lặp trong cây danh mục
+var errCategoryCycle = errors.New("category cannot be moved under itself or its descendants")
+
+type categoryInput struct {
+	Name     string `json:"name" binding:"required,min=1,max=100"`
+	ParentID *uint  `json:"parent_id"`
+}
+
+// GET /categories — toàn bộ cây danh mục
+func GetCategories(c *gin.Context) {
+	var categories []models.Category
+	if err := database.DB.Order("name").Find(&categories).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	jsonWithETag(c, http.StatusOK, categoryTree(categories, nil))
+}
+
+// categoryTree dựng cây con của parent từ danh sách phẳng
+func categoryTree(categories []models.Category, parent *uint) []models.Category {
+	tree := []models.Category{}
+	for _, category := range categories {
+		if (parent == nil && category.ParentID == nil) || (parent != nil && category.ParentID != nil && *category.ParentID == *parent) {
+			id := category.ID
+			category.Children = categoryTree(categories, &id)
+			tree = append(tree, category)
+		}
+	}
+	return tree
+}
+
+// POST /categories (admin)
+func CreateCategory(c *gin.Context) {
+	var body categoryInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category := models.Category{Name: body.Name, Slug: services.Slugify(body.Name)}
+	if category.Slug == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name must contain letters or digits"})
+		return
+	}
+	if body.ParentID != nil && *body.ParentID != 0 {
+		if !categoryExists(c, *body.ParentID) {
+			return
+		}
+		category.ParentID = body.ParentID
+	}
+	if !categorySlugAvailable(c, category.Slug, 0) {
+		return
+	}
+
+	if err := database.DB.Create(&category).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, category)
+}
+
+// PATCH /categories/:id (admin) — đổi tên và/hoặc chuyển sang parent khác (parent_id = 0 là gốc)
+func UpdateCategory(c *gin.Context) {
+	category, ok := loadCategory(c)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Name     *string `json:"name" binding:"omitempty,min=1,max=100"`
+		ParentID *uint   `json:"parent_id"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if body.Name != nil && *body.Name != category.Name {
+		slug := services.Slugify(*body.Name)
+		if slug == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "name must contain letters or digits"})
+			return
+		}
+		if !categorySlugAvailable(c, slug, category.ID) {
+			return
+		}
+		updates["name"], updates["slug"] = *body.Name, slug
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if body.ParentID != nil {
+			if *body.ParentID == 0 {
+				updates["parent_id"] = nil
+			} else {
+				// Khóa các danh mục trên đường lên gốc để hai lần di chuyển song song không tạo vòng
+				if err := checkCategoryParent(tx, category.ID, *body.ParentID); err != nil {
+					return err
+				}
+				updates["parent_id"] = *body.ParentID
+			}
+		}
+		if len(updates) == 0 {
+			return nil
+		}
+		return tx.Model(category).Updates(updates).Error
+	})
+	switch {
+	case errors.Is(err, errCategoryCycle):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parent category not found"})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, category)
+	}
+}
+
+// checkCategoryParent đi ngược từ parent lên gốc, báo lỗi nếu gặp lại chính category
+func checkCategoryParent(tx *gorm.DB, id, parentID uint) error {
+	for current := &parentID; current != nil; {
+		if *current == id {
+			return errCategoryCycle
+		}
+		var parent models.Category
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "parent_id").First(&parent, *current).Error; err != nil {
+			return err
+		}
+		current = parent.ParentID
+	}
+	return nil
+}
+
+// DELETE /categories/:id (admin) — chỉ xóa được danh mục không còn danh mục con
+func DeleteCategory(c *gin.Context) {
+	category, ok := loadCategory(c)
+	if !ok {
+		return
+	}
+
+	var children int64
+	if err := database.DB.Model(&models.Category{}).Where("parent_id = ?", category.ID).Count(&children).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if children > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Category still has subcategories"})
+		return
+	}
+
+	if err := database.DB.Delete(category).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GET /posts/:id/categories
+func GetPostCategories(c *gin.Context) {
+	post, ok := loadPost(c)
+	if !ok {
+		return
+	}
+	categories, err := postCategories(database.DB, post.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, categories)
+}
+
+// PUT /posts/:id/categories — thay toàn bộ danh mục của post (chỉ tác giả hoặc admin)
+func SetPostCategories(c *gin.Context) {
+	post, ok := loadPost(c)
+	if !ok || !canModifyPost(c, post) {
+		return
+	}
+
+	var body struct {
+		CategoryIDs []uint `json:"category_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ids := uniqueIDs(body.CategoryIDs)
+
+	if len(ids) > 0 {
+		var count int64
+		if err := database.DB.Model(&models.Category{}).Where("id IN ?", ids).Count(&count).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if int(count) != len(ids) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Some categories do not exist"})
+			return
+		}
+	}
+
+	var categories []models.Category
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("post_id = ?", post.ID).Delete(&models.PostCategory{}).Error; err != nil {
+			return err
+		}
+		if len(ids) > 0 {
+			links := make([]models.PostCategory, len(ids))
+			for i, id := range ids {
+				links[i] = models.PostCategory{PostID: post.ID, CategoryID: id}
+			}
+			if err := tx.Create(&links).Error; err != nil {
+				return err
+			}
+		}
+		var err error
+		categories, err = postCategories(tx, post.ID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, categories)
+}
+
+// postCategories trả về các danh mục của post
+func postCategories(db *gorm.DB, postID uint) ([]models.Category, error) {
+	categories := []models.Category{}
+	err := db.Joins("JOIN post_categories ON post_categories.category_id = categories.id").
+		Where("post_categories.post_id = ?", postID).Order("categories.name").Find(&categories).Error
+	return categories, err
+}
+
+// loadCategory tìm danh mục theo :id, tự ghi response 400/404/500 nếu lỗi
+func loadCategory(c *gin.Context) (*models.Category, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category id"})
+		return nil, false
+	}
+
+	var category models.Category
+	if err := database.DB.First(&category, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return nil, false
+	}
+	return &category, true
+}
+
+// categoryExists kiểm tra danh mục cha tồn tại, ghi response 400/500 nếu không
+func categoryExists(c *gin.Context, id uint) bool {
+	var count int64
+	if err := database.DB.Model(&models.Category{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	if count == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parent category not found"})
+		return false
+	}
+	return true
+}
+
+// categorySlugAvailable ghi response 409 nếu slug đã thuộc danh mục khác
+func categorySlugAvailable(c *gin.Context, slug string, exceptID uint) bool {
+	var count int64
+	if err := database.DB.Model(&models.Category{}).Where("slug = ? AND id <> ?", slug, exceptID).Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "A category with this name already exists"})
+		return false
+	}
+	return true
+}
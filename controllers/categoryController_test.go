@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"myapp/database"
+	"myapp/models"
+)
+
+var categoryColumns = []string{"id", "name", "slug", "parent_id", "created_at", "updated_at"}
+
+func TestCategoryTree(t *testing.T) {
+	one, two := uint(1), uint(2)
+	tree := categoryTree([]models.Category{
+		{ID: 1, Name: "Backend"},
+		{ID: 2, Name: "Go", ParentID: &one},
+		{ID: 3, Name: "Generics", ParentID: &two},
+		{ID: 4, Name: "Frontend"},
+	}, nil)
+
+	assert.Len(t, tree, 2)
+	assert.Equal(t, "Go", tree[0].Children[0].Name)
+	assert.Equal(t, "Generics", tree[0].Children[0].Children[0].Name)
+	assert.Empty(t, tree[1].Children)
+}
+
+func TestUpdateCategory_RejectsCycle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT \\* FROM `categories` WHERE `categories`.`id` = \\?").
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows(categoryColumns).AddRow(1, "Backend", "backend", nil, time.Now(), time.Now()))
+	mock.ExpectBegin()
+	// Danh mục 3 là con của 2, 2 là con của 1 => chuyển 1 xuống 3 tạo vòng
+	mock.ExpectQuery("SELECT `id`,`parent_id` FROM `categories` WHERE `categories`.`id` = \\? .* FOR UPDATE").
+		WithArgs(3, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id"}).AddRow(3, 2))
+	mock.ExpectQuery("SELECT `id`,`parent_id` FROM `categories` WHERE `categories`.`id` = \\? .* FOR UPDATE").
+		WithArgs(2, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id"}).AddRow(2, 1))
+	mock.ExpectRollback()
+
+	c, w := newAuthedContext(&models.User{ID: 1, Role: models.RoleAdmin}, "PATCH", "/categories/1", []byte(`{"parent_id":3}`))
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	UpdateCategory(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "descendants")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteCategory_WithChildren(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT \\* FROM `categories` WHERE `categories`.`id` = \\?").
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows(categoryColumns).AddRow(1, "Backend", "backend", nil, time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `categories` WHERE parent_id = \\?").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	c, w := newAuthedContext(&models.User{ID: 1, Role: models.RoleAdmin}, "DELETE", "/categories/1", nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	DeleteCategory(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateCategory_DuplicateSlug(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `categories` WHERE slug = \\? AND id <> \\?").
+		WithArgs("web-dev", 0).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	c, w := newAuthedContext(&models.User{ID: 1, Role: models.RoleAdmin}, "POST", "/categories", []byte(`{"name":"Web Dev"}`))
+	CreateCategory(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,310 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"myapp/config"
+	"myapp/database"
+	"myapp/middleware"
+	"myapp/models"
+	"myapp/services"
+)
+
+// Độ sâu tối đa của cây comment (comment gốc có depth 0)
+const maxCommentDepth = 8
+
+// commentEditWindow là khoảng thời gian tác giả được sửa comment sau khi đăng,
+// cấu hình qua COMMENT_EDIT_WINDOW (vd: 15m)
+func commentEditWindow() time.Duration {
+	window, err := time.ParseDuration(config.GetEnv("COMMENT_EDIT_WINDOW", "15m"))
+	if err != nil || window <= 0 {
+		return 15 * time.Minute
+	}
+	return window
+}
+
+type commentInput struct {
+	Content  string `json:"content" binding:"required,min=1,max=10000"`
+	ParentID *uint  `json:"parent_id"`
+}
+
+// GET /posts/:id/comments — phân trang theo thread gốc (?page=&per_page=)
+//   - view=tree (mặc định): mỗi thread là một cây lồng nhau qua "replies"
+//   - view=flat: danh sách phẳng, các comment cùng thread đứng liền nhau theo thứ tự duyệt cây
+func GetComments(c *gin.Context) {
+	view := c.DefaultQuery("view", "tree")
+	if view != "tree" && view != "flat" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "view must be tree or flat"})
+		return
+	}
+	page, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+	post, ok := loadPost(c)
+	if !ok {
+		return
+	}
+
+	roots := database.DB.Model(&models.Comment{}).
+		Where("post_id = ? AND parent_id IS NULL", post.ID).
+		Session(&gorm.Session{})
+
+	var total int64
+	if err := roots.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var threads []models.Comment
+	if err := page.apply(roots).Order("id").Find(&threads).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var replies []models.Comment
+	if len(threads) > 0 {
+		rootIDs := make([]uint, len(threads))
+		for i, thread := range threads {
+			rootIDs[i] = thread.ID
+		}
+		if err := database.DB.Where("root_id IN ?", rootIDs).Order("id").Find(&replies).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	children := map[uint][]models.Comment{}
+	for _, reply := range replies {
+		children[*reply.ParentID] = append(children[*reply.ParentID], reply)
+	}
+
+	result := make([]models.CommentView, 0, len(threads)+len(replies))
+	for _, thread := range threads {
+		if view == "tree" {
+			result = append(result, commentTree(thread, children))
+		} else {
+			result = appendCommentThread(result, thread, children)
+		}
+	}
+
+	page.setHeaders(c, total)
+	jsonWithETag(c, http.StatusOK, result)
+}
+
+// commentTree dựng cây replies cho comment
+func commentTree(comment models.Comment, children map[uint][]models.Comment) models.CommentView {
+	view := comment.View()
+	for _, child := range children[comment.ID] {
+		view.Replies = append(view.Replies, commentTree(child, children))
+	}
+	return view
+}
+
+// appendCommentThread thêm comment và các reply theo thứ tự duyệt cây (pre-order)
+func appendCommentThread(list []models.CommentView, comment models.Comment, children map[uint][]models.Comment) []models.CommentView {
+	list = append(list, comment.View())
+	for _, child := range children[comment.ID] {
+		list = appendCommentThread(list, child, children)
+	}
+	return list
+}
+
+// POST /posts/:id/comments — trả lời comment khác bằng parent_id
+func CreateComment(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+	post, ok := loadPost(c)
+	if !ok {
+		return
+	}
+
+	var body commentInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment := models.Comment{PostID: post.ID, AuthorID: user.ID, Content: body.Content}
+	if body.ParentID != nil {
+		var parent models.Comment
+		err := database.DB.Where("post_id = ?", post.ID).First(&parent, *body.ParentID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parent comment not found on this post"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if parent.IsDeleted() {
+			c.JSON(http.StatusConflict, gin.H{"error": "Cannot reply to a deleted comment"})
+			return
+		}
+		if parent.IsHidden() {
+			c.JSON(http.StatusConflict, gin.H{"error": "Cannot reply to a hidden comment"})
+			return
+		}
+		if parent.Depth+1 > maxCommentDepth {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Comments cannot be nested more than %d levels", maxCommentDepth)})
+			return
+		}
+		rootID := parent.ThreadID()
+		comment.ParentID, comment.RootID, comment.Depth = &parent.ID, &rootID, parent.Depth+1
+	}
+
+	mentioned, err := services.ResolveMentions(database.DB, comment.Content)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&comment).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("UPDATE posts SET comment_count = comment_count + 1 WHERE id = ?", post.ID).Error; err != nil {
+			return err
+		}
+		return saveMentions(tx, commentMention(&comment), "", mentioned)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	flagBannedWords(models.ReportTargetComment, comment.ID, comment.Content)
+	if len(mentioned) > 0 && post.IsPublished() {
+		notifyMentions(post.ID)
+	}
+	services.Notify(services.NotificationEvent{
+		Type:        models.NotificationComment,
+		RecipientID: post.AuthorID,
+		ActorID:     user.ID,
+		TargetType:  models.NotificationTargetPost,
+		TargetID:    post.ID,
+		PostID:      &post.ID,
+	})
+	c.JSON(http.StatusCreated, comment.View())
+}
+
+// PATCH /posts/:id/comments/:comment_id — chỉ tác giả, trong thời hạn cho phép sửa
+func UpdateComment(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+	comment, ok := loadComment(c)
+	if !ok {
+		return
+	}
+	if comment.IsDeleted() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Comment has been deleted"})
+		return
+	}
+	if comment.AuthorID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author can edit this comment"})
+		return
+	}
+	if window := commentEditWindow(); time.Since(comment.CreatedAt) > window {
+		c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("Comments can only be edited within %s of posting", window)})
+		return
+	}
+
+	var body struct {
+		Content string `json:"content" binding:"required,min=1,max=10000"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if body.Content != comment.Content {
+		mentioned, err := services.ResolveMentions(database.DB, body.Content)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		previous := comment.Content
+		err = database.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(comment).Update("content", body.Content).Error; err != nil {
+				return err
+			}
+			return saveMentions(tx, commentMention(comment), previous, mentioned)
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		flagBannedWords(models.ReportTargetComment, comment.ID, body.Content)
+		if len(mentioned) > 0 {
+			notifyMentions(comment.PostID)
+		}
+	}
+	c.JSON(http.StatusOK, comment.View())
+}
+
+// DELETE /posts/:id/comments/:comment_id — tác giả comment, tác giả post hoặc admin.
+// Comment được giữ lại dạng tombstone để các reply vẫn đúng vị trí.
+func DeleteComment(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+	post, ok := loadPost(c)
+	if !ok {
+		return
+	}
+	comment, ok := loadComment(c)
+	if !ok {
+		return
+	}
+	if comment.AuthorID != user.ID && post.AuthorID != user.ID && !user.IsAdmin() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the comment author, the post author or an admin can delete this comment"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Comment{}).
+			Where("id = ? AND deleted_at IS NULL", comment.ID).
+			Updates(map[string]interface{}{"deleted_at": time.Now(), "content": ""})
+		if result.Error != nil || result.RowsAffected == 0 {
+			// Đã bị xóa trước đó: không giảm bộ đếm lần nữa
+			return result.Error
+		}
+		return tx.Exec("UPDATE posts SET comment_count = comment_count - 1 WHERE id = ? AND comment_count > 0", comment.PostID).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// loadComment tìm comment theo :comment_id thuộc post :id, tự ghi response 400/404/500 nếu lỗi
+func loadComment(c *gin.Context) (*models.Comment, bool) {
+	postID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post id"})
+		return nil, false
+	}
+	id, err := strconv.ParseUint(c.Param("comment_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment id"})
+		return nil, false
+	}
+
+	var comment models.Comment
+	if err := database.DB.Where("post_id = ?", postID).First(&comment, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return nil, false
+	}
+	return &comment, true
+}
+
+// commentMention là nội dung comment chứa mention, dùng cho services.SaveMentions
+func commentMention(comment *models.Comment) models.Mention {
+	return models.Mention{AuthorID: comment.AuthorID, TargetType: models.MentionTargetComment, TargetID: comment.ID, PostID: comment.PostID}
+}
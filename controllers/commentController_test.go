@@ -0,0 +1,209 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"myapp/database"
+	"myapp/models"
+)
+
+var commentColumns = []string{"id", "post_id", "parent_id", "root_id", "depth", "author_id", "content", "deleted_at", "created_at", "updated_at"}
+
+func commentParams() gin.Params {
+	return gin.Params{{Key: "id", Value: "1"}, {Key: "comment_id", Value: "5"}}
+}
+
+func expectLoadComment(mock sqlmock.Sqlmock, authorID uint, createdAt time.Time, deletedAt interface{}) {
+	mock.ExpectQuery("SELECT \\* FROM `comments` WHERE post_id = \\? AND `comments`.`id` = \\?").
+		WithArgs(1, 5, 1).
+		WillReturnRows(sqlmock.NewRows(commentColumns).
+			AddRow(5, 1, nil, nil, 0, authorID, "First!", deletedAt, createdAt, createdAt))
+}
+
+func TestCreateComment_ReplyIncrementsCount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadPost(mock, 7)
+
+	// Comment cha ở depth 1 thuộc thread 3
+	mock.ExpectQuery("SELECT \\* FROM `comments` WHERE post_id = \\? AND `comments`.`id` = \\?").
+		WithArgs(1, 5, 1).
+		WillReturnRows(sqlmock.NewRows(commentColumns).
+			AddRow(5, 1, 3, 3, 1, 8, "Parent", nil, time.Now(), time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `comments`").
+		WithArgs(1, 5, 3, 2, 9, "Reply", nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(6, 1))
+	mock.ExpectExec("UPDATE posts SET comment_count = comment_count \\+ 1 WHERE id = \\?").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	c, w := newAuthedContext(&models.User{ID: 9}, "POST", "/posts/1/comments", []byte(`{"content":"Reply","parent_id":5}`))
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	CreateComment(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response models.CommentView
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, uint(2), response.Depth)
+	assert.Equal(t, uint(5), *response.ParentID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateComment_ReplyToDeletedComment(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadPost(mock, 7)
+	mock.ExpectQuery("SELECT \\* FROM `comments`").
+		WillReturnRows(sqlmock.NewRows(commentColumns).
+			AddRow(5, 1, nil, nil, 0, 8, "", time.Now(), time.Now(), time.Now()))
+
+	c, w := newAuthedContext(&models.User{ID: 9}, "POST", "/posts/1/comments", []byte(`{"content":"Reply","parent_id":5}`))
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	CreateComment(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestUpdateComment_WithinWindow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadComment(mock, 9, time.Now().Add(-time.Minute), nil)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `comments` SET `content`=\\?,`updated_at`=\\? WHERE `id` = \\?").
+		WithArgs("Edited", sqlmock.AnyArg(), 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	c, w := newAuthedContext(&models.User{ID: 9}, "PATCH", "/posts/1/comments/5", []byte(`{"content":"Edited"}`))
+	c.Params = commentParams()
+	UpdateComment(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"content":"Edited"`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateComment_AfterWindow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadComment(mock, 9, time.Now().Add(-time.Hour), nil)
+
+	c, w := newAuthedContext(&models.User{ID: 9}, "PATCH", "/posts/1/comments/5", []byte(`{"content":"Edited"}`))
+	c.Params = commentParams()
+	UpdateComment(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestDeleteComment_PostAuthorModerates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadPost(mock, 7)
+	expectLoadComment(mock, 9, time.Now(), nil)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `comments` SET `content`=\\?,`deleted_at`=\\?,`updated_at`=\\? WHERE id = \\? AND deleted_at IS NULL").
+		WithArgs("", sqlmock.AnyArg(), sqlmock.AnyArg(), 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE posts SET comment_count = comment_count - 1 WHERE id = \\? AND comment_count > 0").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "DELETE", "/posts/1/comments/5", nil)
+	c.Params = commentParams()
+	DeleteComment(c)
+
+	assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+	assert.Empty(t, w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteComment_Forbidden(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadPost(mock, 7)
+	expectLoadComment(mock, 9, time.Now(), nil)
+
+	c, w := newAuthedContext(&models.User{ID: 10}, "DELETE", "/posts/1/comments/5", nil)
+	c.Params = commentParams()
+	DeleteComment(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func expectCommentThreads(mock sqlmock.Sqlmock) {
+	expectLoadPost(mock, 7)
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `comments` WHERE post_id = \\? AND parent_id IS NULL").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT \\* FROM `comments` WHERE post_id = \\? AND parent_id IS NULL ORDER BY id LIMIT \\?").
+		WithArgs(1, 20).
+		WillReturnRows(sqlmock.NewRows(commentColumns).
+			AddRow(1, 1, nil, nil, 0, 7, "Root", nil, time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT \\* FROM `comments` WHERE root_id IN \\(\\?\\) ORDER BY id").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows(commentColumns).
+			AddRow(2, 1, 1, 1, 1, 8, "", time.Now(), time.Now(), time.Now()).
+			AddRow(3, 1, 1, 1, 1, 9, "Second reply", nil, time.Now(), time.Now()).
+			AddRow(4, 1, 2, 1, 2, 7, "Reply to deleted", nil, time.Now(), time.Now()))
+}
+
+func TestGetComments_Tree(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectCommentThreads(mock)
+
+	c, w := newListRequest("/posts/1/comments")
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	GetComments(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []models.CommentView
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response, 1)
+	assert.Len(t, response[0].Replies, 2)
+	// Comment đã xóa vẫn giữ vị trí nhưng không lộ nội dung
+	assert.True(t, response[0].Replies[0].Deleted)
+	assert.Nil(t, response[0].Replies[0].Content)
+	assert.Equal(t, uint(4), response[0].Replies[0].Replies[0].ID)
+	assert.Equal(t, "1", w.Header().Get("X-Total-Count"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetComments_Flat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectCommentThreads(mock)
+
+	c, w := newListRequest("/posts/1/comments?view=flat")
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	GetComments(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []models.CommentView
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	ids := []uint{}
+	for _, comment := range response {
+		ids = append(ids, comment.ID)
+	}
+	assert.Equal(t, []uint{1, 2, 4, 3}, ids)
+}
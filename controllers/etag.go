@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bodyETag sinh strong ETag từ nội dung response
+func bodyETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches kiểm tra ETag có nằm trong header (danh sách ETag hoặc "*") hay không.
+// weak = true cho phép so khớp W/"..." (dùng cho If-None-Match).
+func etagMatches(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// requireIfMatch bắt buộc header If-Match khớp với ETag hiện tại.
+// Trả về false (và đã ghi response 428/412) nếu không hợp lệ.
+func requireIfMatch(c *gin.Context, etag string) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header is required"})
+		return false
+	}
+	if !etagMatches(header, etag, false) {
+		c.Header("ETag", etag)
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Resource has been modified, reload and try again"})
+		return false
+	}
+	return true
+}
+
+// notModified trả về 304 nếu If-None-Match khớp với ETag hiện tại
+func notModified(c *gin.Context, etag string) bool {
+	c.Header("ETag", etag)
+	if header := c.GetHeader("If-None-Match"); header != "" && etagMatches(header, etag, true) {
+		c.Status(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// jsonWithETag trả JSON kèm ETag tính từ nội dung, hỗ trợ If-None-Match
+func jsonWithETag(c *gin.Context, status int, value interface{}) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if notModified(c, bodyETag(body)) {
+		return
+	}
+	c.Data(status, "application/json; charset=utf-8", body)
+}
@@ -0,0 +1,29 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestETagMatches(t *testing.T) {
+	etag := `"user-1-v2"`
+
+	assert.True(t, etagMatches(`"user-1-v2"`, etag, false))
+	assert.True(t, etagMatches(`"user-1-v1", "user-1-v2"`, etag, false))
+	assert.True(t, etagMatches(`*`, etag, false))
+	assert.False(t, etagMatches(`"user-1-v1"`, etag, false))
+
+	// If-Match dùng so sánh strong, If-None-Match chấp nhận weak
+	assert.False(t, etagMatches(`W/"user-1-v2"`, etag, false))
+	assert.True(t, etagMatches(`W/"user-1-v2"`, etag, true))
+}
+
+func TestBodyETag(t *testing.T) {
+	a := bodyETag([]byte(`[{"id":1}]`))
+	b := bodyETag([]byte(`[{"id":2}]`))
+
+	assert.NotEqual(t, a, b)
+	assert.Equal(t, a, bodyETag([]byte(`[{"id":1}]`)))
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, a)
+}
@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"myapp/config"
+	"myapp/database"
+	"myapp/jobs"
+	"myapp/middleware"
+	"myapp/models"
+	"myapp/services"
+)
+
+// Loại job dùng cho export user
+const userExportJobType = "user_export"
+
+// Thời hạn của link tải file export
+const exportLinkTTL = time.Hour
+
+// Tên file export hợp lệ: <job_id>.<format>
+var exportFilePattern = regexp.MustCompile(`^[a-f0-9]{32}\.(csv|ndjson|xlsx)$`)
+
+// exportDir là thư mục lưu các file export chạy nền
+func exportDir() string {
+	return config.GetEnv("EXPORT_DIR", "storage/exports")
+}
+
+// GET /users/export?format=csv|ndjson|xlsx&columns=id,email&async=true (+ các bộ lọc của GET /users)
+func ExportUsers(c *gin.Context) {
+	admin, _ := middleware.CurrentUser(c)
+
+	format := c.DefaultQuery("format", services.FormatCSV)
+	contentType, ok := services.ExportContentTypes[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv, ndjson or xlsx"})
+		return
+	}
+	columns, err := services.ParseExportColumns(c.Query("columns"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := filterUsers(database.DB.Model(&models.User{}), c)
+
+	if async, _ := strconv.ParseBool(c.Query("async")); async {
+		if err := os.MkdirAll(exportDir(), 0o755); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		job := jobs.Start(userExportJobType, admin.ID, func(j *jobs.Job) (interface{}, error) {
+			return runExportJob(j, query, format, columns)
+		})
+		c.Header("Location", "/api/users/export/jobs/"+job.ID)
+		c.JSON(http.StatusAccepted, exportJobResponse(job.Snapshot()))
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="users.`+format+`"`)
+	c.Status(http.StatusOK)
+	if _, err := services.ExportUsers(query, c.Writer, format, columns, nil); err != nil {
+		// Header đã được gửi nên chỉ có thể ghi log
+		log.Printf("❌ Export users thất bại: %v", err)
+	}
+}
+
+// GET /users/export/jobs/:job_id
+func GetExportJob(c *gin.Context) {
+	job, ok := jobs.Get(c.Param("job_id"))
+	if !ok || job.Type != userExportJobType {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Export job not found"})
+		return
+	}
+	c.JSON(http.StatusOK, exportJobResponse(job.Snapshot()))
+}
+
+// GET /users/export/files/:file?expires=...&signature=... (link đã ký, không cần token)
+func DownloadExport(c *gin.Context) {
+	name := c.Param("file")
+	if !exportFilePattern.MatchString(name) ||
+		!services.VerifySignedPath(c.Request.URL.Path, c.Query("expires"), c.Query("signature")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invalid or expired download link"})
+		return
+	}
+
+	path := filepath.Join(exportDir(), name)
+	if _, err := os.Stat(path); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Export file not found"})
+		return
+	}
+	c.FileAttachment(path, "users-export-"+name)
+}
+
+// runExportJob ghi file export vào thư mục lưu trữ cục bộ
+func runExportJob(j *jobs.Job, query *gorm.DB, format string, columns []string) (interface{}, error) {
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	name := j.ID + "." + format
+	path := filepath.Join(exportDir(), name)
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := services.ExportUsers(query, file, format, columns, func(written int) {
+		if total > 0 {
+			j.Report(written, float64(written)/float64(total))
+		}
+	})
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	j.Report(count, 1)
+	j.SetArtifact("file", name)
+	j.OnCleanup(func() { os.Remove(path) })
+	return gin.H{"rows": count, "format": format}, nil
+}
+
+// exportJobResponse gắn link tải đã ký (mới tạo mỗi lần poll) khi job hoàn thành
+func exportJobResponse(job jobs.Info) gin.H {
+	response := gin.H{"job": job}
+	if name := job.Artifacts["file"]; job.Status == jobs.StatusCompleted && name != "" {
+		response["download_url"] = services.SignPath("/api/users/export/files/"+name, exportLinkTTL)
+	}
+	return response
+}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"myapp/database"
+	"myapp/middleware"
+	"myapp/models"
+	"myapp/services"
+)
+
+func newExportContext(url string) (*gin.Context, *httptest.ResponseRecorder) {
+	req, _ := http.NewRequest("GET", url, nil)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Set(middleware.CurrentUserKey, &models.User{ID: 1, Role: models.RoleAdmin})
+	return c, w
+}
+
+func TestExportUsers_InvalidFormat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	c, w := newExportContext("/users/export?format=pdf")
+	ExportUsers(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestExportUsers_RejectsSecretColumns(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	c, w := newExportContext("/users/export?columns=id,password")
+	ExportUsers(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "password")
+}
+
+func TestExportUsers_StreamsFilteredCSV(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT `id`,`email` FROM `users` WHERE role = \\? ORDER BY id").
+		WithArgs("admin").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email"}).AddRow(1, "admin@example.com"))
+
+	c, w := newExportContext("/users/export?columns=id,email&role=admin")
+	ExportUsers(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "id,email\n1,admin@example.com\n", w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDownloadExport_SignedLink(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dir := t.TempDir()
+	os.Setenv("EXPORT_DIR", dir)
+	defer os.Unsetenv("EXPORT_DIR")
+
+	name := strings.Repeat("a", 32) + ".csv"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("id\n1\n"), 0o644))
+
+	router := gin.New()
+	router.GET("/api/users/export/files/:file", DownloadExport)
+
+	// Link hợp lệ
+	req, _ := http.NewRequest("GET", services.SignPath("/api/users/export/files/"+name, time.Minute), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "id\n1\n", w.Body.String())
+
+	// Không có chữ ký
+	req, _ = http.NewRequest("GET", "/api/users/export/files/"+name, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,245 @@
+package controllers
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"myapp/config"
+	"myapp/database"
+	"myapp/feed"
+	"myapp/models"
+)
+
+// Phần mở rộng của đường dẫn quyết định định dạng feed
+const (
+	feedRSS  = ".rss"
+	feedAtom = ".atom"
+	feedJSON = ".json"
+)
+
+// feedItemCount là số post mặc định của một feed, cấu hình qua FEED_ITEM_COUNT (1..100);
+// client có thể đổi bằng ?limit=
+func feedItemCount() int {
+	count, err := strconv.Atoi(config.GetEnv("FEED_ITEM_COUNT", "20"))
+	if err != nil || count < 1 || count > maxPerPage {
+		return 20
+	}
+	return count
+}
+
+// feedSource mô tả một feed: tiêu đề, đường dẫn và truy vấn lọc post
+type feedSource struct {
+	title       string
+	description string
+	link        string // đường dẫn API tương ứng với feed
+	query       *gorm.DB
+}
+
+// GET /feeds/posts.rss|.atom|.json — các post đã xuất bản
+func GetPostsFeed(c *gin.Context) {
+	renderFeed(c, feedSource{
+		title:       "Posts",
+		description: "Latest published posts",
+		link:        "/api/posts",
+		query:       database.DB,
+	})
+}
+
+// GET /feeds/users/:id/posts.rss|.atom|.json — post đã xuất bản của một tác giả
+func GetUserPostsFeed(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+		return
+	}
+	var author models.User
+	if err := database.DB.Select("id", "name").First(&author, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	renderFeed(c, feedSource{
+		title:       "Posts by " + author.Name,
+		description: "Latest published posts by " + author.Name,
+		link:        "/api/users/" + strconv.FormatUint(id, 10) + "/posts",
+		query:       database.DB.Where("author_id = ?", author.ID),
+	})
+}
+
+// GET /feeds/tags/:slug/posts.rss|.atom|.json — post đã xuất bản có tag
+func GetTagPostsFeed(c *gin.Context) {
+	var tag models.Tag
+	if err := database.DB.Where("slug = ?", c.Param("slug")).First(&tag).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	renderFeed(c, feedSource{
+		title:       "Posts tagged " + tag.Name,
+		description: "Latest published posts tagged " + tag.Name,
+		link:        "/api/posts?tag=" + tag.Slug,
+		query:       database.DB.Where("posts.id IN (SELECT post_id FROM post_tags WHERE tag_id = ?)", tag.ID),
+	})
+}
+
+// renderFeed lấy các post mới nhất của source và trả về theo định dạng của đường dẫn,
+// hỗ trợ GET có điều kiện qua If-None-Match (ETag) và If-Modified-Since (Last-Modified)
+func renderFeed(c *gin.Context, source feedSource) {
+	limit, ok := bindLimit(c, feedItemCount())
+	if !ok {
+		return
+	}
+
+	var posts []models.Post
+	err := source.query.Where("posts.status = ?", models.PostPublished).
+		Preload("Author", func(db *gorm.DB) *gorm.DB { return db.Select("id", "name") }).
+		Order("posts.published_at DESC, posts.id DESC").Limit(limit).Find(&posts).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	tags, err := feedTags(posts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	base := baseURL(c)
+	out := feed.Feed{
+		Title:       source.title,
+		Description: source.description,
+		Link:        base + source.link,
+		FeedURL:     base + c.Request.URL.RequestURI(),
+	}
+	for i := range posts {
+		item := feedItem(base, &posts[i], tags[posts[i].ID])
+		if item.Updated.After(out.Updated) {
+			out.Updated = item.Updated
+		}
+		out.Items = append(out.Items, item)
+	}
+
+	var body []byte
+	var contentType string
+	switch path.Ext(c.FullPath()) {
+	case feedRSS:
+		body, err = out.RSS()
+		contentType = feed.RSSContentType
+	case feedAtom:
+		body, err = out.Atom()
+		contentType = feed.AtomContentType
+	default:
+		body, err = out.JSON()
+		contentType = feed.JSONContentType
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	writeFeed(c, contentType, body, out.Updated)
+}
+
+func feedItem(base string, post *models.Post, tags []string) feed.Item {
+	// ID giữ theo post id để không đổi khi slug đổi; URL là trang công khai (không cần đăng nhập),
+	// post tạo trước khi có slug (chưa chạy slug-posts) dùng link theo id
+	id := strconv.FormatUint(uint64(post.ID), 10)
+	url := base + "/feeds/posts/" + post.Slug
+	if post.Slug == "" {
+		url = base + "/feeds/items/" + id
+	}
+	item := feed.Item{
+		ID:          base + "/api/posts/" + id,
+		Title:       post.Title,
+		URL:         url,
+		ContentHTML: postHTML(post),
+		Tags:        tags,
+		Published:   post.CreatedAt,
+		Updated:     post.UpdatedAt,
+	}
+	if post.PublishedAt != nil {
+		item.Published = *post.PublishedAt
+	}
+	// Post được xuất bản lại sau lần sửa cuối vẫn phải làm feed đổi
+	if item.Published.After(item.Updated) {
+		item.Updated = item.Published
+	}
+	if post.Author != nil {
+		item.Author = post.Author.Name
+	}
+	return item
+}
+
+// feedTags lấy tên tag của các post, theo post id
+func feedTags(posts []models.Post) (map[uint][]string, error) {
+	tags := map[uint][]string{}
+	if len(posts) == 0 {
+		return tags, nil
+	}
+	ids := make([]uint, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+
+	var rows []struct {
+		PostID uint
+		Name   string
+	}
+	err := database.DB.Table("post_tags").Select("post_tags.post_id, tags.name").
+		Joins("JOIN tags ON tags.id = post_tags.tag_id").
+		Where("post_tags.post_id IN ?", ids).Order("tags.slug").Scan(&rows).Error
+	for _, row := range rows {
+		tags[row.PostID] = append(tags[row.PostID], row.Name)
+	}
+	return tags, err
+}
+
+// writeFeed ghi feed kèm ETag/Last-Modified, trả 304 nếu client đã có bản mới nhất.
+// If-None-Match được ưu tiên hơn If-Modified-Since (RFC 9110).
+func writeFeed(c *gin.Context, contentType string, body []byte, modified time.Time) {
+	if !modified.IsZero() {
+		c.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+	if c.GetHeader("If-None-Match") != "" {
+		if notModified(c, bodyETag(body)) {
+			return
+		}
+	} else {
+		c.Header("ETag", bodyETag(body))
+		if since, err := http.ParseTime(c.GetHeader("If-Modified-Since")); err == nil && !modified.IsZero() &&
+			!modified.Truncate(time.Second).After(since) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// baseURL là gốc của các URL tuyệt đối trong feed: APP_URL nếu có cấu hình,
+// ngược lại suy ra từ request (tôn trọng X-Forwarded-Proto khi chạy sau proxy)
+func baseURL(c *gin.Context) string {
+	if url := config.GetEnv("APP_URL", ""); url != "" {
+		return strings.TrimRight(url, "/")
+	}
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+	return scheme + "://" + c.Request.Host
+}
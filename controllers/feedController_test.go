@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"myapp/database"
+	"myapp/models"
+)
+
+var feedUpdatedAt = time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+func newFeedRouter() *gin.Engine {
+	router := gin.New()
+	for _, ext := range []string{".rss", ".atom", ".json"} {
+		router.GET("/feeds/posts"+ext, GetPostsFeed)
+		router.GET("/feeds/tags/:slug/posts"+ext, GetTagPostsFeed)
+	}
+	return router
+}
+
+func expectFeedPosts(mock sqlmock.Sqlmock) {
+	published := feedUpdatedAt.Add(-time.Hour)
+	mock.ExpectQuery("SELECT \\* FROM `posts` WHERE posts.status = \\? ORDER BY posts.published_at DESC, posts.id DESC LIMIT \\?").
+		WithArgs(models.PostPublished, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "slug", "content", "content_html", "author_id", "status", "published_at", "created_at", "updated_at"}).
+			AddRow(2, "Hello & bye", "hello-bye", "**x**", "<p><strong>x</strong></p>", 7, models.PostPublished, published, published, feedUpdatedAt))
+	mock.ExpectQuery("SELECT `id`,`name` FROM `users` WHERE `users`.`id` = \\?").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(7, "An"))
+	mock.ExpectQuery("SELECT post_tags.post_id, tags.name FROM `post_tags` JOIN tags ON tags.id = post_tags.tag_id WHERE post_tags.post_id IN \\(\\?\\)").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "name"}).AddRow(2, "Go"))
+}
+
+func TestFeedItem_LinksByIDWithoutSlug(t *testing.T) {
+	item := feedItem("http://blog.test", &models.Post{ID: 2, Title: "Old post"}, nil)
+
+	assert.Equal(t, "http://blog.test/feeds/items/2", item.URL)
+	assert.Equal(t, "http://blog.test/api/posts/2", item.ID)
+}
+
+func TestGetPostsFeed_Formats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		path, contentType string
+		contains          []string
+	}{
+		{"/feeds/posts.rss", "application/rss+xml", []string{
+			`<rss version="2.0"`, "<title>Hello &amp; bye</title>", "<link>http://blog.test/feeds/posts/hello-bye</link>",
+			`<guid isPermaLink="false">http://blog.test/api/posts/2</guid>`,
+			"<category>Go</category>", "<dc:creator>An</dc:creator>", "&lt;p&gt;&lt;strong&gt;x&lt;/strong&gt;&lt;/p&gt;",
+		}},
+		{"/feeds/posts.atom", "application/atom+xml", []string{
+			`<feed xmlns="http://www.w3.org/2005/Atom">`, "<updated>2026-03-01T10:00:00Z</updated>",
+			`<link href="http://blog.test/feeds/posts.atom" rel="self"`, `<category term="Go">`, `<content type="html">`,
+		}},
+		{"/feeds/posts.json", "application/feed+json", []string{
+			`"version":"https://jsonfeed.org/version/1.1"`, `"feed_url":"http://blog.test/feeds/posts.json"`,
+			`"content_html":"<p><strong>x</strong></p>"`, `"authors":[{"name":"An"}]`, `"tags":["Go"]`,
+		}},
+	}
+	for _, tt := range tests {
+		mock, gormDB := setupTestDB(t)
+		database.DB = gormDB
+		expectFeedPosts(mock)
+
+		req, _ := http.NewRequest("GET", "http://blog.test"+tt.path, nil)
+		w := httptest.NewRecorder()
+		newFeedRouter().ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, tt.path)
+		assert.Contains(t, w.Header().Get("Content-Type"), tt.contentType)
+		assert.Equal(t, "Sun, 01 Mar 2026 10:00:00 GMT", w.Header().Get("Last-Modified"))
+		assert.NotEmpty(t, w.Header().Get("ETag"))
+		for _, s := range tt.contains {
+			assert.Contains(t, w.Body.String(), s, tt.path)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestGetPostsFeed_ConditionalGet(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectFeedPosts(mock)
+
+	req, _ := http.NewRequest("GET", "/feeds/posts.rss", nil)
+	w := httptest.NewRecorder()
+	newFeedRouter().ServeHTTP(w, req)
+	etag := w.Header().Get("ETag")
+
+	// If-None-Match khớp
+	expectFeedPosts(mock)
+	req, _ = http.NewRequest("GET", "/feeds/posts.rss", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	newFeedRouter().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	// If-Modified-Since không trước Last-Modified
+	expectFeedPosts(mock)
+	req, _ = http.NewRequest("GET", "/feeds/posts.rss", nil)
+	req.Header.Set("If-Modified-Since", "Sun, 01 Mar 2026 10:00:00 GMT")
+	w = httptest.NewRecorder()
+	newFeedRouter().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	// Bản của client cũ hơn
+	expectFeedPosts(mock)
+	req, _ = http.NewRequest("GET", "/feeds/posts.rss", nil)
+	req.Header.Set("If-Modified-Since", "Sun, 01 Mar 2026 09:00:00 GMT")
+	w = httptest.NewRecorder()
+	newFeedRouter().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTagPostsFeed_UnknownTag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT \\* FROM `tags` WHERE slug = \\?").
+		WithArgs("nope", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	req, _ := http.NewRequest("GET", "/feeds/tags/nope/posts.json", nil)
+	w := httptest.NewRecorder()
+	newFeedRouter().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPostsFeed_InvalidLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	req, _ := http.NewRequest("GET", "/feeds/posts.rss?limit=0", nil)
+	w := httptest.NewRecorder()
+	newFeedRouter().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
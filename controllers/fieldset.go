@@ -0,0 +1,278 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// resourceFields là whitelist các field client được chọn qua ?fields=
+// và các quan hệ được nhúng qua ?include= của một resource
+type resourceFields struct {
+	Fields   []string // field (trùng tên cột) client được phép chọn, cũng là mặc định
+	Required []string // cột luôn được select dù client không yêu cầu (khóa để Preload)
+	Computed []string // field không phải cột, controller tính sau khi truy vấn (mặc định có)
+	Includes map[string]includeRelation
+}
+
+// includeRelation mô tả một quan hệ has-many có thể nhúng
+type includeRelation struct {
+	Association  string // tên association trong model, dùng cho Preload
+	Table        string
+	ForeignKey   string
+	Resource     *resourceFields
+	DefaultLimit int
+	MaxLimit     int
+	// Scope (có thể nil) trả về điều kiện SQL giới hạn các bản ghi con user hiện tại được xem
+	Scope func(c *gin.Context, table string) (string, []interface{})
+}
+
+var postFields = &resourceFields{
+	Fields: []string{"id", "title", "slug", "content", "author_id", "status", "published_at", "scheduled_for",
+		"comment_count", "view_count", "created_at", "updated_at"},
+	// author_id và status cần để kiểm tra quyền xem
+	Required: []string{"id", "author_id", "status"},
+	Computed: []string{"reactions", "bookmarked"},
+}
+
+var userFields = &resourceFields{
+	Fields:   []string{"id", "email", "name", "handle", "role", "version", "follower_count", "following_count"},
+	Required: []string{"id"},
+	Includes: map[string]includeRelation{
+		"posts": {
+			Association:  "Posts",
+			Table:        "posts",
+			ForeignKey:   "author_id",
+			Resource:     postFields,
+			DefaultLimit: 10,
+			MaxLimit:     50,
+			Scope:        postVisibility,
+		},
+	},
+}
+
+// fieldSelection là kết quả parse ?fields= / ?include= của một request
+type fieldSelection struct {
+	fields   []string
+	columns  []string // nil = SELECT * (client không truyền fields)
+	includes []includeSelection
+}
+
+type includeSelection struct {
+	name     string
+	relation includeRelation
+	fields   []string
+	columns  []string
+	limit    int
+	scope    func(table string) (string, []interface{})
+}
+
+// parseFieldSelection đọc các query:
+//   - fields=id,name: field của resource chính
+//   - include=posts: nhúng quan hệ
+//   - fields[posts]=id,title, limit[posts]=5: field và số bản ghi tối đa mỗi quan hệ
+func parseFieldSelection(c *gin.Context, resource *resourceFields) (*fieldSelection, error) {
+	fields, columns, err := parseFields(c.Query("fields"), resource, nil)
+	if err != nil {
+		return nil, err
+	}
+	sel := &fieldSelection{fields: fields, columns: columns}
+
+	for _, name := range splitList(c.Query("include")) {
+		relation, ok := resource.Includes[name]
+		if !ok {
+			return nil, fmt.Errorf("cannot include %q, allowed: %s", name, strings.Join(includeNames(resource), ", "))
+		}
+		if containsInclude(sel.includes, name) {
+			continue
+		}
+
+		// Luôn select khóa ngoại để Preload ghép được bản ghi con vào cha.
+		// Field tính toán không được điền cho bản ghi con nên không cho chọn.
+		child := &resourceFields{Fields: relation.Resource.Fields, Required: relation.Resource.Required}
+		fields, columns, err := parseFields(c.Query("fields["+name+"]"), child, []string{relation.ForeignKey})
+		if err != nil {
+			return nil, err
+		}
+
+		limit := relation.DefaultLimit
+		if raw := c.Query("limit[" + name + "]"); raw != "" {
+			limit, err = strconv.Atoi(raw)
+			if err != nil || limit < 1 || limit > relation.MaxLimit {
+				return nil, fmt.Errorf("limit[%s] must be between 1 and %d", name, relation.MaxLimit)
+			}
+		}
+		inc := includeSelection{name: name, relation: relation, fields: fields, columns: columns, limit: limit}
+		if relation.Scope != nil {
+			inc.scope = func(table string) (string, []interface{}) { return relation.Scope(c, table) }
+		}
+		sel.includes = append(sel.includes, inc)
+	}
+	return sel, nil
+}
+
+// parseFields validate danh sách field theo whitelist.
+// columns là nil khi không chọn field và không cần cột phụ, để giữ nguyên SELECT *.
+func parseFields(param string, resource *resourceFields, extra []string) (fields, columns []string, err error) {
+	allowed := append(append([]string{}, resource.Fields...), resource.Computed...)
+	fields = allowed
+	if requested := splitList(param); len(requested) > 0 {
+		fields = nil
+		for _, field := range requested {
+			if !containsString(allowed, field) {
+				return nil, nil, fmt.Errorf("field %q cannot be selected, allowed fields: %s", field, strings.Join(allowed, ", "))
+			}
+			if !containsString(fields, field) {
+				fields = append(fields, field)
+			}
+		}
+	} else if len(extra) == 0 {
+		return fields, nil, nil
+	}
+
+	for _, field := range fields {
+		if !containsString(resource.Computed, field) {
+			columns = append(columns, field)
+		}
+	}
+	for _, col := range append(append([]string{}, resource.Required...), extra...) {
+		if !containsString(columns, col) {
+			columns = append(columns, col)
+		}
+	}
+	return fields, columns, nil
+}
+
+// apply thêm Select và Preload tương ứng vào query
+func (s *fieldSelection) apply(query *gorm.DB) *gorm.DB {
+	if s.columns != nil {
+		query = query.Select(s.columns)
+	}
+	for _, inc := range s.includes {
+		inc := inc
+		query = query.Preload(inc.relation.Association, func(tx *gorm.DB) *gorm.DB {
+			table, fk := inc.relation.Table, inc.relation.ForeignKey
+			tx = tx.Select(inc.columns)
+
+			// Điều kiện quyền xem áp dụng cho cả bản ghi lẫn phép đếm giới hạn bên dưới
+			var newerCond string
+			var args []interface{}
+			if inc.scope != nil {
+				if cond, condArgs := inc.scope(table); cond != "" {
+					tx = tx.Where(cond, condArgs...)
+				}
+				if cond, condArgs := inc.scope("newer"); cond != "" {
+					newerCond, args = " AND "+cond, condArgs
+				}
+			}
+
+			// Giới hạn theo từng bản ghi cha: chỉ lấy `limit` bản ghi mới nhất của mỗi cha
+			return tx.
+				Where(fmt.Sprintf("(SELECT COUNT(*) FROM `%s` AS newer WHERE newer.`%s` = `%s`.`%s` AND newer.id > `%s`.id%s) < ?",
+					table, fk, table, fk, table, newerCond), append(args, inc.limit)...).
+				Order(fmt.Sprintf("`%s`.id DESC", table))
+		})
+	}
+	return query
+}
+
+// wants cho biết field có nằm trong response hay không
+func (s *fieldSelection) wants(field string) bool {
+	return containsString(s.fields, field)
+}
+
+// customized cho biết client có yêu cầu fields/include hay không
+func (s *fieldSelection) customized() bool {
+	return s.columns != nil || len(s.includes) > 0
+}
+
+// render chuyển record (struct hoặc slice) thành JSON chỉ gồm các field đã chọn
+func (s *fieldSelection) render(record interface{}) (interface{}, error) {
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []map[string]interface{}
+	if err := json.Unmarshal(raw, &list); err == nil {
+		for i := range list {
+			list[i] = s.pick(list[i])
+		}
+		return list, nil
+	}
+
+	var item map[string]interface{}
+	if err := json.Unmarshal(raw, &item); err != nil {
+		return nil, err
+	}
+	return s.pick(item), nil
+}
+
+func (s *fieldSelection) pick(item map[string]interface{}) map[string]interface{} {
+	out := pickFields(item, s.fields)
+	for _, inc := range s.includes {
+		children, _ := item[inc.name].([]interface{})
+		picked := make([]map[string]interface{}, 0, len(children))
+		for _, child := range children {
+			if m, ok := child.(map[string]interface{}); ok {
+				picked = append(picked, pickFields(m, inc.fields))
+			}
+		}
+		out[inc.name] = picked
+	}
+	return out
+}
+
+func pickFields(item map[string]interface{}, fields []string) map[string]interface{} {
+	out := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if value, ok := item[field]; ok {
+			out[field] = value
+		}
+	}
+	return out
+}
+
+// bindFieldSelection parse ?fields= / ?include=, ghi response 400 nếu không hợp lệ
+func bindFieldSelection(c *gin.Context, resource *resourceFields) (*fieldSelection, bool) {
+	sel, err := parseFieldSelection(c, resource)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return sel, true
+}
+
+func splitList(param string) []string {
+	var items []string
+	for _, item := range strings.Split(param, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func includeNames(resource *resourceFields) []string {
+	names := make([]string, 0, len(resource.Includes))
+	for name := range resource.Includes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func containsInclude(includes []includeSelection, name string) bool {
+	for _, inc := range includes {
+		if inc.name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"myapp/database"
+)
+
+func newListRequest(target string) (*gin.Context, *httptest.ResponseRecorder) {
+	req, _ := http.NewRequest("GET", target, nil)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	return c, w
+}
+
+func TestGetUsers_HidesPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT \\* FROM `users`").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "password", "token_version"}).
+			AddRow(1, "John Doe", "john@example.com", "$2a$10$secret", 3))
+
+	c, w := newListRequest("/users")
+	GetUsers(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "password")
+	assert.NotContains(t, w.Body.String(), "$2a$10$secret")
+}
+
+func TestGetUsers_SparseFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT `id`,`name` FROM `users`").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "John Doe"))
+
+	c, w := newListRequest("/users?fields=id,name")
+	GetUsers(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []map[string]interface{}{{"id": float64(1), "name": "John Doe"}}, response)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUsers_RejectsHiddenField(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, target := range []string{"/users?fields=id,password", "/users?include=comments", "/users?include=posts&fields[posts]=id,secret", "/users?include=posts&limit[posts]=1000"} {
+		c, w := newListRequest(target)
+		GetUsers(c)
+		assert.Equal(t, http.StatusBadRequest, w.Code, target)
+	}
+}
+
+func TestGetUsers_IncludePosts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT `id`,`name` FROM `users`").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "John Doe").AddRow(2, "Jane Doe"))
+	// Khách chỉ thấy post đã xuất bản, kể cả trong phép đếm giới hạn
+	mock.ExpectQuery("SELECT `title`,`id`,`author_id`,`status` FROM `posts` WHERE `posts`.`author_id` IN \\(\\?,\\?\\) AND `posts`.status = \\? AND \\(\\(SELECT COUNT\\(\\*\\) FROM `posts` AS newer .* AND `newer`.status = \\?\\) < \\?\\) ORDER BY `posts`.id DESC").
+		WithArgs(1, 2, "published", "published", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"title", "id", "author_id", "status"}).
+			AddRow("Hello", 10, 1, "published").
+			AddRow("World", 9, 1, "published"))
+
+	c, w := newListRequest("/users?fields=id,name&include=posts&fields[posts]=title&limit[posts]=2")
+	GetUsers(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response, 2)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"title": "Hello"},
+		map[string]interface{}{"title": "World"},
+	}, response[0]["posts"])
+	assert.Equal(t, []interface{}{}, response[1]["posts"])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUser_SparseFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT `email`,`id` FROM `users` WHERE `users`.`id` = \\?").
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"email", "id"}).AddRow("john@example.com", 1))
+
+	c, w := newUserRequest("GET", "", nil)
+	c.Request.URL.RawQuery = "fields=email"
+	GetUser(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"email":"john@example.com"}`, w.Body.String())
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
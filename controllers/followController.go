@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"myapp/database"
+	"myapp/middleware"
+	"myapp/models"
+	"myapp/services"
+)
+
+// followStatus là trạng thái theo dõi của user hiện tại với một user khác
+type followStatus struct {
+	Following     bool `json:"following"`
+	FollowerCount uint `json:"follower_count"`
+}
+
+// followEntry là một dòng trong danh sách follower/following
+type followEntry struct {
+	ID         uint      `json:"id"`
+	Name       string    `json:"name"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+// PUT /users/:id/follow — idempotent, theo dõi lại không tính thêm
+func FollowUser(c *gin.Context) {
+	setFollow(c, true)
+}
+
+// DELETE /users/:id/follow — idempotent
+func UnfollowUser(c *gin.Context) {
+	setFollow(c, false)
+}
+
+// GET /users/:id/followers — người theo dõi user, mới nhất trước, phân trang như /posts
+func GetFollowers(c *gin.Context) {
+	listFollows(c, "followee_id", "follower_id")
+}
+
+// GET /users/:id/following — những người user đang theo dõi
+func GetFollowing(c *gin.Context) {
+	listFollows(c, "follower_id", "followee_id")
+}
+
+func setFollow(c *gin.Context, follow bool) {
+	user, _ := middleware.CurrentUser(c)
+	target, ok := findUser(c, database.DB.Select("id"))
+	if !ok {
+		return
+	}
+	if target.ID == user.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot follow yourself"})
+		return
+	}
+
+	var err error
+	if follow {
+		var added bool
+		if added, err = services.Follow(database.DB, user.ID, target.ID); added {
+			services.Notify(services.NotificationEvent{
+				Type:        models.NotificationFollow,
+				RecipientID: target.ID,
+				ActorID:     user.ID,
+				TargetType:  models.NotificationTargetUser,
+				TargetID:    target.ID,
+			})
+		}
+	} else {
+		_, err = services.Unfollow(database.DB, user.ID, target.ID)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	status := followStatus{Following: follow}
+	if err := database.DB.Model(&models.User{}).Select("follower_count").Where("id = ?", target.ID).
+		Scan(&status.FollowerCount).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// listFollows liệt kê user ở cột other của các dòng follows có cột self = :id
+func listFollows(c *gin.Context, self, other string) {
+	page, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+	user, ok := findUser(c, database.DB.Select("id"))
+	if !ok {
+		return
+	}
+	query := database.DB.Model(&models.Follow{}).Where("follows."+self+" = ?", user.ID).Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries := []followEntry{}
+	err := page.apply(query).Select("users.id, users.name, follows.created_at AS followed_at").
+		Joins("JOIN users ON users.id = follows." + other).
+		Order("follows.created_at DESC, follows." + other + " DESC").Scan(&entries).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	page.setHeaders(c, total)
+	jsonWithETag(c, http.StatusOK, entries)
+}
@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"myapp/database"
+	"myapp/models"
+)
+
+func expectFindUserID(mock sqlmock.Sqlmock, id uint) {
+	mock.ExpectQuery("SELECT `id` FROM `users` WHERE `users`.`id` = \\?").
+		WithArgs(id, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id))
+}
+
+func TestFollowUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectFindUserID(mock, 9)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `follows`").
+		WithArgs(7, 9, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET following_count = following_count \\+ 1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET follower_count = follower_count \\+ 1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT `follower_count` FROM `users` WHERE id = \\?").
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"follower_count"}).AddRow(3))
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "PUT", "/users/9/follow", nil)
+	c.Params = gin.Params{{Key: "id", Value: "9"}}
+	FollowUser(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"following":true,"follower_count":3}`, w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFollowUser_Self(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectFindUserID(mock, 7)
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "PUT", "/users/7/follow", nil)
+	c.Params = gin.Params{{Key: "id", Value: "7"}}
+	FollowUser(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUnfollowUser_NotFollowing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectFindUserID(mock, 9)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `follows`").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT `follower_count` FROM `users`").
+		WillReturnRows(sqlmock.NewRows([]string{"follower_count"}).AddRow(0))
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "DELETE", "/users/9/follow", nil)
+	c.Params = gin.Params{{Key: "id", Value: "9"}}
+	UnfollowUser(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"following":false,"follower_count":0}`, w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetFollowers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectFindUserID(mock, 9)
+	followedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `follows` WHERE follows.followee_id = \\?").
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(21))
+	mock.ExpectQuery("SELECT users.id, users.name, follows.created_at AS followed_at FROM `follows` "+
+		"JOIN users ON users.id = follows.follower_id WHERE follows.followee_id = \\? "+
+		"ORDER BY follows.created_at DESC, follows.follower_id DESC LIMIT \\? OFFSET \\?").
+		WithArgs(9, 20, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "followed_at"}).AddRow(7, "John", followedAt))
+
+	c, w := newListRequest("/users/9/followers?page=2")
+	c.Params = gin.Params{{Key: "id", Value: "9"}}
+	GetFollowers(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"id":7,"name":"John","followed_at":"2025-01-02T03:04:05Z"}]`, w.Body.String())
+	assert.Equal(t, "21", w.Header().Get("X-Total-Count"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetFollowing_UserNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	mock.ExpectQuery("SELECT `id` FROM `users`").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	c, w := newListRequest("/users/404/following")
+	c.Params = gin.Params{{Key: "id", Value: "404"}}
+	GetFollowing(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
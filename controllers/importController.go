@@ -0,0 +1,212 @@
+package controllers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"myapp/database"
+	"myapp/jobs"
+	"myapp/middleware"
+	"myapp/services"
+)
+
+// Loại job dùng cho import user
+const userImportJobType = "user_import"
+
+// File lớn hơn ngưỡng này (bytes) sẽ được import bằng background job
+const importAsyncThreshold = 1 << 20
+
+// POST /users/import?format=csv|ndjson&dry_run=true&mode=insert|upsert&on_error=abort|skip&async=true&report=csv
+func ImportUsers(c *gin.Context) {
+	admin, _ := middleware.CurrentUser(c)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+
+	opts, err := parseImportOptions(c, fileHeader.Filename)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	async, _ := strconv.ParseBool(c.Query("async"))
+	if async || fileHeader.Size > importAsyncThreshold {
+		// Lưu file ra thư mục tạm vì request kết thúc trước khi job chạy xong
+		tmp, err := os.CreateTemp("", "user-import-*")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		tmp.Close()
+		if err := c.SaveUploadedFile(fileHeader, tmp.Name()); err != nil {
+			os.Remove(tmp.Name())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		job := jobs.Start(userImportJobType, admin.ID, func(j *jobs.Job) (interface{}, error) {
+			return runImportJob(j, tmp.Name(), fileHeader.Size, opts)
+		})
+		c.Header("Location", "/api/users/import/"+job.ID)
+		c.JSON(http.StatusAccepted, job.Snapshot())
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	report, err := services.ImportUsers(database.DB, file, opts, nil)
+	if report == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "report": report.Summary()})
+		return
+	}
+
+	if c.Query("report") == "csv" {
+		c.Header("Content-Disposition", `attachment; filename="import-report.csv"`)
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Status(http.StatusOK)
+		report.WriteCSV(c.Writer)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GET /users/import/:job_id
+func GetImportJob(c *gin.Context) {
+	job, ok := findImportJob(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// GET /users/import/:job_id/report
+func DownloadImportReport(c *gin.Context) {
+	job, ok := findImportJob(c)
+	if !ok {
+		return
+	}
+	path := job.Artifacts["report"]
+	if job.Status != jobs.StatusCompleted || path == "" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Report is not ready", "status": job.Status})
+		return
+	}
+	c.FileAttachment(path, "import-report-"+job.ID+".csv")
+}
+
+func findImportJob(c *gin.Context) (jobs.Info, bool) {
+	job, ok := jobs.Get(c.Param("job_id"))
+	if !ok || job.Type != userImportJobType {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Import job not found"})
+		return jobs.Info{}, false
+	}
+	return job.Snapshot(), true
+}
+
+// runImportJob chạy import từ file tạm và lưu report CSV để tải về sau
+func runImportJob(j *jobs.Job, path string, size int64, opts services.UserImportOptions) (interface{}, error) {
+	defer os.Remove(path)
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	counter := &countingReader{reader: file}
+	report, err := services.ImportUsers(database.DB, counter, opts, func(processed int) {
+		if size > 0 {
+			j.Report(processed, float64(counter.count)/float64(size))
+		}
+	})
+	if report == nil {
+		return nil, err
+	}
+
+	reportFile, createErr := os.CreateTemp("", "user-import-report-*.csv")
+	if createErr == nil {
+		report.WriteCSV(reportFile)
+		reportFile.Close()
+		j.SetArtifact("report", reportFile.Name())
+		j.OnCleanup(func() { os.Remove(reportFile.Name()) })
+	}
+	return report.Summary(), err
+}
+
+// parseImportOptions đọc các tùy chọn import từ query string
+func parseImportOptions(c *gin.Context, filename string) (services.UserImportOptions, error) {
+	opts := services.UserImportOptions{Format: c.Query("format")}
+	if opts.Format == "" {
+		opts.Format = importFormatFromFilename(filename)
+	}
+
+	var err error
+	if v := c.Query("dry_run"); v != "" {
+		if opts.DryRun, err = strconv.ParseBool(v); err != nil {
+			return opts, errInvalidQuery("dry_run")
+		}
+	}
+	switch c.DefaultQuery("mode", "insert") {
+	case "insert":
+	case "upsert":
+		opts.Upsert = true
+	default:
+		return opts, errInvalidQuery("mode")
+	}
+	switch c.DefaultQuery("on_error", "abort") {
+	case "abort":
+	case "skip":
+		opts.SkipOnError = true
+	default:
+		return opts, errInvalidQuery("on_error")
+	}
+	if v := c.Query("batch_size"); v != "" {
+		if opts.BatchSize, err = strconv.Atoi(v); err != nil || opts.BatchSize < 1 || opts.BatchSize > 5000 {
+			return opts, errInvalidQuery("batch_size")
+		}
+	}
+	return opts, nil
+}
+
+func importFormatFromFilename(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".ndjson", ".jsonl":
+		return services.FormatNDJSON
+	default:
+		return services.FormatCSV
+	}
+}
+
+func errInvalidQuery(name string) error {
+	return fmt.Errorf("invalid value for query parameter %s", name)
+}
+
+// countingReader đếm số bytes đã đọc để tính tiến độ
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
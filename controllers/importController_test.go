@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"myapp/database"
+	"myapp/middleware"
+	"myapp/models"
+	"myapp/services"
+)
+
+// newImportRequest tạo request multipart với file upload
+func newImportRequest(t *testing.T, url, filename, content string) (*gin.Context, *httptest.ResponseRecorder) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filename)
+	assert.NoError(t, err)
+	part.Write([]byte(content))
+	writer.Close()
+
+	req, _ := http.NewRequest("POST", url, &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Set(middleware.CurrentUserKey, &models.User{ID: 1, Role: models.RoleAdmin})
+	return c, w
+}
+
+func TestImportUsers_MissingFile(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	req, _ := http.NewRequest("POST", "/users/import", nil)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Set(middleware.CurrentUserKey, &models.User{ID: 1, Role: models.RoleAdmin})
+
+	ImportUsers(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "file is required")
+}
+
+func TestImportUsers_InvalidMode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	c, w := newImportRequest(t, "/users/import?mode=replace", "users.csv", "email,name\n")
+	ImportUsers(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "mode")
+}
+
+func TestImportUsers_DryRun(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT `email` FROM `users` WHERE email IN").
+		WillReturnRows(sqlmock.NewRows([]string{"email"}))
+
+	content := `{"email":"john@example.com","name":"John","password":"password123"}` + "\n"
+	c, w := newImportRequest(t, "/users/import?dry_run=true", "users.ndjson", content)
+	ImportUsers(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var report services.UserImportReport
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 1, report.Created)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImportUsers_CSVReport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT `email` FROM `users` WHERE email IN").
+		WillReturnRows(sqlmock.NewRows([]string{"email"}))
+
+	content := "email,name\njohn@example.com,John\n"
+	c, w := newImportRequest(t, "/users/import?dry_run=true&report=csv", "users.csv", content)
+	ImportUsers(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+	assert.Contains(t, w.Body.String(), "password is required for new users")
+}
+
+func TestGetImportJob_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	req, _ := http.NewRequest("GET", "/users/import/unknown", nil)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "job_id", Value: "unknown"}}
+
+	GetImportJob(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
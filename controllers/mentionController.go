@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"myapp/database"
+	"myapp/markdown"
+	"myapp/middleware"
+	"myapp/models"
+	"myapp/services"
+)
+
+// GET /users/me/mentions — các lần user hiện tại được nhắc, mới nhất trước, phân trang như /posts.
+// ?type=post|comment lọc theo loại nội dung. Mention trong post không xem được
+// hoặc comment đã xóa/bị ẩn không được trả về.
+func GetMyMentions(c *gin.Context) {
+	page, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+	user, _ := middleware.CurrentUser(c)
+
+	query := database.DB.Model(&models.Mention{}).
+		Joins("JOIN posts ON posts.id = mentions.post_id").
+		Joins("LEFT JOIN comments ON mentions.target_type = ? AND comments.id = mentions.target_id", models.MentionTargetComment).
+		Where("mentions.user_id = ?", user.ID).
+		Where("comments.deleted_at IS NULL AND comments.hidden_at IS NULL")
+	if cond, args := postVisibility(c, "posts"); cond != "" {
+		query = query.Where(cond, args...)
+	}
+	switch targetType := c.Query("type"); targetType {
+	case "":
+	case models.MentionTargetPost, models.MentionTargetComment:
+		query = query.Where("mentions.target_type = ?", targetType)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be post or comment"})
+		return
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var mentions []models.Mention
+	if err := page.apply(query).Select("mentions.*").Preload("Author", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "name", "handle")
+	}).Order("mentions.created_at DESC, mentions.id DESC").Find(&mentions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	views := make([]models.MentionView, 0, len(mentions))
+	for i := range mentions {
+		views = append(views, mentions[i].View())
+	}
+	page.setHeaders(c, total)
+	jsonWithETag(c, http.StatusOK, views)
+}
+
+// saveMentions ghi lại danh sách mention của nội dung vừa sửa/tạo (trong transaction tx).
+// Bỏ qua khi cả nội dung cũ lẫn mới đều không nhắc ai để không tốn truy vấn.
+func saveMentions(tx *gorm.DB, target models.Mention, previous string, users []models.User) error {
+	if len(users) == 0 && len(markdown.Mentions(previous)) == 0 {
+		return nil
+	}
+	return services.SaveMentions(tx, target, users)
+}
+
+// notifyMentions gửi thông báo cho các mention mới trong post postID; lỗi chỉ ghi log,
+// nội dung đã lưu thành công
+func notifyMentions(postID uint) {
+	if _, err := services.NotifyMentions(database.DB, postID, time.Now()); err != nil {
+		log.Printf("❌ Gửi thông báo mention trong post %d thất bại: %v", postID, err)
+	}
+}
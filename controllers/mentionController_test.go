@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"myapp/database"
+	"myapp/models"
+	"myapp/services"
+)
+
+func TestGetMyMentions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `mentions` JOIN posts .* LEFT JOIN comments .* WHERE mentions.user_id = \\? .* AND \\(\\(`posts`.status = \\? OR `posts`.author_id = \\?\\)\\) AND mentions.target_type = \\?").
+		WithArgs(models.MentionTargetComment, 9, models.PostPublished, 9, models.MentionTargetComment).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT mentions.\\* FROM `mentions` .* ORDER BY mentions.created_at DESC, mentions.id DESC LIMIT \\?").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "author_id", "target_type", "target_id", "post_id", "created_at"}).
+			AddRow(3, 9, 7, "comment", 12, 5, now))
+	mock.ExpectQuery("SELECT `id`,`name`,`handle` FROM `users` WHERE `users`.`id` = \\?").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "handle"}).AddRow(7, "Alice", "alice"))
+
+	c, w := newAuthedContext(&models.User{ID: 9}, "GET", "/users/me/mentions?type=comment", nil)
+	GetMyMentions(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-Total-Count"))
+	assert.JSONEq(t, `[{"id":3,"target_type":"comment","target_id":12,"post_id":5,
+		"author":{"id":7,"name":"Alice","handle":"alice"},"created_at":"2024-05-01T10:00:00Z"}]`, w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetMyMentions_InvalidType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	c, w := newAuthedContext(&models.User{ID: 9}, "GET", "/users/me/mentions?type=user", nil)
+	GetMyMentions(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCreateComment_SavesAndNotifiesMentions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadPost(mock, 7)
+
+	mock.ExpectQuery("SELECT `id`,`handle` FROM `users` WHERE handle IN \\(\\?,\\?\\)").
+		WithArgs("alice", "everyone").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "handle"}).AddRow(4, "alice"))
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `comments`").
+		WillReturnResult(sqlmock.NewResult(6, 1))
+	mock.ExpectExec("UPDATE posts SET comment_count = comment_count \\+ 1 WHERE id = \\?").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM `mentions` WHERE \\(target_type = \\? AND target_id = \\?\\) AND user_id NOT IN \\(\\?\\)").
+		WithArgs(models.MentionTargetComment, 6, 4).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO `mentions`").
+		WithArgs(4, 9, models.MentionTargetComment, 6, 1, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT mentions.\\* FROM `mentions`").
+		WithArgs(models.MentionTargetComment, 1, models.PostPublished).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "author_id", "target_type", "target_id", "post_id"}).
+			AddRow(1, 4, 9, models.MentionTargetComment, 6, 1))
+	mock.ExpectExec("UPDATE `mentions` SET `notified_at`=\\? WHERE id IN \\(\\?\\)").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// @everyone là từ dành riêng nên không thuộc về ai
+	c, w := newAuthedContext(&models.User{ID: 9}, "POST", "/posts/1/comments", []byte(`{"content":"cc @alice @everyone"}`))
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	CreateComment(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCheckHandle_Invalid(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	c, w := newAuthedContext(&models.User{ID: 9}, "POST", "/users", nil)
+	_, ok := checkHandle(c, "9lives", 0)
+
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), services.ErrInvalidHandle.Error())
+}
@@ -0,0 +1,478 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"myapp/database"
+	"myapp/middleware"
+	"myapp/models"
+	"myapp/services"
+)
+
+// ContentFilter là bộ lọc từ cấm, nội dung khớp sẽ tự được gắn cờ; nil = không lọc
+var ContentFilter *services.WordFilter
+
+// reportInput là body của POST /reports
+type reportInput struct {
+	TargetType string `json:"target_type" binding:"required,oneof=post comment user"`
+	TargetID   uint   `json:"target_id" binding:"required"`
+	Reason     string `json:"reason" binding:"required"`
+	Details    string `json:"details" binding:"max=2000"`
+}
+
+// moderationInput là body của các hành động moderation
+type moderationInput struct {
+	Action      string `json:"action" binding:"required"`
+	Note        string `json:"note" binding:"max=2000"`
+	SuspendDays uint   `json:"suspend_days"` // 0 = khóa vô thời hạn
+}
+
+// POST /reports — báo cáo post, comment hoặc user vi phạm. Mỗi user báo cáo một đối tượng một lần.
+func CreateReport(c *gin.Context) {
+	var body reportInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !models.IsReportReason(body.Reason) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported reason, allowed: " + strings.Join(models.ReportReasons, ", ")})
+		return
+	}
+	user, _ := middleware.CurrentUser(c)
+	ownerID, ok := loadReportTarget(c, body.TargetType, body.TargetID)
+	if !ok {
+		return
+	}
+	if ownerID == user.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot report yourself or your own content"})
+		return
+	}
+
+	report := models.Report{
+		ReporterID: &user.ID,
+		TargetType: body.TargetType,
+		TargetID:   body.TargetID,
+		Reason:     body.Reason,
+		Details:    body.Details,
+		Status:     models.ReportOpen,
+	}
+	result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&report)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "You have already reported this " + body.TargetType})
+		return
+	}
+	c.JSON(http.StatusCreated, report)
+}
+
+// GET /moderation/reports — hàng đợi moderation, cũ nhất trước, phân trang như /posts.
+// Bộ lọc:
+//   - status: open, in_review, resolved, dismissed hoặc active (mặc định: open + in_review)
+//   - target_type: post, comment, user
+//   - assignee: me, none hoặc id của moderator
+func GetReports(c *gin.Context) {
+	page, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+	query := database.DB.Model(&models.Report{})
+
+	switch status := c.DefaultQuery("status", "active"); status {
+	case "active":
+		query = query.Where("status IN ?", []string{models.ReportOpen, models.ReportInReview})
+	case models.ReportOpen, models.ReportInReview, models.ReportResolved, models.ReportDismissed:
+		query = query.Where("status = ?", status)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be active, open, in_review, resolved or dismissed"})
+		return
+	}
+	if targetType := c.Query("target_type"); targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+	switch assignee := c.Query("assignee"); assignee {
+	case "":
+	case "me":
+		user, _ := middleware.CurrentUser(c)
+		query = query.Where("assignee_id = ?", user.ID)
+	case "none":
+		query = query.Where("assignee_id IS NULL")
+	default:
+		id, err := strconv.ParseUint(assignee, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "assignee must be me, none or a user id"})
+			return
+		}
+		query = query.Where("assignee_id = ?", id)
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	reports := []models.Report{}
+	if err := page.apply(query).Order("created_at, id").Find(&reports).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	page.setHeaders(c, total)
+	jsonWithETag(c, http.StatusOK, reports)
+}
+
+// GET /moderation/reports/:id
+func GetReport(c *gin.Context) {
+	if report, ok := loadReport(c); ok {
+		jsonWithETag(c, http.StatusOK, report)
+	}
+}
+
+// POST /moderation/reports/:id/assign — giao report cho moderator (mặc định là chính mình)
+// và chuyển sang in_review. Body: {"assignee_id": 5} (không bắt buộc)
+func AssignReport(c *gin.Context) {
+	moderator, _ := middleware.CurrentUser(c)
+	var body struct {
+		AssigneeID uint `json:"assignee_id"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	assigneeID := moderator.ID
+	if body.AssigneeID != 0 && body.AssigneeID != moderator.ID {
+		var assignee models.User
+		err := database.DB.Select("id", "role").First(&assignee, body.AssigneeID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && !assignee.IsAdmin()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Reports can only be assigned to an admin"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		assigneeID = assignee.ID
+	}
+	setReportAssignee(c, moderator.ID, &assigneeID)
+}
+
+// DELETE /moderation/reports/:id/assign — bỏ giao, report trở về open
+func UnassignReport(c *gin.Context) {
+	moderator, _ := middleware.CurrentUser(c)
+	setReportAssignee(c, moderator.ID, nil)
+}
+
+// POST /moderation/reports/:id/resolve — áp dụng hành động cho đối tượng bị báo cáo và đóng
+// mọi report đang mở trên đối tượng đó. Body: {"action": "hide" | "suspend" | "dismiss",
+// "note": "...", "suspend_days": 7}. suspend trên post/comment sẽ khóa tác giả.
+func ResolveReport(c *gin.Context) {
+	report, ok := loadReport(c)
+	if !ok {
+		return
+	}
+	var body moderationInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	switch body.Action {
+	case models.ModerationHide, models.ModerationSuspend, models.ModerationDismiss:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "action must be hide, suspend or dismiss"})
+		return
+	}
+	if report.IsClosed() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Report has already been " + report.Status})
+		return
+	}
+	action, ok := moderationAction(c, body, report.TargetType, report.TargetID)
+	if !ok {
+		return
+	}
+	action.ReportID = &report.ID
+
+	now := time.Now()
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := services.ApplyModeration(tx, action, now); err != nil {
+			return err
+		}
+		_, err := services.ResolveReports(tx, report, body.Action, action.ModeratorID, body.Note, now)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	report.Status = models.ReportResolved
+	if body.Action == models.ModerationDismiss {
+		report.Status = models.ReportDismissed
+	}
+	report.Resolution, report.ResolutionNote = &body.Action, body.Note
+	report.ResolvedBy, report.ResolvedAt = &action.ModeratorID, &now
+	c.JSON(http.StatusOK, report)
+}
+
+// POST /moderation/actions — hành động trực tiếp không cần report, dùng cả để hoàn tác.
+// Body: {"action": "hide" | "unhide" | "suspend" | "unsuspend", "target_type": "post",
+// "target_id": 1, "note": "...", "suspend_days": 7}
+func ModerateContent(c *gin.Context) {
+	var body struct {
+		moderationInput
+		TargetType string `json:"target_type" binding:"required,oneof=post comment user"`
+		TargetID   uint   `json:"target_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	switch body.Action {
+	case models.ModerationHide, models.ModerationUnhide, models.ModerationSuspend, models.ModerationUnsuspend:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "action must be hide, unhide, suspend or unsuspend"})
+		return
+	}
+	action, ok := moderationAction(c, body.moderationInput, body.TargetType, body.TargetID)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return services.ApplyModeration(tx, action, time.Now())
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GET /moderation/log — nhật ký moderation, mới nhất trước; lọc theo ?target_type=&target_id=
+func GetModerationLog(c *gin.Context) {
+	page, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+	query := database.DB.Model(&models.ModerationLogEntry{})
+	if targetType := c.Query("target_type"); targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+	if raw := c.Query("target_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid target_id"})
+			return
+		}
+		query = query.Where("target_id = ?", id)
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	entries := []models.ModerationLogEntry{}
+	if err := page.apply(query).Order("id DESC").Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	page.setHeaders(c, total)
+	jsonWithETag(c, http.StatusOK, entries)
+}
+
+// GET /moderation/banned-words — danh sách từ cấm đang dùng (cấu hình qua BANNED_WORDS / BANNED_WORDS_FILE)
+func GetBannedWords(c *gin.Context) {
+	words := []string{}
+	if ContentFilter != nil {
+		words = ContentFilter.Words()
+	}
+	c.JSON(http.StatusOK, gin.H{"words": words})
+}
+
+// flagBannedWords gắn cờ nội dung vừa ghi nếu chứa từ cấm. Lỗi chỉ được ghi log,
+// không làm hỏng thao tác ghi đã thành công.
+func flagBannedWords(targetType string, targetID uint, texts ...string) {
+	if ContentFilter == nil {
+		return
+	}
+	matched := ContentFilter.Match(texts...)
+	if len(matched) == 0 {
+		return
+	}
+	if _, err := services.FlagContent(database.DB, targetType, targetID, matched); err != nil {
+		log.Printf("❌ Gắn cờ %s %d chứa từ cấm thất bại: %v", targetType, targetID, err)
+	}
+}
+
+// moderationAction dựng hành động từ input; suspend trên post/comment được chuyển sang tác giả.
+// Ghi response 400/404/500 nếu không áp dụng được.
+func moderationAction(c *gin.Context, body moderationInput, targetType string, targetID uint) (services.ModerationAction, bool) {
+	moderator, _ := middleware.CurrentUser(c)
+	action := services.ModerationAction{
+		ModeratorID: moderator.ID,
+		Action:      body.Action,
+		TargetType:  targetType,
+		TargetID:    targetID,
+		Note:        body.Note,
+		SuspendFor:  time.Duration(body.SuspendDays) * 24 * time.Hour,
+	}
+	if body.Action == models.ModerationDismiss {
+		// Bỏ qua report không cần đối tượng còn tồn tại
+		return action, true
+	}
+	if targetType == models.ReportTargetUser && (body.Action == models.ModerationHide || body.Action == models.ModerationUnhide) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only posts and comments can be hidden"})
+		return action, false
+	}
+
+	ownerID, ok := loadModerationTarget(c, targetType, targetID)
+	if !ok {
+		return action, false
+	}
+	if body.Action == models.ModerationSuspend || body.Action == models.ModerationUnsuspend {
+		var owner models.User
+		if err := database.DB.Select("id", "role").First(&owner, ownerID).Error; err != nil {
+			writeModerationTargetError(c, err)
+			return action, false
+		}
+		if body.Action == models.ModerationSuspend && owner.IsAdmin() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Admins cannot be suspended"})
+			return action, false
+		}
+		action.TargetType, action.TargetID = models.ReportTargetUser, owner.ID
+	}
+	return action, true
+}
+
+// loadReportTarget kiểm tra đối tượng bị báo cáo tồn tại và user hiện tại xem được,
+// trả về id chủ sở hữu (tác giả, hoặc chính user). Tự ghi response 404/500 nếu lỗi.
+func loadReportTarget(c *gin.Context, targetType string, targetID uint) (uint, bool) {
+	postID, ownerID := targetID, uint(0)
+	if targetType == models.ReportTargetComment {
+		var comment models.Comment
+		if err := database.DB.Select("id", "post_id", "author_id", "deleted_at").First(&comment, targetID).Error; err != nil {
+			writeModerationTargetError(c, err)
+			return 0, false
+		}
+		if comment.IsDeleted() {
+			writeModerationTargetError(c, gorm.ErrRecordNotFound)
+			return 0, false
+		}
+		postID, ownerID = comment.PostID, comment.AuthorID
+	} else if targetType == models.ReportTargetUser {
+		return loadModerationTarget(c, targetType, targetID)
+	}
+
+	// Chỉ báo cáo được post (hoặc comment của post) mà user đang xem được
+	post, err := firstVisiblePost(c, database.DB.Select("id", "author_id", "status").Where("id = ?", postID))
+	if err != nil {
+		writeModerationTargetError(c, err)
+		return 0, false
+	}
+	if targetType == models.ReportTargetPost {
+		ownerID = post.AuthorID
+	}
+	return ownerID, true
+}
+
+// loadModerationTarget trả về id chủ sở hữu của đối tượng (không kiểm tra quyền xem),
+// tự ghi response 404/500 nếu lỗi
+func loadModerationTarget(c *gin.Context, targetType string, targetID uint) (uint, bool) {
+	var ownerID uint
+	var err error
+	switch targetType {
+	case models.ReportTargetPost:
+		var post models.Post
+		err = database.DB.Select("id", "author_id").First(&post, targetID).Error
+		ownerID = post.AuthorID
+	case models.ReportTargetComment:
+		var comment models.Comment
+		err = database.DB.Select("id", "author_id").First(&comment, targetID).Error
+		ownerID = comment.AuthorID
+	default:
+		var user models.User
+		err = database.DB.Select("id").First(&user, targetID).Error
+		ownerID = user.ID
+	}
+	if err != nil {
+		writeModerationTargetError(c, err)
+		return 0, false
+	}
+	return ownerID, true
+}
+
+func writeModerationTargetError(c *gin.Context, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Target not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+// loadReport tìm report theo :id, tự ghi response 400/404/500 nếu lỗi
+func loadReport(c *gin.Context) (*models.Report, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report id"})
+		return nil, false
+	}
+	var report models.Report
+	if err := database.DB.First(&report, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Report not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return nil, false
+	}
+	return &report, true
+}
+
+// setReportAssignee giao/bỏ giao report đang mở và ghi log
+func setReportAssignee(c *gin.Context, moderatorID uint, assigneeID *uint) {
+	report, ok := loadReport(c)
+	if !ok {
+		return
+	}
+	if report.IsClosed() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Report has already been " + report.Status})
+		return
+	}
+	status, note := models.ReportOpen, "unassigned"
+	if assigneeID != nil {
+		status, note = models.ReportInReview, "assigned to user "+strconv.FormatUint(uint64(*assigneeID), 10)
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(report).Updates(map[string]interface{}{"assignee_id": assigneeID, "status": status}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.ModerationLogEntry{
+			ModeratorID: &moderatorID,
+			Action:      models.ModerationAssign,
+			TargetType:  report.TargetType,
+			TargetID:    report.TargetID,
+			ReportID:    &report.ID,
+			Note:        note,
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	report.AssigneeID, report.Status = assigneeID, status
+	c.JSON(http.StatusOK, report)
+}
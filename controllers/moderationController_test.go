@@ -0,0 +1,280 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"myapp/database"
+	"myapp/models"
+	"myapp/services"
+)
+
+var reportColumns = []string{"id", "reporter_id", "target_type", "target_id", "reason", "status", "assignee_id", "created_at", "updated_at"}
+
+func expectReportTargetPost(mock sqlmock.Sqlmock, authorID uint, status string) {
+	mock.ExpectQuery("SELECT `id`,`author_id`,`status` FROM `posts` WHERE id = \\?").
+		WithArgs(5, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "author_id", "status"}).AddRow(5, authorID, status))
+}
+
+func expectLoadReport(mock sqlmock.Sqlmock, targetType string, status string) {
+	mock.ExpectQuery("SELECT \\* FROM `reports` WHERE `reports`.`id` = \\?").
+		WithArgs(3, 1).
+		WillReturnRows(sqlmock.NewRows(reportColumns).
+			AddRow(3, 9, targetType, 5, "spam", status, nil, time.Now(), time.Now()))
+}
+
+func TestCreateReport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectReportTargetPost(mock, 7, models.PostPublished)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `reports` .* ON DUPLICATE KEY UPDATE").
+		WithArgs(9, "post", 5, "spam", "Link farm", models.ReportOpen, nil, nil, "", nil, nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+	mock.ExpectCommit()
+
+	c, w := newAuthedContext(&models.User{ID: 9}, "POST", "/reports",
+		[]byte(`{"target_type":"post","target_id":5,"reason":"spam","details":"Link farm"}`))
+	CreateReport(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"open"`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateReport_AlreadyReported(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectReportTargetPost(mock, 7, models.PostPublished)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `reports`").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	c, w := newAuthedContext(&models.User{ID: 9}, "POST", "/reports", []byte(`{"target_type":"post","target_id":5,"reason":"spam"}`))
+	CreateReport(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateReport_HiddenDraftNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	// Draft của người khác: không để lộ là post tồn tại
+	expectReportTargetPost(mock, 7, models.PostDraft)
+
+	c, w := newAuthedContext(&models.User{ID: 9}, "POST", "/reports", []byte(`{"target_type":"post","target_id":5,"reason":"spam"}`))
+	CreateReport(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateReport_Validation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	cases := map[string]string{
+		"unknown reason":    `{"target_type":"post","target_id":5,"reason":"boring"}`,
+		"system reason":     `{"target_type":"post","target_id":5,"reason":"banned_words"}`,
+		"unknown target":    `{"target_type":"tag","target_id":5,"reason":"spam"}`,
+		"missing target id": `{"target_type":"post","reason":"spam"}`,
+	}
+	for name, body := range cases {
+		c, w := newAuthedContext(&models.User{ID: 9}, "POST", "/reports", []byte(body))
+		CreateReport(c)
+		assert.Equal(t, http.StatusBadRequest, w.Code, name)
+	}
+
+	// Tự báo cáo chính mình
+	mock.ExpectQuery("SELECT `id` FROM `users`").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+	c, w := newAuthedContext(&models.User{ID: 9}, "POST", "/reports", []byte(`{"target_type":"user","target_id":9,"reason":"spam"}`))
+	CreateReport(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetReports_Queue(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `reports` WHERE status IN \\(\\?,\\?\\) AND assignee_id IS NULL").
+		WithArgs(models.ReportOpen, models.ReportInReview).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT \\* FROM `reports` WHERE status IN \\(\\?,\\?\\) AND assignee_id IS NULL ORDER BY created_at, id LIMIT \\?").
+		WillReturnRows(sqlmock.NewRows(reportColumns).
+			AddRow(3, 9, "post", 5, "spam", models.ReportOpen, nil, time.Now(), time.Now()))
+
+	c, w := newAuthedContext(&models.User{ID: 1, Role: models.RoleAdmin}, "GET", "/moderation/reports?assignee=none", nil)
+	GetReports(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-Total-Count"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	c, w = newAuthedContext(&models.User{ID: 1, Role: models.RoleAdmin}, "GET", "/moderation/reports?status=closed", nil)
+	GetReports(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAssignReport_ToSelf(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadReport(mock, "post", models.ReportOpen)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `reports` SET `assignee_id`=\\?,`status`=\\?,`updated_at`=\\? WHERE `id` = \\?").
+		WithArgs(1, models.ReportInReview, sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO `moderation_log`").
+		WithArgs(1, models.ModerationAssign, "post", 5, 3, "assigned to user 1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	c, w := newAuthedContext(&models.User{ID: 1, Role: models.RoleAdmin}, "POST", "/moderation/reports/3/assign", nil)
+	c.Params = gin.Params{{Key: "id", Value: "3"}}
+	AssignReport(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"in_review"`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResolveReport_HidePost(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadReport(mock, "post", models.ReportInReview)
+
+	mock.ExpectQuery("SELECT `id`,`author_id` FROM `posts` WHERE `posts`.`id` = \\?").
+		WithArgs(5, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "author_id"}).AddRow(5, 7))
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE posts SET status = \\?").
+		WithArgs(models.PostHidden, 5, models.PostHidden).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO `moderation_log`").
+		WithArgs(1, models.ModerationHide, "post", 5, 3, "Spam", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE `reports` SET .* WHERE target_type = \\? AND target_id = \\? AND status IN").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	c, w := newAuthedContext(&models.User{ID: 1, Role: models.RoleAdmin}, "POST", "/moderation/reports/3/resolve",
+		[]byte(`{"action":"hide","note":"Spam"}`))
+	c.Params = gin.Params{{Key: "id", Value: "3"}}
+	ResolveReport(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"resolved","assignee_id":null,"resolution":"hide"`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResolveReport_AlreadyClosed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadReport(mock, "post", models.ReportDismissed)
+
+	c, w := newAuthedContext(&models.User{ID: 1, Role: models.RoleAdmin}, "POST", "/moderation/reports/3/resolve",
+		[]byte(`{"action":"dismiss"}`))
+	c.Params = gin.Params{{Key: "id", Value: "3"}}
+	ResolveReport(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestModerateContent_SuspendCommentAuthor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT `id`,`author_id` FROM `comments` WHERE `comments`.`id` = \\?").
+		WithArgs(8, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "author_id"}).AddRow(8, 7))
+	mock.ExpectQuery("SELECT `id`,`role` FROM `users` WHERE `users`.`id` = \\?").
+		WithArgs(7, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "role"}).AddRow(7, models.RoleUser))
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE users SET suspended_at = \\?, suspended_until = \\? WHERE id = \\?").
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO `moderation_log`").
+		WithArgs(1, models.ModerationSuspend, "user", 7, nil, "", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	c, _ := newAuthedContext(&models.User{ID: 1, Role: models.RoleAdmin}, "POST", "/moderation/actions",
+		[]byte(`{"action":"suspend","target_type":"comment","target_id":8,"suspend_days":7}`))
+	ModerateContent(c)
+
+	assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestModerateContent_CannotSuspendAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT `id` FROM `users`").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectQuery("SELECT `id`,`role` FROM `users`").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "role"}).AddRow(2, models.RoleAdmin))
+
+	c, w := newAuthedContext(&models.User{ID: 1, Role: models.RoleAdmin}, "POST", "/moderation/actions",
+		[]byte(`{"action":"suspend","target_type":"user","target_id":2}`))
+	ModerateContent(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateComment_FlagsBannedWords(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	defer func() { ContentFilter = nil }()
+	ContentFilter = services.NewWordFilter([]string{"scam"})
+	expectLoadPost(mock, 7)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `comments`").
+		WillReturnResult(sqlmock.NewResult(6, 1))
+	mock.ExpectExec("UPDATE posts SET comment_count").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `reports`").
+		WithArgs("comment", 6, models.ReportOpen, models.ReportInReview).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectExec("INSERT INTO `reports`").
+		WillReturnResult(sqlmock.NewResult(12, 1))
+	mock.ExpectExec("INSERT INTO `moderation_log`").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	c, w := newAuthedContext(&models.User{ID: 9}, "POST", "/posts/1/comments", []byte(`{"content":"Total SCAM!"}`))
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	CreateComment(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"myapp/database"
+	"myapp/middleware"
+	"myapp/models"
+	"myapp/services"
+)
+
+// unreadCount là số thông báo chưa đọc của user hiện tại
+type unreadCount struct {
+	Unread int64 `json:"unread"`
+}
+
+// GET /notifications — thông báo của user hiện tại, mới cập nhật trước, phân trang như /posts.
+// ?unread=true chỉ lấy thông báo chưa đọc; số chưa đọc trả về ở header X-Unread-Count.
+func GetNotifications(c *gin.Context) {
+	page, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+	unreadOnly := false
+	if raw := c.Query("unread"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unread must be true or false"})
+			return
+		}
+		unreadOnly = v
+	}
+
+	user, _ := middleware.CurrentUser(c)
+	query := database.DB.Model(&models.Notification{}).Where("user_id = ?", user.ID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	unread := total
+	if !unreadOnly {
+		var err error
+		if unread, err = services.UnreadNotificationCount(database.DB, user.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var notifications []models.Notification
+	if err := page.apply(query).Preload("Actor", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "name")
+	}).Order("updated_at DESC, id DESC").Find(&notifications).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	views := make([]models.NotificationView, 0, len(notifications))
+	for i := range notifications {
+		views = append(views, notifications[i].View())
+	}
+	page.setHeaders(c, total)
+	c.Header("X-Unread-Count", strconv.FormatInt(unread, 10))
+	jsonWithETag(c, http.StatusOK, views)
+}
+
+// GET /notifications/unread-count
+func GetUnreadNotificationCount(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+	count, err := services.UnreadNotificationCount(database.DB, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, unreadCount{Unread: count})
+}
+
+// POST /notifications/:id/read — idempotent, đọc lại thông báo đã đọc không lỗi
+func MarkNotificationRead(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification id"})
+		return
+	}
+	user, _ := middleware.CurrentUser(c)
+
+	var found int64
+	if err := database.DB.Model(&models.Notification{}).Where("id = ? AND user_id = ?", id, user.ID).
+		Count(&found).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if found == 0 {
+		// Thông báo của user khác cũng trả về 404
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		return
+	}
+	if _, err := services.MarkNotificationsRead(database.DB, user.ID, uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// POST /notifications/read-all — trả về số thông báo vừa được đánh dấu đã đọc
+func MarkAllNotificationsRead(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+	marked, err := services.MarkNotificationsRead(database.DB, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"marked": marked})
+}
+
+// GET /notifications/preferences — {"comment": true, "follow": false, ...}
+func GetNotificationPreferences(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+	prefs, err := services.NotificationPreferences(database.DB, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, prefs)
+}
+
+// PUT /notifications/preferences — chỉ đổi các loại có trong body, trả về toàn bộ tùy chọn
+func UpdateNotificationPreferences(c *gin.Context) {
+	var body map[string]bool
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for t := range body {
+		if !models.IsNotificationType(t) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported notification type " + strconv.Quote(t) +
+				", allowed: " + strings.Join(models.NotificationTypes, ", ")})
+			return
+		}
+	}
+
+	user, _ := middleware.CurrentUser(c)
+	if err := services.SetNotificationPreferences(database.DB, user.ID, body); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	GetNotificationPreferences(c)
+}
@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"myapp/database"
+	"myapp/models"
+)
+
+func TestGetNotifications(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `notifications` WHERE user_id = \\?").
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `notifications` WHERE user_id = \\? AND read_at IS NULL").
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT \\* FROM `notifications` WHERE user_id = \\? ORDER BY updated_at DESC, id DESC LIMIT \\?").
+		WithArgs(9, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "type", "actor_id", "actor_count", "target_type", "target_id", "post_id", "read_at", "created_at", "updated_at"}).
+			AddRow(41, 9, "reaction", 7, 5, "post", 5, 5, nil, now, now).
+			AddRow(40, 9, "follow", 8, 1, "user", 9, nil, now, now, now))
+	mock.ExpectQuery("SELECT `id`,`name` FROM `users` WHERE `users`.`id` IN \\(\\?,\\?\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(7, "Alice").AddRow(8, "Bob"))
+
+	c, w := newAuthedContext(&models.User{ID: 9}, "GET", "/notifications", nil)
+	GetNotifications(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("X-Total-Count"))
+	assert.Equal(t, "1", w.Header().Get("X-Unread-Count"))
+	assert.Contains(t, w.Body.String(), `"message":"Alice and 4 others reacted to your post"`)
+	assert.Contains(t, w.Body.String(), `"message":"Bob started following you","actor":{"id":8,"name":"Bob"}`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetNotifications_UnreadOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	// Chỉ lấy chưa đọc thì tổng cũng là số chưa đọc, không cần đếm lần hai
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `notifications` WHERE user_id = \\? AND read_at IS NULL").
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery("SELECT \\* FROM `notifications` WHERE user_id = \\? AND read_at IS NULL").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	c, w := newAuthedContext(&models.User{ID: 9}, "GET", "/notifications?unread=true", nil)
+	GetNotifications(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-Unread-Count"))
+	assert.JSONEq(t, `[]`, w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMarkNotificationRead_OtherUsersNotification(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `notifications` WHERE id = \\? AND user_id = \\?").
+		WithArgs(40, 9).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	c, w := newAuthedContext(&models.User{ID: 9}, "POST", "/notifications/40/read", nil)
+	c.Params = gin.Params{{Key: "id", Value: "40"}}
+	MarkNotificationRead(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMarkAllNotificationsRead(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `notifications` SET `group_key`=\\?,`read_at`=\\? WHERE user_id = \\? AND read_at IS NULL").
+		WithArgs(nil, sqlmock.AnyArg(), 9).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	c, w := newAuthedContext(&models.User{ID: 9}, "POST", "/notifications/read-all", nil)
+	MarkAllNotificationsRead(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"marked":3}`, w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateNotificationPreferences(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `notification_preferences` .* ON DUPLICATE KEY UPDATE `enabled`=VALUES\\(`enabled`\\)").
+		WithArgs(9, "follow", false).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT \\* FROM `notification_preferences` WHERE user_id = \\?").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "type", "enabled"}).AddRow(9, "follow", false))
+
+	c, w := newAuthedContext(&models.User{ID: 9}, "PUT", "/notifications/preferences", []byte(`{"follow":false}`))
+	UpdateNotificationPreferences(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"comment":true,"follow":false,"mention":true,"reaction":true}`, w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateNotificationPreferences_UnknownType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	c, w := newAuthedContext(&models.User{ID: 9}, "PUT", "/notifications/preferences", []byte(`{"digest":true}`))
+	UpdateNotificationPreferences(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "allowed: comment, follow, mention, reaction")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
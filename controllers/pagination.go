@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Giới hạn số bản ghi mỗi trang
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+)
+
+// pagination là tham số ?page= / ?per_page= của một request danh sách
+type pagination struct {
+	Page    int
+	PerPage int
+}
+
+// bindPagination đọc ?page= (từ 1) và ?per_page=, ghi response 400 nếu không hợp lệ
+func bindPagination(c *gin.Context) (pagination, bool) {
+	page := pagination{Page: 1, PerPage: defaultPerPage}
+	if raw := c.Query("page"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "page must be a positive integer"})
+			return page, false
+		}
+		page.Page = n
+	}
+	if raw := c.Query("per_page"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxPerPage {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("per_page must be between 1 and %d", maxPerPage)})
+			return page, false
+		}
+		page.PerPage = n
+	}
+	return page, true
+}
+
+// apply thêm Offset/Limit vào query
+func (p pagination) apply(query *gorm.DB) *gorm.DB {
+	return query.Offset((p.Page - 1) * p.PerPage).Limit(p.PerPage)
+}
+
+// setHeaders ghi X-Total-Count và Link (RFC 8288) để client điều hướng giữa các trang,
+// body vẫn là mảng như các endpoint danh sách khác
+func (p pagination) setHeaders(c *gin.Context, total int64) {
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+
+	lastPage := int((total + int64(p.PerPage) - 1) / int64(p.PerPage))
+	if lastPage < 1 {
+		lastPage = 1
+	}
+
+	var links []string
+	addLink := func(page int, rel string) {
+		u := *c.Request.URL
+		q := u.Query()
+		q.Set("page", strconv.Itoa(page))
+		q.Set("per_page", strconv.Itoa(p.PerPage))
+		u.RawQuery = q.Encode()
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), rel))
+	}
+	addLink(1, "first")
+	if p.Page > 1 {
+		addLink(p.Page-1, "prev")
+	}
+	if p.Page < lastPage {
+		addLink(p.Page+1, "next")
+	}
+	addLink(lastPage, "last")
+	c.Header("Link", strings.Join(links, ", "))
+}
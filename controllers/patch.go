@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	"myapp/jsonpatch"
+)
+
+// Content-Type của các định dạng patch được hỗ trợ
+const (
+	mergePatchContentType = "application/merge-patch+json"
+	jsonPatchContentType  = "application/json-patch+json"
+)
+
+// isPatchDocument cho biết body là merge patch / JSON patch thay vì JSON thường
+func isPatchDocument(c *gin.Context) bool {
+	switch c.ContentType() {
+	case mergePatchContentType, jsonPatchContentType:
+		return true
+	}
+	return false
+}
+
+// applyPatchDocument áp dụng patch trong body lên current, từ chối patch chạm vào
+// các field read-only, rồi decode kết quả vào out và validate theo binding tags.
+// Trả về false nếu đã ghi response lỗi.
+func applyPatchDocument(c *gin.Context, current interface{}, readOnly []string, out interface{}) bool {
+	patch, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	doc, err := json.Marshal(current)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+
+	var fields []string
+	var patched []byte
+	if c.ContentType() == mergePatchContentType {
+		if fields, err = jsonpatch.MergePatchFields(patch); err == nil {
+			patched, err = jsonpatch.MergePatch(doc, patch)
+		}
+	} else {
+		if fields, err = jsonpatch.JSONPatchFields(patch); err == nil {
+			patched, err = jsonpatch.Apply(doc, patch)
+		}
+	}
+	for _, field := range fields {
+		if field == "" || containsString(readOnly, field) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "field " + field + " is read-only"})
+			return false
+		}
+	}
+	if errors.Is(err, jsonpatch.ErrTestFailed) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	// Tài liệu sau khi patch phải hợp lệ như khi tạo mới
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(out); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	if err := binding.Validator.ValidateStruct(out); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
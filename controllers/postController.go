@@ -0,0 +1,662 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"myapp/database"
+	"myapp/markdown"
+	"myapp/middleware"
+	"myapp/models"
+	"myapp/services"
+)
+
+// postInput là dữ liệu post client được gửi lên (author lấy từ token, không lấy từ body)
+type postInput struct {
+	Title   string `json:"title" binding:"required,min=1,max=255"`
+	Content string `json:"content" binding:"max=65535"`
+}
+
+// postDocument là tài liệu post mà merge patch / JSON patch thao tác lên
+type postDocument struct {
+	ID        uint      `json:"id"`
+	AuthorID  uint      `json:"author_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	postInput
+}
+
+// newPostDocument trả về tài liệu patch của post: các field sửa được cùng id read-only
+func newPostDocument(post *models.Post) postDocument {
+	return postDocument{
+		ID:        post.ID,
+		AuthorID:  post.AuthorID,
+		CreatedAt: post.CreatedAt,
+		UpdatedAt: post.UpdatedAt,
+		postInput: postInput{Title: post.Title, Content: post.Content},
+	}
+}
+
+// Các field của post không được phép patch (slug tự đổi theo title, trạng thái đổi qua
+// PUT /posts/:id/status, bộ đếm do server quản lý)
+var postReadOnlyFields = []string{
+	"id", "slug", "author_id", "created_at", "updated_at",
+	"status", "published_at", "scheduled_for", "comment_count", "view_count",
+}
+
+// POST /posts — tác giả là user đang đăng nhập, post mới luôn ở trạng thái draft
+func CreatePost(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+
+	var body postInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	html, mentioned, err := services.RenderContent(database.DB, body.Content)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	post := models.Post{Title: body.Title, Content: body.Content, ContentHTML: html, AuthorID: user.ID, Status: models.PostDraft}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := services.SavePostSlug(tx, post.Title, 0, func(slug string) error {
+			post.Slug = slug
+			return tx.Create(&post).Error
+		}); err != nil {
+			return err
+		}
+		if err := recordPostRevision(tx, nil, &post, user.ID, nil); err != nil {
+			return err
+		}
+		// Post mới là draft nên chưa thông báo, chờ tới lúc xuất bản
+		return saveMentions(tx, postMention(&post), "", mentioned)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	flagBannedWords(models.ReportTargetPost, post.ID, post.Title, post.Content)
+	c.JSON(http.StatusCreated, post)
+}
+
+// PUT /posts/:id/status — chuyển trạng thái theo state machine của post:
+//   - draft -> scheduled | published | archived
+//   - scheduled -> scheduled (đổi lịch) | draft | published
+//   - published -> archived
+//   - archived -> draft | published
+func SetPostStatus(c *gin.Context) {
+	post, ok := loadPost(c)
+	if !ok || !canModifyPost(c, post) {
+		return
+	}
+
+	var body struct {
+		Status       string     `json:"status" binding:"required,oneof=draft scheduled published archived"`
+		ScheduledFor *time.Time `json:"scheduled_for"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !post.CanTransition(body.Status) {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Cannot change post status from %s to %s", post.Status, body.Status)})
+		return
+	}
+
+	updates := map[string]interface{}{"status": body.Status, "scheduled_for": nil}
+	switch body.Status {
+	case models.PostScheduled:
+		if body.ScheduledFor == nil || !body.ScheduledFor.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "scheduled_for must be a time in the future"})
+			return
+		}
+		updates["scheduled_for"] = *body.ScheduledFor
+	case models.PostPublished:
+		updates["published_at"] = time.Now()
+	}
+
+	// Chỉ cập nhật nếu trạng thái chưa bị đổi (vd: scheduler vừa xuất bản)
+	result := database.DB.Model(post).Where("status = ?", post.Status).Updates(updates)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Post status has changed, reload and try again"})
+		return
+	}
+	if body.Status == models.PostPublished {
+		// Mention trong draft được thông báo khi xuất bản
+		notifyMentions(post.ID)
+	}
+	c.JSON(http.StatusOK, post)
+}
+
+// GET /posts — phân trang ?page=&per_page=, lọc ?q=, ?author_id=, chọn field ?fields=
+func GetPosts(c *gin.Context) {
+	listPosts(c, database.DB)
+}
+
+// GET /users/:id/posts
+func GetUserPosts(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+		return
+	}
+
+	var count int64
+	if err := database.DB.Model(&models.User{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if count == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	listPosts(c, database.DB.Where("author_id = ?", id))
+}
+
+// GET /posts/:id — hỗ trợ ?fields=
+func GetPost(c *gin.Context) {
+	sel, ok := bindFieldSelection(c, postFields)
+	if !ok {
+		return
+	}
+	format, ok := bindContentFormat(c, sel)
+	if !ok {
+		return
+	}
+	post, ok := findPost(c, sel.apply(database.DB))
+	if !ok {
+		return
+	}
+	recordView(c, post)
+	items, err := renderPosts(c, sel, format, []models.Post{*post})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	jsonWithETag(c, http.StatusOK, items[0])
+}
+
+// GET /posts/by-slug/:slug — hỗ trợ ?fields= và ?format= như GET /posts/:id.
+// Slug cũ (trước khi đổi title) được chuyển hướng 301 sang slug hiện tại.
+func GetPostBySlug(c *gin.Context) {
+	sel, ok := bindFieldSelection(c, postFields)
+	if !ok {
+		return
+	}
+	format, ok := bindContentFormat(c, sel)
+	if !ok {
+		return
+	}
+	slug := c.Param("slug")
+	post, err := firstVisiblePost(c, sel.apply(database.DB).Where("slug = ?", slug))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		redirectOldPostSlug(c, slug)
+		return
+	}
+	if err != nil {
+		writePostError(c, err)
+		return
+	}
+	recordView(c, post)
+
+	items, err := renderPosts(c, sel, format, []models.Post{*post})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	jsonWithETag(c, http.StatusOK, items[0])
+}
+
+// redirectOldPostSlug chuyển hướng 301 nếu slug là slug cũ của một post user được xem, ngược lại 404
+func redirectOldPostSlug(c *gin.Context, slug string) {
+	postID, err := services.ResolvePostSlug(database.DB, slug)
+	if err != nil {
+		writePostError(c, err)
+		return
+	}
+	if postID == 0 {
+		writePostError(c, gorm.ErrRecordNotFound)
+		return
+	}
+	post, err := firstVisiblePost(c, database.DB.Select("id", "slug", "author_id", "status").Where("id = ?", postID))
+	if err != nil {
+		writePostError(c, err)
+		return
+	}
+
+	target := url.URL{Path: path.Join(path.Dir(c.Request.URL.Path), post.Slug), RawQuery: c.Request.URL.RawQuery}
+	c.Redirect(http.StatusMovedPermanently, target.String())
+}
+
+// PUT /posts/:id — thay thế title và content
+func UpdatePost(c *gin.Context) {
+	post, ok := loadPost(c)
+	if !ok || !canModifyPost(c, post) {
+		return
+	}
+
+	var body postInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	savePost(c, post, changedPostColumns(post, body))
+}
+
+// PATCH /posts/:id — hỗ trợ JSON thường, merge patch và JSON patch như PATCH /users/:id
+func PatchPost(c *gin.Context) {
+	post, ok := loadPost(c)
+	if !ok || !canModifyPost(c, post) {
+		return
+	}
+
+	if isPatchDocument(c) {
+		var doc postDocument
+		if !applyPatchDocument(c, newPostDocument(post), postReadOnlyFields, &doc) {
+			return
+		}
+		savePost(c, post, changedPostColumns(post, doc.postInput))
+		return
+	}
+
+	var body struct {
+		Title   *string `json:"title" binding:"omitempty,min=1,max=255"`
+		Content *string `json:"content" binding:"omitempty,max=65535"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if body.Title != nil {
+		updates["title"] = *body.Title
+	}
+	if body.Content != nil {
+		updates["content"] = *body.Content
+	}
+	savePost(c, post, updates)
+}
+
+// DELETE /posts/:id
+func DeletePost(c *gin.Context) {
+	post, ok := loadPost(c)
+	if !ok || !canModifyPost(c, post) {
+		return
+	}
+
+	var attachments []models.Attachment
+	var uploadParts []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Dòng attachment/upload bị xóa theo khóa ngoại, file trong storage xóa sau khi commit
+		if Files != nil {
+			if err := tx.Where("post_id = ?", post.ID).Find(&attachments).Error; err != nil {
+				return err
+			}
+			var err error
+			if uploadParts, err = services.UploadPartKeys(tx, "uploads.post_id = ?", post.ID); err != nil {
+				return err
+			}
+		}
+
+		// Reaction không có khóa ngoại tới post/comment nên phải xóa tay
+		commentIDs := tx.Model(&models.Comment{}).Select("id").Where("post_id = ?", post.ID)
+		if err := services.DeleteReactions(tx, models.ReactionTargetComment, commentIDs); err != nil {
+			return err
+		}
+		if err := services.DeleteReactions(tx, models.ReactionTargetPost, []uint{post.ID}); err != nil {
+			return err
+		}
+
+		// post_tags bị xóa theo khóa ngoại, cần giảm usage_count trước
+		if err := tx.Exec("UPDATE tags SET usage_count = usage_count - 1 WHERE usage_count > 0 AND id IN (SELECT tag_id FROM post_tags WHERE post_id = ?)", post.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(post).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range attachments {
+		services.DeleteFiles(c.Request.Context(), Files, attachments[i].Keys()...)
+	}
+	services.DeleteFiles(c.Request.Context(), Files, uploadParts...)
+	c.Status(http.StatusNoContent)
+}
+
+// listPosts trả về danh sách post đã lọc, phân trang, mới nhất trước
+func listPosts(c *gin.Context, query *gorm.DB) {
+	sel, ok := bindFieldSelection(c, postFields)
+	if !ok {
+		return
+	}
+	page, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+	format, ok := bindContentFormat(c, sel)
+	if !ok {
+		return
+	}
+
+	query, err := filterPosts(query, c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if cond, args := postVisibility(c, "posts"); cond != "" {
+		query = query.Where(cond, args...)
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Model(&models.Post{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var posts []models.Post
+	if err := sel.apply(page.apply(query)).Order("id DESC").Find(&posts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	items, err := renderPosts(c, sel, format, posts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	page.setHeaders(c, total)
+	jsonWithETag(c, http.StatusOK, items)
+}
+
+// Định dạng content khi đọc post qua ?format=
+const (
+	formatMarkdown = "markdown" // mã nguồn Markdown như khi ghi (mặc định)
+	formatHTML     = "html"     // HTML đã render và lọc
+)
+
+// bindContentFormat đọc ?format=; với html cần select thêm content_html khi client chọn fields
+func bindContentFormat(c *gin.Context, sel *fieldSelection) (string, bool) {
+	format := c.DefaultQuery("format", formatMarkdown)
+	switch format {
+	case formatMarkdown:
+	case formatHTML:
+		if sel.columns != nil && sel.wants("content") {
+			sel.columns = append(sel.columns, "content_html")
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be markdown or html"})
+		return "", false
+	}
+	return format, true
+}
+
+// renderPosts render posts theo fieldset, đổi content sang HTML nếu cần, gắn reactions và cờ bookmarked
+func renderPosts(c *gin.Context, sel *fieldSelection, format string, posts []models.Post) ([]map[string]interface{}, error) {
+	body, err := sel.render(posts)
+	if err != nil {
+		return nil, err
+	}
+	items, _ := body.([]map[string]interface{})
+
+	if format == formatHTML {
+		for i, item := range items {
+			if _, ok := item["content"]; ok {
+				item["content"] = postHTML(&posts[i])
+			}
+		}
+	}
+	ids := make([]uint, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+	if sel.wants("reactions") {
+		if err := attachReactions(c, models.ReactionTargetPost, ids, items); err != nil {
+			return nil, err
+		}
+	}
+	if sel.wants("bookmarked") {
+		if err := attachBookmarks(c, ids, items); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+// postHTML trả về HTML đã lưu; post tạo trước khi có content_html được render tại chỗ
+func postHTML(post *models.Post) string {
+	if post.ContentHTML == "" && post.Content != "" {
+		return markdown.Render(post.Content)
+	}
+	return post.ContentHTML
+}
+
+// filterPosts áp dụng các bộ lọc của danh sách posts
+//   - q: tìm theo title
+//   - author_id: lọc theo tác giả
+//   - status: lọc theo trạng thái (chỉ thấy post chưa xuất bản của chính mình, trừ admin)
+//   - tag (lặp lại được): tag_mode=all (mặc định) cần đủ mọi tag, tag_mode=any cần ít nhất một
+//   - category (lặp lại được): post thuộc danh mục hoặc danh mục con của nó
+func filterPosts(query *gorm.DB, c *gin.Context) (*gorm.DB, error) {
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		query = query.Where("title LIKE ?", "%"+escapeLike(q)+"%")
+	}
+	if authorID := c.Query("author_id"); authorID != "" {
+		query = query.Where("author_id = ?", authorID)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("posts.status = ?", status)
+	}
+
+	var tags []string
+	for _, tag := range c.QueryArray("tag") {
+		if slug := services.Slugify(tag); slug != "" && !containsString(tags, slug) {
+			tags = append(tags, slug)
+		}
+	}
+	if len(tags) > 0 {
+		tagged := database.DB.Table("post_tags").Select("post_tags.post_id").
+			Joins("JOIN tags ON tags.id = post_tags.tag_id").
+			Where("tags.slug IN ?", tags)
+		switch c.DefaultQuery("tag_mode", "all") {
+		case "all":
+			tagged = tagged.Group("post_tags.post_id").Having("COUNT(DISTINCT post_tags.tag_id) = ?", len(tags))
+		case "any":
+		default:
+			return nil, errors.New("tag_mode must be all or any")
+		}
+		query = query.Where("posts.id IN (?)", tagged)
+	}
+
+	if categories := c.QueryArray("category"); len(categories) > 0 {
+		query = query.Where(`posts.id IN (SELECT post_id FROM post_categories WHERE category_id IN (
+			WITH RECURSIVE tree AS (
+				SELECT id FROM categories WHERE slug IN ?
+				UNION ALL
+				SELECT categories.id FROM categories JOIN tree ON categories.parent_id = tree.id
+			) SELECT id FROM tree))`, categories)
+	}
+	return query, nil
+}
+
+// loadPost tìm post theo :id, tự ghi response 400/404/500 nếu lỗi
+func loadPost(c *gin.Context) (*models.Post, bool) {
+	return findPost(c, database.DB)
+}
+
+// findPost giống loadPost nhưng chạy trên query cho trước (đã Select)
+func findPost(c *gin.Context, query *gorm.DB) (*models.Post, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post id"})
+		return nil, false
+	}
+
+	post, err := firstVisiblePost(c, query.Where("`posts`.`id` = ?", id))
+	if err != nil {
+		writePostError(c, err)
+		return nil, false
+	}
+	return post, true
+}
+
+// firstVisiblePost lấy post đầu tiên của query, trả về gorm.ErrRecordNotFound
+// cả khi post tồn tại nhưng user hiện tại không được xem
+func firstVisiblePost(c *gin.Context, query *gorm.DB) (*models.Post, error) {
+	var post models.Post
+	if err := query.First(&post).Error; err != nil {
+		return nil, err
+	}
+	if !canViewPost(c, &post) {
+		// Không để lộ sự tồn tại của post chưa xuất bản
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &post, nil
+}
+
+func writePostError(c *gin.Context, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+// postVisibility trả về điều kiện SQL (trên bảng table) giới hạn các post user hiện tại được xem:
+// admin xem tất cả, user xem post đã xuất bản và post của chính mình, khách chỉ xem post đã xuất bản
+func postVisibility(c *gin.Context, table string) (string, []interface{}) {
+	user, ok := middleware.CurrentUser(c)
+	switch {
+	case ok && user.IsAdmin():
+		return "", nil
+	case ok:
+		return fmt.Sprintf("(`%s`.status = ? OR `%s`.author_id = ?)", table, table), []interface{}{models.PostPublished, user.ID}
+	default:
+		return fmt.Sprintf("`%s`.status = ?", table), []interface{}{models.PostPublished}
+	}
+}
+
+// postMention là nội dung post chứa mention, dùng cho services.SaveMentions
+func postMention(post *models.Post) models.Mention {
+	return models.Mention{AuthorID: post.AuthorID, TargetType: models.MentionTargetPost, TargetID: post.ID, PostID: post.ID}
+}
+
+// canViewPost giống postVisibility nhưng kiểm tra trên post đã load
+func canViewPost(c *gin.Context, post *models.Post) bool {
+	if post.IsPublished() {
+		return true
+	}
+	user, ok := middleware.CurrentUser(c)
+	return ok && (user.ID == post.AuthorID || user.IsAdmin())
+}
+
+// canModifyPost chỉ cho tác giả hoặc admin sửa/xóa post, ghi response 403 nếu không
+func canModifyPost(c *gin.Context, post *models.Post) bool {
+	user, ok := middleware.CurrentUser(c)
+	if !ok || (user.ID != post.AuthorID && !user.IsAdmin()) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author or an admin can modify this post"})
+		return false
+	}
+	return true
+}
+
+// changedPostColumns chỉ trả về các cột thực sự thay đổi
+func changedPostColumns(post *models.Post, input postInput) map[string]interface{} {
+	updates := map[string]interface{}{}
+	if input.Title != post.Title {
+		updates["title"] = input.Title
+	}
+	if input.Content != post.Content {
+		updates["content"] = input.Content
+	}
+	return updates
+}
+
+// savePost cập nhật các cột thay đổi và trả về post mới
+func savePost(c *gin.Context, post *models.Post, updates map[string]interface{}) {
+	if err := updatePost(c, post, updates, nil); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, post)
+}
+
+// updatePost cập nhật post và ghi revision mới nếu title/content thay đổi,
+// restoredFrom khác nil khi cập nhật là do khôi phục revision cũ
+func updatePost(c *gin.Context, post *models.Post, updates map[string]interface{}, restoredFrom *uint) error {
+	if len(updates) == 0 {
+		return nil
+	}
+	editor, _ := middleware.CurrentUser(c)
+	previous := *post
+	content, contentChanged := updates["content"].(string)
+	var mentioned []models.User
+	if contentChanged {
+		html, users, err := services.RenderContent(database.DB, content)
+		if err != nil {
+			return err
+		}
+		updates["content_html"], mentioned = html, users
+	}
+
+	title, titleChanged := updates["title"].(string)
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Đổi title thì đổi slug, slug cũ vẫn chuyển hướng được
+		if titleChanged {
+			if _, err := services.ChangePostSlug(tx, post, title, func(slug string) error {
+				delete(updates, "slug")
+				if slug != previous.Slug {
+					updates["slug"] = slug
+				}
+				return tx.Model(post).Updates(updates).Error
+			}); err != nil {
+				return err
+			}
+		} else if err := tx.Model(post).Updates(updates).Error; err != nil {
+			return err
+		}
+		if !titleChanged && !contentChanged {
+			return nil
+		}
+		if err := recordPostRevision(tx, &previous, post, editor.ID, restoredFrom); err != nil {
+			return err
+		}
+		if !contentChanged {
+			return nil
+		}
+		return saveMentions(tx, postMention(post), previous.Content, mentioned)
+	})
+	if err == nil && contentChanged && len(mentioned) > 0 && post.IsPublished() {
+		notifyMentions(post.ID)
+	}
+	if err == nil && (titleChanged || contentChanged) {
+		if !titleChanged {
+			title = previous.Title
+		}
+		if !contentChanged {
+			content = previous.Content
+		}
+		flagBannedWords(models.ReportTargetPost, post.ID, title, content)
+	}
+	return err
+}
@@ -0,0 +1,581 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"myapp/database"
+	"myapp/models"
+)
+
+var postColumns = []string{"id", "title", "content", "author_id", "created_at", "updated_at"}
+
+func expectLoadPost(mock sqlmock.Sqlmock, authorID uint) {
+	expectLoadPostWithStatus(mock, authorID, models.PostPublished)
+}
+
+func expectLoadPostWithStatus(mock sqlmock.Sqlmock, authorID uint, status string) {
+	mock.ExpectQuery("SELECT \\* FROM `posts` WHERE `posts`.`id` = \\?").
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows(append(postColumns, "status")).
+			AddRow(1, "Hello", "World", authorID, time.Now(), time.Now(), status))
+}
+
+// expectReactionSummaries khớp truy vấn bộ đếm reaction (và reaction của user nếu withMine)
+func expectReactionSummaries(mock sqlmock.Sqlmock, withMine bool) {
+	mock.ExpectQuery("SELECT \\* FROM `reaction_counts` WHERE target_type = \\? AND target_id IN").
+		WillReturnRows(sqlmock.NewRows([]string{"target_type", "target_id", "type", "count"}))
+	if withMine {
+		mock.ExpectQuery("SELECT `target_id`,`type` FROM `reactions` WHERE target_type = \\? AND target_id IN").
+			WillReturnRows(sqlmock.NewRows([]string{"target_id", "type"}))
+	}
+}
+
+// expectBookmarks khớp truy vấn cờ bookmarked của user hiện tại (postIDs đã nằm trong danh sách đọc)
+func expectBookmarks(mock sqlmock.Sqlmock, postIDs ...uint) {
+	rows := sqlmock.NewRows([]string{"post_id"})
+	for _, id := range postIDs {
+		rows.AddRow(id)
+	}
+	mock.ExpectQuery("SELECT DISTINCT `reading_list_items`.`post_id` FROM `reading_list_items` JOIN reading_lists").
+		WillReturnRows(rows)
+}
+
+// expectUniqueSlug khớp hai truy vấn kiểm tra slug (chưa post nào dùng)
+func expectUniqueSlug(mock sqlmock.Sqlmock, slug string, postID uint) {
+	mock.ExpectQuery("SELECT `slug` FROM `posts` WHERE id <> \\? AND \\(slug = \\? OR slug LIKE \\?\\)").
+		WithArgs(postID, slug, slug+"-%").
+		WillReturnRows(sqlmock.NewRows([]string{"slug"}))
+	mock.ExpectQuery("SELECT `slug` FROM `post_slugs` WHERE post_id <> \\? AND \\(slug = \\? OR slug LIKE \\?\\)").
+		WithArgs(postID, slug, slug+"-%").
+		WillReturnRows(sqlmock.NewRows([]string{"slug"}))
+}
+
+// expectSlugHistory khớp truy vấn sau khi đổi slug của post chưa có slug (không cần lưu slug cũ)
+func expectSlugHistory(mock sqlmock.Sqlmock, slug string) {
+	mock.ExpectExec("DELETE FROM `post_slugs` WHERE slug = \\?").
+		WithArgs(slug).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func TestCreatePost_UsesAuthenticatedAuthor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectBegin()
+	expectUniqueSlug(mock, "hello", 0)
+	mock.ExpectExec("INSERT INTO `posts`").
+		WithArgs("Hello", "World", "<p>World</p>\n", 7, models.PostDraft, nil, nil, sqlmock.AnyArg(), sqlmock.AnyArg(), "hello").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(revision\\), 0\\) FROM `post_revisions` WHERE post_id = \\?").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(0))
+	mock.ExpectExec("INSERT INTO `post_revisions`").
+		WithArgs(1, 1, "Hello", "World", 7, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	user := &models.User{ID: 7, Role: models.RoleUser}
+	c, w := newAuthedContext(user, "POST", "/posts", []byte(`{"title":"Hello","content":"World","author_id":99}`))
+	CreatePost(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response models.Post
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, uint(7), response.AuthorID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreatePost_ValidationError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "POST", "/posts", []byte(`{"content":"no title"}`))
+	CreatePost(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestUpdatePost_ForbiddenForOtherUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadPost(mock, 7)
+
+	c, w := newAuthedContext(&models.User{ID: 8, Role: models.RoleUser}, "PUT", "/posts/1", []byte(`{"title":"Hacked"}`))
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	UpdatePost(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPatchPost_AdminCanModify(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadPost(mock, 7)
+
+	mock.ExpectBegin()
+	expectUniqueSlug(mock, "edited", 1)
+	mock.ExpectExec("UPDATE `posts` SET `slug`=\\?,`title`=\\?,`updated_at`=\\? WHERE `id` = \\?").
+		WithArgs("edited", "Edited", sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectSlugHistory(mock, "edited")
+	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(revision\\), 0\\) FROM `post_revisions`").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(4))
+	mock.ExpectExec("INSERT INTO `post_revisions`").
+		WithArgs(1, 5, "Edited", "World", 1, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(9, 1))
+	mock.ExpectCommit()
+
+	c, w := newAuthedContext(&models.User{ID: 1, Role: models.RoleAdmin}, "PATCH", "/posts/1", []byte(`{"title":"Edited"}`))
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	PatchPost(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"title":"Edited"`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPatchPost_RejectsAuthorChange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadPost(mock, 7)
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "PATCH", "/posts/1", []byte(`{"author_id":8}`))
+	c.Request.Header.Set("Content-Type", mergePatchContentType)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	PatchPost(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "author_id is read-only")
+}
+
+func TestPatchPost_MergePatchWithComments(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	mock.ExpectQuery("SELECT \\* FROM `posts` WHERE `posts`.`id` = \\?").
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows(append(postColumns, "slug", "status", "comment_count", "view_count")).
+			AddRow(1, "Hello", "World", 7, time.Now(), time.Now(), "hello", models.PostPublished, 3, 10))
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `posts` SET `content`=\\?,`content_html`=\\?,`updated_at`=\\? WHERE `id` = \\?").
+		WithArgs("Edited", "<p>Edited</p>\n", sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(revision\\), 0\\) FROM `post_revisions`").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(4))
+	mock.ExpectExec("INSERT INTO `post_revisions`").
+		WithArgs(1, 5, "Hello", "Edited", 7, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(9, 1))
+	mock.ExpectCommit()
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "PATCH", "/posts/1", []byte(`{"content":"Edited"}`))
+	c.Request.Header.Set("Content-Type", mergePatchContentType)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	PatchPost(c)
+
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"content":"Edited"`)
+	assert.Contains(t, w.Body.String(), `"comment_count":3`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPatchPost_RejectsServerManagedFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for _, field := range []string{"status", "published_at", "comment_count", "view_count"} {
+		mock, gormDB := setupTestDB(t)
+		database.DB = gormDB
+		expectLoadPost(mock, 7)
+
+		c, w := newAuthedContext(&models.User{ID: 7}, "PATCH", "/posts/1", []byte(`{"`+field+`":null}`))
+		c.Request.Header.Set("Content-Type", mergePatchContentType)
+		c.Params = gin.Params{{Key: "id", Value: "1"}}
+		PatchPost(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, field)
+		assert.Contains(t, w.Body.String(), field+" is read-only")
+	}
+}
+
+func TestDeletePost_Author(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadPost(mock, 7)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `reactions` WHERE target_type = \\? AND target_id IN \\(SELECT `id` FROM `comments` WHERE post_id = \\?\\)").
+		WithArgs(models.ReactionTargetComment, 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM `reaction_counts` WHERE target_type = \\? AND target_id IN \\(SELECT `id` FROM `comments` WHERE post_id = \\?\\)").
+		WithArgs(models.ReactionTargetComment, 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM `reactions` WHERE target_type = \\? AND target_id IN \\(\\?\\)").
+		WithArgs(models.ReactionTargetPost, 1).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("DELETE FROM `reaction_counts` WHERE target_type = \\? AND target_id IN \\(\\?\\)").
+		WithArgs(models.ReactionTargetPost, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE tags SET usage_count = usage_count - 1 WHERE usage_count > 0 AND id IN \\(SELECT tag_id FROM post_tags WHERE post_id = \\?\\)").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM `posts` WHERE `posts`.`id` = \\?").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "DELETE", "/posts/1", nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	DeletePost(c)
+
+	assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+	assert.Empty(t, w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPosts_Pagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `posts`").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+	mock.ExpectQuery("SELECT \\* FROM `posts` WHERE `posts`.status = \\? ORDER BY id DESC LIMIT \\? OFFSET \\?").
+		WithArgs(models.PostPublished, 2, 2).
+		WillReturnRows(sqlmock.NewRows(postColumns).
+			AddRow(3, "C", "", 7, time.Now(), time.Now()).
+			AddRow(2, "B", "", 7, time.Now(), time.Now()))
+	expectReactionSummaries(mock, false)
+
+	c, w := newListRequest("/posts?page=2&per_page=2")
+	GetPosts(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "5", w.Header().Get("X-Total-Count"))
+	assert.Contains(t, w.Header().Get("Link"), `page=3&per_page=2>; rel="next"`)
+	assert.Contains(t, w.Header().Get("Link"), `page=1&per_page=2>; rel="prev"`)
+	var response []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response, 2)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPosts_InvalidPagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, target := range []string{"/posts?page=0", "/posts?per_page=1000", "/posts?page=abc"} {
+		c, w := newListRequest(target)
+		GetPosts(c)
+		assert.Equal(t, http.StatusBadRequest, w.Code, target)
+	}
+}
+
+func TestGetUserPosts_UserNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `users` WHERE id = \\?").
+		WithArgs(42).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	c, w := newListRequest("/users/42/posts")
+	c.Params = gin.Params{{Key: "id", Value: "42"}}
+	GetUserPosts(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUserPosts_FiltersByAuthor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `users` WHERE id = \\?").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `posts` WHERE author_id = \\? AND `posts`.status = \\?").
+		WithArgs(7, models.PostPublished).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT `id`,`title`,`author_id`,`status` FROM `posts` WHERE author_id = \\? AND `posts`.status = \\? ORDER BY id DESC LIMIT \\?").
+		WithArgs(7, models.PostPublished, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "author_id", "status"}).AddRow(1, "Hello", 7, "published"))
+
+	c, w := newListRequest("/users/7/posts?fields=id,title")
+	c.Params = gin.Params{{Key: "id", Value: "7"}}
+	GetUserPosts(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"id":1,"title":"Hello"}]`, w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPost_DraftHiddenFromOthers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadPostWithStatus(mock, 7, models.PostDraft)
+
+	c, w := newAuthedContext(&models.User{ID: 8}, "GET", "/posts/1", nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	GetPost(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetPost_DraftVisibleToAuthor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadPostWithStatus(mock, 7, models.PostDraft)
+	expectReactionSummaries(mock, true)
+	expectBookmarks(mock, 1)
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "GET", "/posts/1", nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	GetPost(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"draft"`)
+	assert.Contains(t, w.Body.String(), `"bookmarked":true`)
+}
+
+func TestPatchPost_ContentStoresRenderedHTML(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadPost(mock, 7)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `posts` SET `content`=\\?,`content_html`=\\?,`updated_at`=\\? WHERE `id` = \\?").
+		WithArgs("**Hi** <script>x</script>", "<p><strong>Hi</strong> </p>\n", sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(revision\\), 0\\) FROM `post_revisions`").
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(1))
+	mock.ExpectExec("INSERT INTO `post_revisions`").
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "PATCH", "/posts/1", []byte(`{"content":"**Hi** <script>x</script>"}`))
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	PatchPost(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "content_html")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPost_FormatHTML(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT `id`,`content`,`author_id`,`status`,`content_html` FROM `posts` WHERE `posts`.`id` = \\?").
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "content", "author_id", "status", "content_html"}).
+			AddRow(1, "# Hi", 7, models.PostPublished, `<h1 id="hi">Hi</h1>`))
+
+	c, w := newListRequest("/posts/1?fields=id,content&format=html")
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	GetPost(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":1,"content":"<h1 id=\"hi\">Hi</h1>"}`, w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPost_FormatHTMLRendersLegacyPost(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	// Post tạo trước khi có content_html
+	mock.ExpectQuery("SELECT `id`,`content`,`author_id`,`status`,`content_html` FROM `posts`").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "content", "author_id", "status", "content_html"}).
+			AddRow(1, "*old*", 7, models.PostPublished, nil))
+
+	c, w := newListRequest("/posts/1?fields=id,content&format=html")
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	GetPost(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":1,"content":"<p><em>old</em></p>\n"}`, w.Body.String())
+}
+
+func TestGetPost_InvalidFormat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	c, w := newListRequest("/posts/1?format=pdf")
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	GetPost(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSetPostStatus_Publish(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadPostWithStatus(mock, 7, models.PostDraft)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `posts` SET `published_at`=\\?,`scheduled_for`=\\?,`status`=\\?,`updated_at`=\\? WHERE status = \\? AND `id` = \\?").
+		WithArgs(sqlmock.AnyArg(), nil, models.PostPublished, sqlmock.AnyArg(), models.PostDraft, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	// Xuất bản thì gửi thông báo cho các mention đang chờ
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT mentions.\\* FROM `mentions` .* WHERE \\(mentions.post_id = \\?").
+		WithArgs(models.MentionTargetComment, 1, models.PostPublished).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectCommit()
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "PUT", "/posts/1/status", []byte(`{"status":"published"}`))
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	SetPostStatus(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"published"`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetPostStatus_InvalidTransition(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadPostWithStatus(mock, 7, models.PostPublished)
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "PUT", "/posts/1/status", []byte(`{"status":"scheduled","scheduled_for":"2999-01-01T00:00:00Z"}`))
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	SetPostStatus(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestSetPostStatus_ScheduleInPast(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadPostWithStatus(mock, 7, models.PostDraft)
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "PUT", "/posts/1/status", []byte(`{"status":"scheduled","scheduled_for":"2000-01-01T00:00:00Z"}`))
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	SetPostStatus(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSetPostStatus_ConcurrentChange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadPostWithStatus(mock, 7, models.PostScheduled)
+
+	// Scheduler đã xuất bản post trước đó
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `posts` SET").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "PUT", "/posts/1/status", []byte(`{"status":"draft"}`))
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	SetPostStatus(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPostBySlug(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT `id`,`title`,`slug`,`author_id`,`status` FROM `posts` WHERE slug = \\?").
+		WithArgs("xin-chao", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "slug", "author_id", "status"}).
+			AddRow(1, "Xin chào", "xin-chao", 7, models.PostPublished))
+
+	c, w := newListRequest("/api/posts/by-slug/xin-chao?fields=id,title,slug")
+	c.Params = gin.Params{{Key: "slug", Value: "xin-chao"}}
+	GetPostBySlug(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":1,"title":"Xin chào","slug":"xin-chao"}`, w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPostBySlug_RedirectsOldSlug(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT \\* FROM `posts` WHERE slug = \\?").
+		WithArgs("hello", 1).
+		WillReturnRows(sqlmock.NewRows(postColumns))
+	mock.ExpectQuery("SELECT \\* FROM `post_slugs` WHERE slug = \\?").
+		WithArgs("hello", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"slug", "post_id"}).AddRow("hello", 1))
+	mock.ExpectQuery("SELECT `id`,`slug`,`author_id`,`status` FROM `posts` WHERE id = \\?").
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "author_id", "status"}).
+			AddRow(1, "xin-chao", 7, models.PostPublished))
+
+	c, w := newListRequest("/api/posts/by-slug/hello?format=html")
+	c.Params = gin.Params{{Key: "slug", Value: "hello"}}
+	GetPostBySlug(c)
+
+	assert.Equal(t, http.StatusMovedPermanently, w.Code)
+	assert.Equal(t, "/api/posts/by-slug/xin-chao?format=html", w.Header().Get("Location"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPostBySlug_OldSlugOfDraftHidden(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT \\* FROM `posts` WHERE slug = \\?").
+		WillReturnRows(sqlmock.NewRows(postColumns))
+	mock.ExpectQuery("SELECT \\* FROM `post_slugs` WHERE slug = \\?").
+		WillReturnRows(sqlmock.NewRows([]string{"slug", "post_id"}).AddRow("hello", 1))
+	mock.ExpectQuery("SELECT `id`,`slug`,`author_id`,`status` FROM `posts` WHERE id = \\?").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "author_id", "status"}).
+			AddRow(1, "xin-chao", 7, models.PostDraft))
+
+	c, w := newAuthedContext(&models.User{ID: 8}, "GET", "/api/posts/by-slug/hello", nil)
+	c.Params = gin.Params{{Key: "slug", Value: "hello"}}
+	GetPostBySlug(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPostBySlug_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT \\* FROM `posts` WHERE slug = \\?").
+		WillReturnRows(sqlmock.NewRows(postColumns))
+	mock.ExpectQuery("SELECT \\* FROM `post_slugs` WHERE slug = \\?").
+		WillReturnRows(sqlmock.NewRows([]string{"slug", "post_id"}))
+
+	c, w := newListRequest("/api/posts/by-slug/missing")
+	c.Params = gin.Params{{Key: "slug", Value: "missing"}}
+	GetPostBySlug(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
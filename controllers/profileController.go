@@ -0,0 +1,227 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"myapp/database"
+	"myapp/mailer"
+	"myapp/middleware"
+	"myapp/models"
+	"myapp/services"
+)
+
+// Thời gian hiệu lực của mã xác nhận đổi email
+const emailChangeTTL = 24 * time.Hour
+
+// GET /users/me
+func GetMe(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+	c.JSON(http.StatusOK, user.Profile())
+}
+
+// PATCH /users/me
+func UpdateMe(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+
+	var body struct {
+		Name   *string `json:"name" binding:"omitempty,min=1,max=255"`
+		Handle *string `json:"handle"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if body.Name != nil {
+		updates["name"] = *body.Name
+	}
+	if body.Handle != nil && services.NormalizeHandle(*body.Handle) != user.Handle {
+		handle, ok := checkHandle(c, *body.Handle, user.ID)
+		if !ok {
+			return
+		}
+		updates["handle"] = handle
+	}
+	if len(updates) > 0 {
+		if err := database.DB.Model(user).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, user.Profile())
+}
+
+// POST /users/me/password
+func ChangePassword(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+
+	var body struct {
+		CurrentPassword string `json:"current_password" binding:"required"`
+		NewPassword     string `json:"new_password" binding:"required,min=8,max=72"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !user.CheckPassword(body.CurrentPassword) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+		return
+	}
+
+	hash, err := models.HashPassword(body.NewPassword)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Tăng token_version để thu hồi mọi token đã phát hành trước đó
+	if err := database.DB.Model(user).Updates(map[string]interface{}{
+		"password":      hash,
+		"token_version": user.TokenVersion + 1,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Phát hành token mới cho phiên hiện tại
+	tokenString, err := generateToken(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"token": tokenString})
+}
+
+// POST /users/me/email
+func RequestEmailChange(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+
+	var body struct {
+		NewEmail string `json:"new_email" binding:"required,email"`
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !user.CheckPassword(body.Password) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Password is incorrect"})
+		return
+	}
+	if body.NewEmail == user.Email {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "New email must be different from current email"})
+		return
+	}
+
+	inUse, err := emailInUse(body.NewEmail, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if inUse {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email is already in use"})
+		return
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	change := models.EmailChange{
+		UserID:    user.ID,
+		OldEmail:  user.Email,
+		NewEmail:  body.NewEmail,
+		Token:     token,
+		ExpiresAt: time.Now().Add(emailChangeTTL),
+	}
+	if err := database.DB.Create(&change).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Gửi mã xác nhận tới địa chỉ mới và thông báo tới địa chỉ cũ
+	sendMail(change.NewEmail, "Confirm your new email address",
+		fmt.Sprintf("Use this code to confirm your new email address: %s\nThe code expires at %s.",
+			token, change.ExpiresAt.Format(time.RFC1123)))
+	sendMail(change.OldEmail, "Email change requested",
+		fmt.Sprintf("A request was made to change your account email to %s.\nIf this was not you, change your password immediately.",
+			change.NewEmail))
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Confirmation sent to the new email address"})
+}
+
+// POST /users/me/email/confirm
+func ConfirmEmailChange(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+
+	var body struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var change models.EmailChange
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("token = ? AND user_id = ?", body.Token, user.ID).First(&change).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		if !change.IsPending(now) {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.Model(user).Update("email", change.NewEmail).Error; err != nil {
+			return err
+		}
+		return tx.Model(&change).Update("confirmed_at", now).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired token"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Xác nhận việc đổi email tới cả hai địa chỉ
+	for _, to := range []string{change.OldEmail, change.NewEmail} {
+		sendMail(to, "Your email address was changed",
+			fmt.Sprintf("Your account email was changed from %s to %s.", change.OldEmail, change.NewEmail))
+	}
+
+	c.JSON(http.StatusOK, user.Profile())
+}
+
+// sendMail gửi email, lỗi chỉ ghi log để không làm hỏng request
+func sendMail(to, subject, body string) {
+	if err := mailer.Send(to, subject, body); err != nil {
+		log.Printf("❌ Không gửi được email tới %s: %v", to, err)
+	}
+}
+
+// randomToken sinh chuỗi ngẫu nhiên dạng hex (64 ký tự)
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,251 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+
+	"myapp/database"
+	"myapp/mailer"
+	"myapp/middleware"
+	"myapp/models"
+)
+
+// fakeMailer ghi lại các email đã gửi để kiểm tra
+type fakeMailer struct {
+	sent []string
+}
+
+func (f *fakeMailer) Send(to, subject, body string) error {
+	f.sent = append(f.sent, to)
+	return nil
+}
+
+// newAuthedContext tạo gin.Context đã có user đăng nhập
+func newAuthedContext(user *models.User, method, path string, body []byte) (*gin.Context, *httptest.ResponseRecorder) {
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Set(middleware.CurrentUserKey, user)
+	return c, w
+}
+
+func TestGetMe_HidesPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	user := &models.User{ID: 1, Name: "John Doe", Email: "john@example.com", Password: "secret"}
+
+	c, w := newAuthedContext(user, "GET", "/users/me", nil)
+	GetMe(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "john@example.com")
+	assert.NotContains(t, w.Body.String(), "password")
+}
+
+func TestUpdateMe_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `users` SET `name`=\\?,`version`=version \\+ 1 WHERE `id` = \\?").
+		WithArgs("Jane Doe", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	user := &models.User{ID: 1, Name: "John Doe", Email: "john@example.com", Version: 2}
+	body, _ := json.Marshal(map[string]string{"name": "Jane Doe"})
+	c, w := newAuthedContext(user, "PATCH", "/users/me", body)
+	UpdateMe(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.UserProfile
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "Jane Doe", response.Name)
+	assert.Equal(t, uint(3), response.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateMe_EmptyName(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	user := &models.User{ID: 1, Name: "John Doe"}
+
+	body, _ := json.Marshal(map[string]string{"name": ""})
+	c, w := newAuthedContext(user, "PATCH", "/users/me", body)
+	UpdateMe(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestUpdateMe_Handle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `users` WHERE handle = \\? AND id <> \\?").
+		WithArgs("jane_doe", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `users` SET `handle`=\\?,`version`=version \\+ 1 WHERE `id` = \\?").
+		WithArgs("jane_doe", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	user := &models.User{ID: 1, Name: "Jane Doe", Handle: "janedoe", Version: 2}
+	body, _ := json.Marshal(map[string]string{"handle": "@Jane_Doe"})
+	c, w := newAuthedContext(user, "PATCH", "/users/me", body)
+	UpdateMe(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"handle":"jane_doe"`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateMe_HandleTaken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `users` WHERE handle = \\? AND id <> \\?").
+		WithArgs("alice", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	body, _ := json.Marshal(map[string]string{"handle": "alice"})
+	c, w := newAuthedContext(&models.User{ID: 1, Handle: "janedoe"}, "PATCH", "/users/me", body)
+	UpdateMe(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateMe_ReservedHandle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	body, _ := json.Marshal(map[string]string{"handle": "Admin"})
+	c, w := newAuthedContext(&models.User{ID: 1, Handle: "janedoe"}, "PATCH", "/users/me", body)
+	UpdateMe(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "handle is reserved")
+}
+
+func TestChangePassword_WrongCurrentPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	user := &models.User{ID: 1, Password: "old-password"}
+
+	body, _ := json.Marshal(map[string]string{
+		"current_password": "not-my-password",
+		"new_password":     "new-password",
+	})
+	c, w := newAuthedContext(user, "POST", "/users/me/password", body)
+	ChangePassword(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestChangePassword_RevokesOtherSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `users` SET `password`=\\?,`token_version`=\\?,`version`=version \\+ 1 WHERE `id` = \\?").
+		WithArgs(sqlmock.AnyArg(), 4, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	user := &models.User{ID: 1, Password: "old-password", TokenVersion: 3}
+	body, _ := json.Marshal(map[string]string{
+		"current_password": "old-password",
+		"new_password":     "new-password",
+	})
+	c, w := newAuthedContext(user, "POST", "/users/me/password", body)
+	ChangePassword(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, user.CheckPassword("new-password"))
+
+	// Token mới phải mang version mới
+	var response map[string]string
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	token, _, err := jwt.NewParser().ParseUnverified(response["token"], jwt.MapClaims{})
+	assert.NoError(t, err)
+	assert.Equal(t, float64(4), token.Claims.(jwt.MapClaims)["ver"])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRequestEmailChange_EmailTaken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `users` WHERE email = \\?").
+		WithArgs("jane@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	user := &models.User{ID: 1, Email: "john@example.com", Password: "password"}
+	body, _ := json.Marshal(map[string]string{"new_email": "jane@example.com", "password": "password"})
+	c, w := newAuthedContext(user, "POST", "/users/me/email", body)
+	RequestEmailChange(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRequestEmailChange_NotifiesBothAddresses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	fake := &fakeMailer{}
+	previous := mailer.Default
+	mailer.Default = fake
+	defer func() { mailer.Default = previous }()
+
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `users` WHERE email = \\?").
+		WithArgs("jane@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `email_changes`").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	user := &models.User{ID: 1, Email: "john@example.com", Password: "password"}
+	body, _ := json.Marshal(map[string]string{"new_email": "jane@example.com", "password": "password"})
+	c, w := newAuthedContext(user, "POST", "/users/me/email", body)
+	RequestEmailChange(c)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.ElementsMatch(t, []string{"jane@example.com", "john@example.com"}, fake.sent)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConfirmEmailChange_InvalidToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT \\* FROM `email_changes` WHERE token = \\? AND user_id = \\?").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	user := &models.User{ID: 1, Email: "john@example.com"}
+	body, _ := json.Marshal(map[string]string{"token": "unknown"})
+	c, w := newAuthedContext(user, "POST", "/users/me/email/confirm", body)
+	ConfirmEmailChange(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
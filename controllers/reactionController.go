@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"myapp/database"
+	"myapp/middleware"
+	"myapp/models"
+	"myapp/services"
+)
+
+// PUT /posts/:id/reactions/:type — idempotent, gọi lại nhiều lần vẫn chỉ tính một reaction
+func AddPostReaction(c *gin.Context) {
+	if post, ok := loadPost(c); ok {
+		react(c, reactionTarget{Type: models.ReactionTargetPost, ID: post.ID, AuthorID: post.AuthorID, PostID: post.ID}, true)
+	}
+}
+
+// DELETE /posts/:id/reactions/:type — idempotent
+func RemovePostReaction(c *gin.Context) {
+	if post, ok := loadPost(c); ok {
+		react(c, reactionTarget{Type: models.ReactionTargetPost, ID: post.ID, AuthorID: post.AuthorID, PostID: post.ID}, false)
+	}
+}
+
+// PUT /posts/:id/comments/:comment_id/reactions/:type
+func AddCommentReaction(c *gin.Context) {
+	if comment, ok := loadReactableComment(c); ok {
+		react(c, reactionTarget{Type: models.ReactionTargetComment, ID: comment.ID, AuthorID: comment.AuthorID, PostID: comment.PostID}, true)
+	}
+}
+
+// DELETE /posts/:id/comments/:comment_id/reactions/:type
+func RemoveCommentReaction(c *gin.Context) {
+	if comment, ok := loadReactableComment(c); ok {
+		react(c, reactionTarget{Type: models.ReactionTargetComment, ID: comment.ID, AuthorID: comment.AuthorID, PostID: comment.PostID}, false)
+	}
+}
+
+// loadReactableComment tìm comment của post mà user hiện tại xem được
+func loadReactableComment(c *gin.Context) (*models.Comment, bool) {
+	if _, ok := loadPost(c); !ok {
+		return nil, false
+	}
+	comment, ok := loadComment(c)
+	if !ok {
+		return nil, false
+	}
+	if comment.IsDeleted() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Comment has been deleted"})
+		return nil, false
+	}
+	if comment.IsHidden() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Comment has been hidden by a moderator"})
+		return nil, false
+	}
+	return comment, true
+}
+
+// reactionTarget là đối tượng được react, kèm tác giả để gửi thông báo
+type reactionTarget struct {
+	Type     string
+	ID       uint
+	AuthorID uint
+	PostID   uint
+}
+
+// react thêm/xóa reaction :type của user hiện tại rồi trả về tổng hợp mới
+func react(c *gin.Context, target reactionTarget, add bool) {
+	reactionType := c.Param("type")
+	if !models.IsReactionType(reactionType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported reaction type, allowed: " + strings.Join(models.ReactionTypes, ", ")})
+		return
+	}
+	user, _ := middleware.CurrentUser(c)
+
+	var err error
+	if add {
+		var added bool
+		if added, err = services.AddReaction(database.DB, target.Type, target.ID, user.ID, reactionType); added {
+			services.Notify(services.NotificationEvent{
+				Type:        models.NotificationReaction,
+				RecipientID: target.AuthorID,
+				ActorID:     user.ID,
+				TargetType:  target.Type,
+				TargetID:    target.ID,
+				PostID:      &target.PostID,
+			})
+		}
+	} else {
+		_, err = services.RemoveReaction(database.DB, target.Type, target.ID, user.ID, reactionType)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	summaries, err := services.ReactionSummaries(database.DB, target.Type, []uint{target.ID}, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, summaries[target.ID])
+}
+
+// attachReactions thêm "reactions" vào các bản ghi đã render (items[i] ứng với ids[i])
+func attachReactions(c *gin.Context, targetType string, ids []uint, items []map[string]interface{}) error {
+	var userID uint
+	if user, ok := middleware.CurrentUser(c); ok {
+		userID = user.ID
+	}
+	summaries, err := services.ReactionSummaries(database.DB, targetType, ids, userID)
+	if err != nil {
+		return err
+	}
+	for i, item := range items {
+		item["reactions"] = summaries[ids[i]]
+	}
+	return nil
+}
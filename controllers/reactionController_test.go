@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"myapp/database"
+	"myapp/models"
+)
+
+func TestAddPostReaction_ReturnsSummary(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadPost(mock, 7)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `reactions`").
+		WithArgs(models.ReactionTargetPost, 1, 8, "love", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+	mock.ExpectExec("INSERT INTO reaction_counts").
+		WithArgs(models.ReactionTargetPost, 1, "love").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT \\* FROM `reaction_counts`").
+		WillReturnRows(sqlmock.NewRows([]string{"target_type", "target_id", "type", "count"}).
+			AddRow(models.ReactionTargetPost, 1, "love", 2))
+	mock.ExpectQuery("SELECT `target_id`,`type` FROM `reactions`").
+		WithArgs(models.ReactionTargetPost, 1, 8).
+		WillReturnRows(sqlmock.NewRows([]string{"target_id", "type"}).AddRow(1, "love"))
+
+	c, w := newAuthedContext(&models.User{ID: 8}, "PUT", "/posts/1/reactions/love", nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}, {Key: "type", Value: "love"}}
+	AddPostReaction(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"counts":{"love":2},"mine":["love"]}`, w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddPostReaction_UnsupportedType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadPost(mock, 7)
+
+	c, w := newAuthedContext(&models.User{ID: 8}, "PUT", "/posts/1/reactions/meh", nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}, {Key: "type", Value: "meh"}}
+	AddPostReaction(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRemoveCommentReaction_NotReactedIsNoop(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadPost(mock, 7)
+	expectLoadComment(mock, 9, time.Now(), nil)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `reactions`").
+		WithArgs(models.ReactionTargetComment, 5, 8, "like").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	expectReactionSummaries(mock, true)
+
+	c, w := newAuthedContext(&models.User{ID: 8}, "DELETE", "/posts/1/comments/5/reactions/like", nil)
+	c.Params = append(commentParams(), gin.Param{Key: "type", Value: "like"})
+	RemoveCommentReaction(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"counts":{},"mine":[]}`, w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddCommentReaction_DeletedComment(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadPost(mock, 7)
+	expectLoadComment(mock, 9, time.Now(), time.Now())
+
+	c, w := newAuthedContext(&models.User{ID: 8}, "PUT", "/posts/1/comments/5/reactions/like", nil)
+	c.Params = append(commentParams(), gin.Param{Key: "type", Value: "like"})
+	AddCommentReaction(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,361 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"myapp/database"
+	"myapp/middleware"
+	"myapp/models"
+	"myapp/services"
+)
+
+// readingListColumns select danh sách kèm số post trong đó
+const readingListColumns = "reading_lists.*, (SELECT COUNT(*) FROM reading_list_items WHERE reading_list_items.list_id = reading_lists.id) AS item_count"
+
+// readingListInput là dữ liệu tạo/sửa danh sách; nil = giữ nguyên khi sửa
+type readingListInput struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=500"`
+	Public      *bool   `json:"public"`
+}
+
+// GET /lists — danh sách đọc của user hiện tại, mới tạo trước, phân trang như /posts
+func GetMyLists(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+	listReadingLists(c, database.DB.Where("user_id = ?", user.ID))
+}
+
+// GET /users/:id/lists — danh sách công khai của user (xem của chính mình thì có cả danh sách riêng tư)
+func GetUserLists(c *gin.Context) {
+	owner, ok := findUser(c, database.DB.Select("id"))
+	if !ok {
+		return
+	}
+	query := database.DB.Where("user_id = ?", owner.ID)
+	if user, _ := middleware.CurrentUser(c); user.ID != owner.ID {
+		query = query.Where("is_public = ?", true)
+	}
+	listReadingLists(c, query)
+}
+
+func listReadingLists(c *gin.Context, query *gorm.DB) {
+	page, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+	query = query.Model(&models.ReadingList{}).Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var lists []models.ReadingList
+	if err := page.apply(query).Select(readingListColumns).Order("created_at DESC, id DESC").Find(&lists).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	page.setHeaders(c, total)
+	jsonWithETag(c, http.StatusOK, lists)
+}
+
+// POST /lists
+func CreateList(c *gin.Context) {
+	user, _ := middleware.CurrentUser(c)
+
+	var body readingListInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if body.Name == nil || strings.TrimSpace(*body.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	list := models.ReadingList{UserID: user.ID, Name: strings.TrimSpace(*body.Name)}
+	if body.Description != nil {
+		list.Description = *body.Description
+	}
+	if body.Public != nil {
+		list.Public = *body.Public
+	}
+	if !checkListName(c, &list, list.Name) {
+		return
+	}
+	if err := database.DB.Create(&list).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, list)
+}
+
+// GET /lists/:id — chủ danh sách hoặc bất kỳ ai nếu danh sách công khai
+func GetList(c *gin.Context) {
+	list, ok := loadList(c, false)
+	if !ok {
+		return
+	}
+	jsonWithETag(c, http.StatusOK, list)
+}
+
+// PATCH /lists/:id — chỉ chủ danh sách
+func UpdateList(c *gin.Context) {
+	list, ok := loadList(c, true)
+	if !ok {
+		return
+	}
+
+	var body readingListInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if body.Name != nil {
+		name := strings.TrimSpace(*body.Name)
+		if name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "name must not be empty"})
+			return
+		}
+		if name != list.Name {
+			if !checkListName(c, list, name) {
+				return
+			}
+			updates["name"] = name
+		}
+	}
+	if body.Description != nil {
+		updates["description"] = *body.Description
+	}
+	if body.Public != nil {
+		updates["is_public"] = *body.Public
+	}
+	if len(updates) > 0 {
+		if err := database.DB.Model(list).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// DELETE /lists/:id — chỉ chủ danh sách; các item bị xóa theo khóa ngoại
+func DeleteList(c *gin.Context) {
+	list, ok := loadList(c, true)
+	if !ok {
+		return
+	}
+	if err := database.DB.Delete(list).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GET /lists/:id/items — các post trong danh sách theo thứ tự, phân trang như /posts,
+// hỗ trợ ?fields= và ?format=. Post người xem không được xem bị bỏ qua.
+func GetListItems(c *gin.Context) {
+	list, ok := loadList(c, false)
+	if !ok {
+		return
+	}
+	sel, ok := bindFieldSelection(c, postFields)
+	if !ok {
+		return
+	}
+	format, ok := bindContentFormat(c, sel)
+	if !ok {
+		return
+	}
+	page, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+
+	query := database.DB.Model(&models.ReadingListItem{}).
+		Joins("JOIN posts ON posts.id = reading_list_items.post_id").
+		Where("reading_list_items.list_id = ?", list.ID)
+	if cond, args := postVisibility(c, "posts"); cond != "" {
+		query = query.Where(cond, args...)
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var ids []uint
+	if err := page.apply(query).Order("reading_list_items.position, reading_list_items.post_id").
+		Pluck("reading_list_items.post_id", &ids).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	posts, err := loadTimelinePosts(c, sel, ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	items, err := renderPosts(c, sel, format, posts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	page.setHeaders(c, total)
+	jsonWithETag(c, http.StatusOK, items)
+}
+
+// PUT /lists/:id/items/:post_id — thêm post vào cuối danh sách, idempotent
+func AddListItem(c *gin.Context) {
+	list, ok := loadList(c, true)
+	if !ok {
+		return
+	}
+	postID, ok := listItemPostID(c)
+	if !ok {
+		return
+	}
+	post, err := firstVisiblePost(c, database.DB.Select("id", "author_id", "status").Where("id = ?", postID))
+	if err != nil {
+		writePostError(c, err)
+		return
+	}
+
+	added, err := services.AddListItem(database.DB, list.ID, post.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	status := http.StatusOK
+	if added {
+		status = http.StatusCreated
+	}
+	c.JSON(status, gin.H{"list_id": list.ID, "post_id": post.ID, "added": added})
+}
+
+// DELETE /lists/:id/items/:post_id — idempotent
+func RemoveListItem(c *gin.Context) {
+	list, ok := loadList(c, true)
+	if !ok {
+		return
+	}
+	postID, ok := listItemPostID(c)
+	if !ok {
+		return
+	}
+	if _, err := services.RemoveListItem(database.DB, list.ID, postID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// PUT /lists/:id/order — sắp lại danh sách, post_ids phải gồm mọi post user nhìn thấy trong danh sách
+// (như GET /lists/:id/items); post bị ẩn giữ nguyên chỗ
+func ReorderList(c *gin.Context) {
+	list, ok := loadList(c, true)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		PostIDs []uint `json:"post_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cond, args := postVisibility(c, "posts")
+	err := services.ReorderListItems(database.DB, list.ID, body.PostIDs, cond, args...)
+	if errors.Is(err, services.ErrListOrderMismatch) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// attachBookmarks gắn cờ "bookmarked": post đã nằm trong danh sách đọc nào đó của user hiện tại
+func attachBookmarks(c *gin.Context, ids []uint, items []map[string]interface{}) error {
+	var userID uint
+	if user, ok := middleware.CurrentUser(c); ok {
+		userID = user.ID
+	}
+	bookmarked, err := services.BookmarkedPosts(database.DB, userID, ids)
+	if err != nil {
+		return err
+	}
+	for i, item := range items {
+		item["bookmarked"] = bookmarked[ids[i]]
+	}
+	return nil
+}
+
+// loadList tìm danh sách theo :id mà user hiện tại được xem (ownerOnly: được sửa),
+// tự ghi response 400/403/404/500 nếu lỗi. Danh sách riêng tư của người khác trả 404.
+func loadList(c *gin.Context, ownerOnly bool) (*models.ReadingList, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid list id"})
+		return nil, false
+	}
+
+	var list models.ReadingList
+	if err := database.DB.Select(readingListColumns).First(&list, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "List not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return nil, false
+	}
+
+	user, _ := middleware.CurrentUser(c)
+	if !list.CanView(user.ID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "List not found"})
+		return nil, false
+	}
+	if ownerOnly && list.UserID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the owner can modify this list"})
+		return nil, false
+	}
+	return &list, true
+}
+
+// checkListName kiểm tra user chưa có danh sách nào khác tên name, ghi response 409/500 nếu không
+func checkListName(c *gin.Context, list *models.ReadingList, name string) bool {
+	var count int64
+	if err := database.DB.Model(&models.ReadingList{}).
+		Where("user_id = ? AND name = ? AND id <> ?", list.UserID, name, list.ID).
+		Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "You already have a list with this name"})
+		return false
+	}
+	return true
+}
+
+func listItemPostID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("post_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post id"})
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"myapp/database"
+	"myapp/models"
+)
+
+// expectLoadList khớp truy vấn đọc danh sách id 3 của ownerID
+func expectLoadList(mock sqlmock.Sqlmock, ownerID uint, public bool) {
+	mock.ExpectQuery("SELECT reading_lists.\\*, \\(SELECT COUNT\\(\\*\\) FROM reading_list_items .*\\) AS item_count FROM `reading_lists` WHERE `reading_lists`.`id` = \\?").
+		WithArgs(3, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name", "description", "is_public", "item_count", "created_at", "updated_at"}).
+			AddRow(3, ownerID, "Later", "", public, 2, time.Now(), time.Now()))
+}
+
+func listParams(extra ...gin.Param) gin.Params {
+	return append(gin.Params{{Key: "id", Value: "3"}}, extra...)
+}
+
+func TestGetList_PrivateHiddenFromOthers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadList(mock, 7, false)
+
+	c, w := newAuthedContext(&models.User{ID: 8}, "GET", "/lists/3", nil)
+	c.Params = listParams()
+	GetList(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetList_PublicVisibleToOthers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadList(mock, 7, true)
+
+	c, w := newAuthedContext(&models.User{ID: 8}, "GET", "/lists/3", nil)
+	c.Params = listParams()
+	GetList(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"item_count":2`)
+}
+
+func TestUpdateList_OnlyOwner(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadList(mock, 7, true)
+
+	c, w := newAuthedContext(&models.User{ID: 8}, "PATCH", "/lists/3", []byte(`{"name":"Mine"}`))
+	c.Params = listParams()
+	UpdateList(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestCreateList_NameTaken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `reading_lists` WHERE user_id = \\? AND name = \\? AND id <> \\?").
+		WithArgs(7, "Later", 0).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "POST", "/lists", []byte(`{"name":" Later "}`))
+	CreateList(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddListItem_HiddenPost(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadList(mock, 7, false)
+	mock.ExpectQuery("SELECT `id`,`author_id`,`status` FROM `posts` WHERE id = \\?").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "author_id", "status"}))
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "PUT", "/lists/3/items/1", nil)
+	c.Params = listParams(gin.Param{Key: "post_id", Value: "1"})
+	AddListItem(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReorderList_Mismatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadList(mock, 7, false)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT `id` FROM `reading_lists`").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectQuery("SELECT `post_id` FROM `reading_list_items` WHERE list_id = \\?").
+		WillReturnRows(sqlmock.NewRows([]string{"post_id"}).AddRow(1).AddRow(2))
+	mock.ExpectQuery("SELECT `reading_list_items`.`post_id` FROM `reading_list_items` JOIN posts .* AND \\(\\(`posts`.status = \\? OR `posts`.author_id = \\?\\)\\)").
+		WithArgs(3, models.PostPublished, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id"}).AddRow(1).AddRow(2))
+	mock.ExpectRollback()
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "PUT", "/lists/3/order", []byte(`{"post_ids":[1]}`))
+	c.Params = listParams()
+	ReorderList(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"myapp/config"
+	"myapp/database"
+	"myapp/models"
+	"myapp/textdiff"
+)
+
+// postRevisionRetention là số revision tối đa giữ lại cho mỗi post,
+// cấu hình qua POST_REVISION_RETENTION (0 = giữ tất cả)
+func postRevisionRetention() uint {
+	keep, err := strconv.ParseUint(config.GetEnv("POST_REVISION_RETENTION", "0"), 10, 32)
+	if err != nil {
+		return 0
+	}
+	return uint(keep)
+}
+
+// recordPostRevision ghi snapshot hiện tại của post thành revision mới và dọn revision cũ
+// theo cấu hình retention. previous là nội dung trước khi sửa (nil khi vừa tạo post).
+// Phải gọi trong transaction đã cập nhật dòng post để các lần ghi song song được tuần tự.
+func recordPostRevision(tx *gorm.DB, previous, post *models.Post, editorID uint, restoredFrom *uint) error {
+	var latest uint
+	if err := tx.Model(&models.PostRevision{}).Where("post_id = ?", post.ID).
+		Select("COALESCE(MAX(revision), 0)").Scan(&latest).Error; err != nil {
+		return err
+	}
+
+	if latest == 0 && previous != nil {
+		// Post có từ trước khi lưu lịch sử: giữ nội dung cũ làm revision đầu tiên
+		original := models.PostRevision{
+			PostID: post.ID, Revision: 1, Title: previous.Title, Content: previous.Content,
+			EditorID: previous.AuthorID, CreatedAt: previous.UpdatedAt,
+		}
+		if err := tx.Create(&original).Error; err != nil {
+			return err
+		}
+		latest = 1
+	}
+
+	revision := models.PostRevision{
+		PostID: post.ID, Revision: latest + 1, Title: post.Title, Content: post.Content,
+		EditorID: editorID, RestoredFrom: restoredFrom,
+	}
+	if err := tx.Create(&revision).Error; err != nil {
+		return err
+	}
+
+	if keep := postRevisionRetention(); keep > 0 && revision.Revision > keep {
+		return tx.Where("post_id = ? AND revision <= ?", post.ID, revision.Revision-keep).
+			Delete(&models.PostRevision{}).Error
+	}
+	return nil
+}
+
+// GET /posts/:id/revisions — lịch sử sửa đổi (mới nhất trước, không kèm content)
+func GetPostRevisions(c *gin.Context) {
+	post, ok := loadPost(c)
+	if !ok || !canModifyPost(c, post) {
+		return
+	}
+	page, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+
+	query := database.DB.Model(&models.PostRevision{}).Where("post_id = ?", post.ID).Session(&gorm.Session{})
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	revisions := []models.PostRevision{}
+	if err := page.apply(query).Omit("content").Order("revision DESC").Find(&revisions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	page.setHeaders(c, total)
+	c.JSON(http.StatusOK, revisions)
+}
+
+// GET /posts/:id/revisions/:rev
+func GetPostRevision(c *gin.Context) {
+	post, ok := loadPost(c)
+	if !ok || !canModifyPost(c, post) {
+		return
+	}
+	revision, ok := loadPostRevision(c, post.ID, c.Param("rev"))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, revision)
+}
+
+// GET /posts/:id/revisions/diff?from=1&to=3&mode=line|word
+func DiffPostRevisions(c *gin.Context) {
+	post, ok := loadPost(c)
+	if !ok || !canModifyPost(c, post) {
+		return
+	}
+
+	diff := textdiff.Lines
+	switch c.DefaultQuery("mode", "line") {
+	case "line":
+	case "word":
+		diff = textdiff.Words
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be line or word"})
+		return
+	}
+
+	from, ok := loadPostRevision(c, post.ID, c.Query("from"))
+	if !ok {
+		return
+	}
+	to, ok := loadPostRevision(c, post.ID, c.Query("to"))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":    from.Revision,
+		"to":      to.Revision,
+		"mode":    c.DefaultQuery("mode", "line"),
+		"title":   textdiff.Words(from.Title, to.Title),
+		"content": diff(from.Content, to.Content),
+	})
+}
+
+// POST /posts/:id/revisions/:rev/restore — đưa post về nội dung của revision cũ,
+// bản thân việc khôi phục cũng tạo ra một revision mới
+func RestorePostRevision(c *gin.Context) {
+	post, ok := loadPost(c)
+	if !ok || !canModifyPost(c, post) {
+		return
+	}
+	revision, ok := loadPostRevision(c, post.ID, c.Param("rev"))
+	if !ok {
+		return
+	}
+
+	updates := changedPostColumns(post, postInput{Title: revision.Title, Content: revision.Content})
+	if len(updates) == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Post already matches this revision"})
+		return
+	}
+	if err := updatePost(c, post, updates, &revision.Revision); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, post)
+}
+
+// loadPostRevision tìm revision theo số thứ tự, tự ghi response 400/404/500 nếu lỗi
+func loadPostRevision(c *gin.Context, postID uint, raw string) (*models.PostRevision, bool) {
+	number, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil || number == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision number"})
+		return nil, false
+	}
+
+	var revision models.PostRevision
+	if err := database.DB.Where("post_id = ? AND revision = ?", postID, number).First(&revision).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return nil, false
+	}
+	return &revision, true
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"myapp/database"
+	"myapp/models"
+	"myapp/textdiff"
+)
+
+var revisionColumns = []string{"id", "post_id", "revision", "title", "content", "editor_id", "restored_from", "created_at"}
+
+func expectLoadRevision(mock sqlmock.Sqlmock, number uint, title, content string) {
+	mock.ExpectQuery("SELECT \\* FROM `post_revisions` WHERE post_id = \\? AND revision = \\?").
+		WithArgs(1, number, 1).
+		WillReturnRows(sqlmock.NewRows(revisionColumns).
+			AddRow(number, 1, number, title, content, 7, nil, time.Now()))
+}
+
+func TestUpdatePost_BackfillsOriginalRevision(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadPost(mock, 7)
+
+	mock.ExpectBegin()
+	expectUniqueSlug(mock, "new-title", 1)
+	mock.ExpectExec("UPDATE `posts` SET").WillReturnResult(sqlmock.NewResult(0, 1))
+	expectSlugHistory(mock, "new-title")
+	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(revision\\), 0\\) FROM `post_revisions`").
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(0))
+	// Post cũ chưa có lịch sử: nội dung trước khi sửa thành revision 1
+	mock.ExpectExec("INSERT INTO `post_revisions`").
+		WithArgs(1, 1, "Hello", "World", 7, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO `post_revisions`").
+		WithArgs(1, 2, "New title", "World", 7, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "PUT", "/posts/1", []byte(`{"title":"New title","content":"World"}`))
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	UpdatePost(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdatePost_PrunesOldRevisions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	os.Setenv("POST_REVISION_RETENTION", "3")
+	defer os.Unsetenv("POST_REVISION_RETENTION")
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadPost(mock, 7)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `posts` SET").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(revision\\), 0\\) FROM `post_revisions`").
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(5))
+	mock.ExpectExec("INSERT INTO `post_revisions`").
+		WithArgs(1, 6, "Hello", "Changed", 7, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(6, 1))
+	mock.ExpectExec("DELETE FROM `post_revisions` WHERE post_id = \\? AND revision <= \\?").
+		WithArgs(1, 3).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "PATCH", "/posts/1", []byte(`{"content":"Changed"}`))
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	PatchPost(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDiffPostRevisions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadPost(mock, 7)
+	expectLoadRevision(mock, 1, "Hello", "one\ntwo\n")
+	expectLoadRevision(mock, 3, "Hello world", "one\n2\n")
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "GET", "/posts/1/revisions/diff?from=1&to=3", nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	DiffPostRevisions(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		From    uint            `json:"from"`
+		To      uint            `json:"to"`
+		Title   []textdiff.Edit `json:"title"`
+		Content []textdiff.Edit `json:"content"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, uint(3), response.To)
+	assert.Equal(t, []textdiff.Edit{{Op: "equal", Text: "Hello"}, {Op: "insert", Text: " world"}}, response.Title)
+	assert.Equal(t, []textdiff.Edit{{Op: "equal", Text: "one\n"}, {Op: "delete", Text: "two\n"}, {Op: "insert", Text: "2\n"}}, response.Content)
+}
+
+func TestDiffPostRevisions_ForbiddenForOthers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadPost(mock, 7)
+
+	c, w := newAuthedContext(&models.User{ID: 8}, "GET", "/posts/1/revisions/diff?from=1&to=2", nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	DiffPostRevisions(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestRestorePostRevision_CreatesNewRevision(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadPost(mock, 7)
+	expectLoadRevision(mock, 2, "Old title", "World")
+
+	mock.ExpectBegin()
+	expectUniqueSlug(mock, "old-title", 1)
+	mock.ExpectExec("UPDATE `posts` SET `slug`=\\?,`title`=\\?,`updated_at`=\\? WHERE `id` = \\?").
+		WithArgs("old-title", "Old title", sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectSlugHistory(mock, "old-title")
+	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(revision\\), 0\\) FROM `post_revisions`").
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(4))
+	mock.ExpectExec("INSERT INTO `post_revisions`").
+		WithArgs(1, 5, "Old title", "World", 7, 2, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(5, 1))
+	mock.ExpectCommit()
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "POST", "/posts/1/revisions/2/restore", nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}, {Key: "rev", Value: "2"}}
+	RestorePostRevision(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"title":"Old title"`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"myapp/database"
+	"myapp/middleware"
+	"myapp/search"
+)
+
+// SearchBackend là backend tìm kiếm do main chọn theo SEARCH_BACKEND; nil = MySQL FULLTEXT
+var SearchBackend search.Searcher
+
+// maxSearchLength giới hạn số ký tự của ?q=
+const maxSearchLength = 200
+
+// searchTypes ánh xạ ?type= sang loại tài liệu cần tìm (nil = tất cả)
+var searchTypes = map[string][]string{
+	"":      nil,
+	"all":   nil,
+	"posts": {search.TypePost},
+	"users": {search.TypeUser},
+}
+
+// GET /search?q=&type=all|posts|users — kết quả xếp theo độ liên quan, kèm snippet có <mark>;
+// phân trang page/per_page như /posts. Post chỉ gồm các post user được xem, email chỉ admin tìm được.
+func Search(c *gin.Context) {
+	page, ok := bindPagination(c)
+	if !ok {
+		return
+	}
+
+	text := strings.TrimSpace(c.Query("q"))
+	if text == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+	if utf8.RuneCountInString(text) > maxSearchLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("q must be at most %d characters", maxSearchLength)})
+		return
+	}
+	terms := search.Terms(text)
+	if len(terms) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q must contain at least one letter or digit"})
+		return
+	}
+	types, ok := searchTypes[c.Query("type")]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be all, posts or users"})
+		return
+	}
+
+	query := search.Query{
+		Terms:  terms,
+		Types:  types,
+		Offset: (page.Page - 1) * page.PerPage,
+		Limit:  page.PerPage,
+	}
+	if user, ok := middleware.CurrentUser(c); ok {
+		query.Viewer = search.Viewer{UserID: user.ID, Admin: user.IsAdmin()}
+	}
+
+	result, err := searcher().Search(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	page.setHeaders(c, result.Total)
+	jsonWithETag(c, http.StatusOK, result.Hits)
+}
+
+func searcher() search.Searcher {
+	if SearchBackend != nil {
+		return SearchBackend
+	}
+	return search.NewMySQL(database.DB)
+}
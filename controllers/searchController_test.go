@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"myapp/models"
+	"myapp/search"
+)
+
+// fakeSearcher ghi lại truy vấn và trả về kết quả cố định
+type fakeSearcher struct {
+	query  search.Query
+	result *search.Result
+}
+
+func (f *fakeSearcher) Search(ctx context.Context, q search.Query) (*search.Result, error) {
+	f.query = q
+	return f.result, nil
+}
+
+func useSearcher(t *testing.T, s search.Searcher) {
+	SearchBackend = s
+	t.Cleanup(func() { SearchBackend = nil })
+}
+
+func TestSearch_PassesViewerAndPagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	fake := &fakeSearcher{result: &search.Result{
+		Hits:  []search.Hit{{Type: search.TypePost, ID: 3, Title: "Go", Snippet: "<mark>Go</mark>", Score: 1.5}},
+		Total: 41,
+	}}
+	useSearcher(t, fake)
+	user := &models.User{ID: 7, Role: models.RoleUser}
+
+	c, w := newAuthedContext(user, "GET", "/api/search?q=Go+web&type=posts&page=2&per_page=20", nil)
+	Search(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"type":"post","id":3,"title":"Go","snippet":"<mark>Go</mark>","score":1.5}]`, w.Body.String())
+	assert.Equal(t, "41", w.Header().Get("X-Total-Count"))
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+	assert.Equal(t, search.Query{
+		Terms:  []string{"go", "web"},
+		Types:  []string{search.TypePost},
+		Viewer: search.Viewer{UserID: 7},
+		Offset: 20,
+		Limit:  20,
+	}, fake.query)
+}
+
+func TestSearch_AdminViewer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	fake := &fakeSearcher{result: &search.Result{Hits: []search.Hit{}}}
+	useSearcher(t, fake)
+	admin := &models.User{ID: 1, Role: models.RoleAdmin}
+
+	c, w := newAuthedContext(admin, "GET", "/api/search?q=john", nil)
+	Search(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "[]", w.Body.String())
+	assert.Nil(t, fake.query.Types)
+	assert.Equal(t, search.Viewer{UserID: 1, Admin: true}, fake.query.Viewer)
+}
+
+func TestSearch_InvalidParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	useSearcher(t, &fakeSearcher{})
+	user := &models.User{ID: 7, Role: models.RoleUser}
+
+	cases := map[string]string{
+		"/api/search":                 "q is required",
+		"/api/search?q=%2B-*":         "q must contain at least one letter or digit",
+		"/api/search?q=go&type=tags":  "type must be all, posts or users",
+		"/api/search?q=go&per_page=0": "per_page must be between 1 and 100",
+	}
+	for target, message := range cases {
+		c, w := newAuthedContext(user, "GET", target, nil)
+		Search(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, target)
+		assert.Contains(t, w.Body.String(), message, target)
+	}
+}
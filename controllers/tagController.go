@@ -0,0 +1,232 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"myapp/database"
+	"myapp/models"
+	"myapp/services"
+)
+
+// Giới hạn tag của một post
+const (
+	maxTagsPerPost = 20
+	maxTagLength   = 50
+)
+
+// GET /tags — tag phổ biến nhất (?limit=, mặc định 20), usage_count là số post user hiện tại xem được
+func GetPopularTags(c *gin.Context) {
+	limit, ok := bindLimit(c, 20)
+	if !ok {
+		return
+	}
+
+	var tags []models.Tag
+	if err := visibleTags(c).Order("usage_count DESC, tags.slug").Limit(limit).Find(&tags).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	jsonWithETag(c, http.StatusOK, tags)
+}
+
+// GET /tags/autocomplete?q= — gợi ý tag theo tiền tố, tag dùng nhiều xếp trước
+func AutocompleteTags(c *gin.Context) {
+	limit, ok := bindLimit(c, 10)
+	if !ok {
+		return
+	}
+	prefix := services.Slugify(c.Query("q"))
+	if prefix == "" {
+		c.JSON(http.StatusOK, []models.Tag{})
+		return
+	}
+
+	var tags []models.Tag
+	if err := visibleTags(c).Where("tags.slug LIKE ?", escapeLike(prefix)+"%").
+		Order("usage_count DESC, tags.slug").Limit(limit).Find(&tags).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tags)
+}
+
+// visibleTags trả về query các tag đang gắn với ít nhất một post user hiện tại xem được,
+// usage_count đếm theo các post đó. Cột tags.usage_count gồm cả post chưa xuất bản nên
+// không dùng ở đây để không lộ tag chỉ có trên draft/post bị ẩn.
+func visibleTags(c *gin.Context) *gorm.DB {
+	query := database.DB.Model(&models.Tag{}).
+		Select("tags.id, tags.name, tags.slug, tags.created_at, COUNT(*) AS usage_count").
+		Joins("JOIN post_tags ON post_tags.tag_id = tags.id").
+		Joins("JOIN posts ON posts.id = post_tags.post_id").
+		Group("tags.id")
+	if cond, args := postVisibility(c, "posts"); cond != "" {
+		query = query.Where(cond, args...)
+	}
+	return query
+}
+
+// GET /posts/:id/tags
+func GetPostTags(c *gin.Context) {
+	post, ok := loadPost(c)
+	if !ok {
+		return
+	}
+	tags, err := postTags(database.DB, post.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tags)
+}
+
+// PUT /posts/:id/tags — thay toàn bộ tag của post (chỉ tác giả hoặc admin)
+func SetPostTags(c *gin.Context) {
+	post, ok := loadPost(c)
+	if !ok || !canModifyPost(c, post) {
+		return
+	}
+
+	var body struct {
+		Tags []string `json:"tags" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	names, err := normalizeTags(body.Tags)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var tags []models.Tag
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Khóa post để các lần sửa tag song song không làm lệch usage_count
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.Post{}, post.ID).Error; err != nil {
+			return err
+		}
+
+		var current []uint
+		if err := tx.Model(&models.PostTag{}).Where("post_id = ?", post.ID).Pluck("tag_id", &current).Error; err != nil {
+			return err
+		}
+
+		wanted := make([]uint, 0, len(names))
+		for _, tag := range names {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tag).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("slug = ?", tag.Slug).First(&tag).Error; err != nil {
+				return err
+			}
+			wanted = append(wanted, tag.ID)
+		}
+
+		removed, added := diffIDs(current, wanted), diffIDs(wanted, current)
+		if len(removed) > 0 {
+			if err := tx.Where("post_id = ? AND tag_id IN ?", post.ID, removed).Delete(&models.PostTag{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("UPDATE tags SET usage_count = usage_count - 1 WHERE id IN ? AND usage_count > 0", removed).Error; err != nil {
+				return err
+			}
+		}
+		if len(added) > 0 {
+			links := make([]models.PostTag, len(added))
+			for i, id := range added {
+				links[i] = models.PostTag{PostID: post.ID, TagID: id}
+			}
+			if err := tx.Create(&links).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("UPDATE tags SET usage_count = usage_count + 1 WHERE id IN ?", added).Error; err != nil {
+				return err
+			}
+		}
+
+		tags, err = postTags(tx, post.ID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tags)
+}
+
+// postTags trả về các tag của post, sắp theo slug
+func postTags(db *gorm.DB, postID uint) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	err := db.Joins("JOIN post_tags ON post_tags.tag_id = tags.id").
+		Where("post_tags.post_id = ?", postID).Order("tags.slug").Find(&tags).Error
+	return tags, err
+}
+
+// normalizeTags chuẩn hóa tên tag thành slug, bỏ trùng, giữ thứ tự gửi lên
+func normalizeTags(raw []string) ([]models.Tag, error) {
+	var tags []models.Tag
+	seen := map[string]bool{}
+	for _, name := range raw {
+		name = strings.TrimSpace(name)
+		if utf8.RuneCountInString(name) > maxTagLength {
+			return nil, fmt.Errorf("tag %q is longer than %d characters", name, maxTagLength)
+		}
+		slug := services.Slugify(name)
+		if slug == "" {
+			return nil, fmt.Errorf("tag %q is empty after normalization", name)
+		}
+		if !seen[slug] {
+			seen[slug] = true
+			tags = append(tags, models.Tag{Name: name, Slug: slug})
+		}
+	}
+	if len(tags) > maxTagsPerPost {
+		return nil, fmt.Errorf("a post can have at most %d tags", maxTagsPerPost)
+	}
+	return tags, nil
+}
+
+// diffIDs trả về các id (không trùng, đã sắp xếp) có trong a mà không có trong b
+func diffIDs(a, b []uint) []uint {
+	seen := make(map[uint]bool, len(b))
+	for _, id := range b {
+		seen[id] = true
+	}
+	var out []uint
+	for _, id := range a {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// uniqueIDs bỏ id trùng và sắp xếp tăng dần
+func uniqueIDs(ids []uint) []uint {
+	return diffIDs(ids, nil)
+}
+
+// bindLimit đọc ?limit= (1..100), ghi response 400 nếu không hợp lệ
+func bindLimit(c *gin.Context, fallback int) (int, bool) {
+	raw := c.Query("limit")
+	if raw == "" {
+		return fallback, true
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 || limit > maxPerPage {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", maxPerPage)})
+		return 0, false
+	}
+	return limit, true
+}
@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"myapp/database"
+	"myapp/models"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := normalizeTags([]string{"Go", " go ", "Web Dev", "web-dev", "API"})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Tag{
+		{Name: "Go", Slug: "go"},
+		{Name: "Web Dev", Slug: "web-dev"},
+		{Name: "API", Slug: "api"},
+	}, tags)
+
+	_, err = normalizeTags([]string{"!!!"})
+	assert.Error(t, err)
+	_, err = normalizeTags([]string{strings.Repeat("a", maxTagLength+1)})
+	assert.Error(t, err)
+}
+
+func TestSetPostTags_MaintainsUsageCounts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadPost(mock, 7)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT `id` FROM `posts` WHERE `posts`.`id` = \\? .* FOR UPDATE").
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	// Post đang có tag 1 (go) và 2 (old)
+	mock.ExpectQuery("SELECT `tag_id` FROM `post_tags` WHERE post_id = \\?").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"tag_id"}).AddRow(1).AddRow(2))
+	mock.ExpectExec("INSERT INTO `tags` .* ON DUPLICATE KEY UPDATE `id`=`id`").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT \\* FROM `tags` WHERE slug = \\?").
+		WithArgs("go", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug", "usage_count"}).AddRow(1, "Go", "go", 4))
+	mock.ExpectExec("INSERT INTO `tags` .* ON DUPLICATE KEY UPDATE `id`=`id`").
+		WillReturnResult(sqlmock.NewResult(3, 1))
+	mock.ExpectQuery("SELECT \\* FROM `tags` WHERE slug = \\?").
+		WithArgs("web-dev", 3, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug", "usage_count"}).AddRow(3, "Web Dev", "web-dev", 0))
+	mock.ExpectExec("DELETE FROM `post_tags` WHERE post_id = \\? AND tag_id IN \\(\\?\\)").
+		WithArgs(1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE tags SET usage_count = usage_count - 1 WHERE id IN \\(\\?\\) AND usage_count > 0").
+		WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO `post_tags`").
+		WithArgs(1, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE tags SET usage_count = usage_count \\+ 1 WHERE id IN \\(\\?\\)").
+		WithArgs(3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT `tags`.`id`.* FROM `tags` JOIN post_tags ON post_tags.tag_id = tags.id WHERE post_tags.post_id = \\? ORDER BY tags.slug").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug", "usage_count"}).
+			AddRow(1, "Go", "go", 4).
+			AddRow(3, "Web Dev", "web-dev", 1))
+	mock.ExpectCommit()
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "PUT", "/posts/1/tags", []byte(`{"tags":["Go","Web Dev"]}`))
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	SetPostTags(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []models.Tag
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response, 2)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetPostTags_Forbidden(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadPost(mock, 7)
+
+	c, w := newAuthedContext(&models.User{ID: 8}, "PUT", "/posts/1/tags", []byte(`{"tags":["go"]}`))
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	SetPostTags(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestGetPopularTags_CountsVisiblePostsOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT tags.id, .* COUNT\\(\\*\\) AS usage_count FROM `tags` .* WHERE \\(`posts`.status = \\? OR `posts`.author_id = \\?\\) GROUP BY `tags`.`id` ORDER BY usage_count DESC, tags.slug LIMIT \\?").
+		WithArgs(models.PostPublished, 7, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug", "usage_count"}).AddRow(3, "Go", "go", 2))
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "GET", "/tags", nil)
+	GetPopularTags(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"usage_count":2`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAutocompleteTags(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	// Chỉ đếm post khách xem được, tag chỉ có trên draft không được gợi ý
+	mock.ExpectQuery("SELECT tags.id, tags.name, tags.slug, tags.created_at, COUNT\\(\\*\\) AS usage_count FROM `tags` JOIN post_tags ON post_tags.tag_id = tags.id JOIN posts ON posts.id = post_tags.post_id WHERE `posts`.status = \\? AND tags.slug LIKE \\? GROUP BY `tags`.`id` ORDER BY usage_count DESC, tags.slug LIMIT \\?").
+		WithArgs(models.PostPublished, "web%", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug", "usage_count"}).AddRow(3, "Web Dev", "web-dev", 5))
+
+	c, w := newListRequest("/tags/autocomplete?q=Web+")
+	AutocompleteTags(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "web-dev")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPosts_FilterByAllTags(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `posts` WHERE posts.id IN \\(SELECT post_tags.post_id FROM `post_tags` JOIN tags ON tags.id = post_tags.tag_id WHERE tags.slug IN \\(\\?,\\?\\) GROUP BY `post_tags`.`post_id` HAVING COUNT\\(DISTINCT post_tags.tag_id\\) = \\?\\)").
+		WithArgs("go", "web-dev", 2, models.PostPublished).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT \\* FROM `posts` WHERE posts.id IN").
+		WillReturnRows(sqlmock.NewRows(postColumns).AddRow(1, "Hello", "", 7, time.Now(), time.Now()))
+	expectReactionSummaries(mock, false)
+
+	c, w := newListRequest("/posts?tag=go&tag=Web+Dev")
+	GetPosts(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPosts_FilterByAnyTagAndCategory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `posts` WHERE posts.id IN \\(SELECT post_tags.post_id FROM `post_tags` JOIN tags ON tags.id = post_tags.tag_id WHERE tags.slug IN \\(\\?,\\?\\)\\) AND posts.id IN \\(SELECT post_id FROM post_categories WHERE category_id IN \\(\\s+WITH RECURSIVE tree").
+		WithArgs("go", "rust", "backend", models.PostPublished).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery("SELECT \\* FROM `posts`").
+		WillReturnRows(sqlmock.NewRows(postColumns))
+
+	c, w := newListRequest("/posts?tag=go&tag=rust&tag_mode=any&category=backend")
+	GetPosts(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPosts_InvalidTagMode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	c, w := newListRequest("/posts?tag=go&tag_mode=xor")
+	GetPosts(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"myapp/database"
+	"myapp/middleware"
+	"myapp/models"
+	"myapp/services"
+)
+
+// HomeTimeline là cách tính home timeline; nil = fan-out-on-read trên database.DB
+var HomeTimeline services.Timeline
+
+// GET /timeline — post đã xuất bản của các tác giả user đang theo dõi, mới nhất trước.
+// Phân trang bằng cursor (keyset): ?limit= và ?cursor= lấy từ header Link rel="next".
+// Hỗ trợ ?fields= và ?format= như /posts.
+func GetTimeline(c *gin.Context) {
+	sel, ok := bindFieldSelection(c, postFields)
+	if !ok {
+		return
+	}
+	format, ok := bindContentFormat(c, sel)
+	if !ok {
+		return
+	}
+	limit, ok := bindLimit(c, defaultPerPage)
+	if !ok {
+		return
+	}
+	var after *services.TimelineCursor
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, err := services.DecodeTimelineCursor(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return
+		}
+		after = cursor
+	}
+
+	user, _ := middleware.CurrentUser(c)
+	page, err := timeline().Home(user.ID, after, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	posts, err := loadTimelinePosts(c, sel, page.PostIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	items, err := renderPosts(c, sel, format, posts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if page.Next != nil {
+		u := *c.Request.URL
+		q := u.Query()
+		q.Set("cursor", page.Next.Encode())
+		q.Set("limit", strconv.Itoa(limit))
+		u.RawQuery = q.Encode()
+		c.Header("Link", fmt.Sprintf(`<%s>; rel="next"`, u.RequestURI()))
+	}
+	jsonWithETag(c, http.StatusOK, items)
+}
+
+// loadTimelinePosts load các post theo đúng thứ tự ids. Post vừa bị ẩn (không còn xem được)
+// sau khi timeline được tính thì bị bỏ qua.
+func loadTimelinePosts(c *gin.Context, sel *fieldSelection, ids []uint) ([]models.Post, error) {
+	if len(ids) == 0 {
+		return []models.Post{}, nil
+	}
+	query := sel.apply(database.DB).Where("posts.id IN ?", ids)
+	if cond, args := postVisibility(c, "posts"); cond != "" {
+		query = query.Where(cond, args...)
+	}
+	var found []models.Post
+	if err := query.Find(&found).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]models.Post, len(found))
+	for _, post := range found {
+		byID[post.ID] = post
+	}
+	posts := make([]models.Post, 0, len(found))
+	for _, id := range ids {
+		if post, ok := byID[id]; ok {
+			posts = append(posts, post)
+		}
+	}
+	return posts, nil
+}
+
+func timeline() services.Timeline {
+	if HomeTimeline != nil {
+		return HomeTimeline
+	}
+	return services.NewFanoutOnRead(database.DB)
+}
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"myapp/database"
+	"myapp/models"
+	"myapp/services"
+)
+
+// fakeTimeline trả về trang cố định và ghi lại tham số
+type fakeTimeline struct {
+	userID uint
+	after  *services.TimelineCursor
+	limit  int
+	page   *services.TimelinePage
+}
+
+func (f *fakeTimeline) Home(userID uint, after *services.TimelineCursor, limit int) (*services.TimelinePage, error) {
+	f.userID, f.after, f.limit = userID, after, limit
+	return f.page, nil
+}
+
+func useTimeline(t *testing.T, timeline services.Timeline) {
+	HomeTimeline = timeline
+	t.Cleanup(func() { HomeTimeline = nil })
+}
+
+func TestGetTimeline_KeepsTimelineOrder(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	next := &services.TimelineCursor{PublishedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), PostID: 3}
+	fake := &fakeTimeline{page: &services.TimelinePage{PostIDs: []uint{5, 3}, Next: next}}
+	useTimeline(t, fake)
+
+	mock.ExpectQuery("SELECT `id`,`title`,`author_id`,`status` FROM `posts` WHERE posts.id IN \\(\\?,\\?\\) AND \\(\\(`posts`.status = \\? OR `posts`.author_id = \\?\\)\\)").
+		WithArgs(5, 3, models.PostPublished, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "author_id", "status"}).
+			AddRow(3, "Older", 9, models.PostPublished).
+			AddRow(5, "Newer", 9, models.PostPublished))
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "GET", "/api/timeline?fields=id,title&limit=2", nil)
+	GetTimeline(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"id":5,"title":"Newer"},{"id":3,"title":"Older"}]`, w.Body.String())
+	assert.Equal(t, uint(7), fake.userID)
+	assert.Equal(t, 2, fake.limit)
+	assert.Nil(t, fake.after)
+
+	link := w.Header().Get("Link")
+	assert.True(t, strings.HasSuffix(link, `>; rel="next"`))
+	u, err := url.Parse(strings.TrimSuffix(strings.TrimPrefix(link, "<"), `>; rel="next"`))
+	assert.NoError(t, err)
+	assert.Equal(t, next.Encode(), u.Query().Get("cursor"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTimeline_PassesCursor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cursor := services.TimelineCursor{PublishedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), PostID: 3}
+	fake := &fakeTimeline{page: &services.TimelinePage{PostIDs: []uint{}}}
+	useTimeline(t, fake)
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "GET", "/api/timeline?cursor="+cursor.Encode(), nil)
+	GetTimeline(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "[]", w.Body.String())
+	assert.Empty(t, w.Header().Get("Link"))
+	assert.Equal(t, defaultPerPage, fake.limit)
+	assert.Equal(t, cursor.PostID, fake.after.PostID)
+	assert.True(t, cursor.PublishedAt.Equal(fake.after.PublishedAt))
+}
+
+func TestGetTimeline_InvalidCursor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	useTimeline(t, &fakeTimeline{})
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "GET", "/api/timeline?cursor=%25%25", nil)
+	GetTimeline(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid cursor")
+}
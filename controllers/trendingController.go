@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"myapp/database"
+	"myapp/middleware"
+	"myapp/models"
+	"myapp/services"
+)
+
+// Views đếm lượt xem post; nil = không đếm
+var Views *services.ViewAggregator
+
+// TrendingHalfLife là thời gian để một lượt xem/reaction mất nửa giá trị khi xếp hạng trending
+var TrendingHalfLife = 6 * time.Hour
+
+// Khoảng thời gian cho phép của ?window= trên /posts/trending
+const (
+	defaultTrendingWindow = 24 * time.Hour
+	maxTrendingWindow     = 30 * 24 * time.Hour
+)
+
+// GET /posts/trending — post đã xuất bản được xem và react nhiều gần đây, điểm cao trước.
+// ?window= (mặc định 24h, tối đa 720h) giới hạn hoạt động được tính, ?limit= như /tags.
+// Hỗ trợ ?fields= và ?format= như /posts; mỗi post có thêm "trending_score".
+func GetTrendingPosts(c *gin.Context) {
+	sel, ok := bindFieldSelection(c, postFields)
+	if !ok {
+		return
+	}
+	format, ok := bindContentFormat(c, sel)
+	if !ok {
+		return
+	}
+	limit, ok := bindLimit(c, defaultPerPage)
+	if !ok {
+		return
+	}
+	window := defaultTrendingWindow
+	if raw := c.Query("window"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil || parsed < time.Hour || parsed > maxTrendingWindow {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "window must be a duration between 1h and 720h"})
+			return
+		}
+		window = parsed
+	}
+
+	now := time.Now()
+	ranked, err := services.TrendingPosts(database.DB, now, now.Add(-window), TrendingHalfLife, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ids := make([]uint, len(ranked))
+	scores := make(map[uint]float64, len(ranked))
+	for i, post := range ranked {
+		ids[i] = post.PostID
+		scores[post.PostID] = post.Score
+	}
+
+	posts, err := loadTimelinePosts(c, sel, ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	items, err := renderPosts(c, sel, format, posts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for i, item := range items {
+		item["trending_score"] = scores[posts[i].ID]
+	}
+	jsonWithETag(c, http.StatusOK, items)
+}
+
+// recordView đếm một lượt xem post đã xuất bản; tác giả xem bài của mình không được tính
+func recordView(c *gin.Context, post *models.Post) {
+	if Views == nil || post.Status != models.PostPublished {
+		return
+	}
+	viewer := "ip:" + c.ClientIP()
+	if user, ok := middleware.CurrentUser(c); ok {
+		if user.ID == post.AuthorID {
+			return
+		}
+		viewer = fmt.Sprintf("user:%d", user.ID)
+	}
+	Views.Record(post.ID, viewer, time.Now())
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"myapp/database"
+	"myapp/models"
+	"myapp/services"
+)
+
+func TestGetTrendingPosts_InvalidWindow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	c, w := newAuthedContext(&models.User{ID: 8}, "GET", "/posts/trending?window=10m", nil)
+	GetTrendingPosts(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGetTrendingPosts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT activity.post_id, SUM\\(activity.score\\) AS score").
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "score"}).AddRow(1, 7.5))
+	mock.ExpectQuery("SELECT `id`,`title`,`author_id`,`status` FROM `posts` WHERE posts.id IN \\(\\?\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "author_id", "status"}).AddRow(1, "Hello", 7, models.PostPublished))
+
+	c, w := newAuthedContext(&models.User{ID: 8}, "GET", "/posts/trending?fields=id,title", nil)
+	GetTrendingPosts(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"id":1,"title":"Hello","trending_score":7.5}]`, w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPost_RecordsView(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	Views = services.NewViewAggregator(nil, time.Hour)
+	defer func() { Views = nil }()
+	expectLoadPost(mock, 7)
+	expectReactionSummaries(mock, true)
+	expectBookmarks(mock)
+
+	c, w := newAuthedContext(&models.User{ID: 8}, "GET", "/posts/1", nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	GetPost(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	// Đã được tính, lượt xem lặp lại bị bỏ
+	assert.False(t, Views.Record(1, "user:8", time.Now()))
+	assert.True(t, Views.Record(1, "user:9", time.Now()))
+}
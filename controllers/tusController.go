@@ -0,0 +1,365 @@
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"myapp/database"
+	"myapp/middleware"
+	"myapp/models"
+	"myapp/services"
+)
+
+// UploadExpiration là thời gian giữ upload resumable kể từ lần nhận dữ liệu cuối
+var UploadExpiration = 24 * time.Hour
+
+// Content-Type bắt buộc của request PATCH theo tus
+const tusChunkContentType = "application/offset+octet-stream"
+
+// maxUploadMetadataLength giới hạn độ dài header Upload-Metadata được lưu lại
+const maxUploadMetadataLength = 4096
+
+// OPTIONS /uploads — khả năng của server theo tus (không cần đăng nhập)
+func TusOptions(c *gin.Context) {
+	c.Header("Tus-Version", middleware.TusVersion)
+	c.Header("Tus-Extension", "creation,termination,expiration")
+	c.Header("Tus-Max-Size", strconv.FormatInt(uploadLimit("ATTACHMENT_MAX_SIZE", 25<<20), 10))
+	c.Status(http.StatusNoContent)
+}
+
+// POST /uploads — tạo upload resumable (tus creation). Upload-Length là dung lượng file
+// (tối đa ATTACHMENT_MAX_SIZE), Upload-Metadata phải có post_id (post mình được sửa)
+// và có thể có filename. Khi nhận đủ dữ liệu, upload thành attachment của post.
+func CreateUpload(c *gin.Context) {
+	if !requireFiles(c) {
+		return
+	}
+	user, _ := middleware.CurrentUser(c)
+
+	if c.GetHeader("Upload-Defer-Length") != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Upload-Defer-Length is not supported"})
+		return
+	}
+	length, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || length <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Upload-Length must be a positive integer"})
+		return
+	}
+	if limit := uploadLimit("ATTACHMENT_MAX_SIZE", 25<<20); length > limit {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Upload-Length must be at most " + strconv.FormatInt(limit, 10)})
+		return
+	}
+
+	rawMetadata := c.GetHeader("Upload-Metadata")
+	metadata, err := parseUploadMetadata(rawMetadata)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	postID, err := strconv.ParseUint(metadata["post_id"], 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Upload-Metadata must include post_id"})
+		return
+	}
+	post, err := firstVisiblePost(c, database.DB.Select("id", "author_id", "status").Where("`posts`.`id` = ?", postID))
+	if err != nil {
+		writePostError(c, err)
+		return
+	}
+	if !canModifyPost(c, post) {
+		return
+	}
+
+	upload := models.Upload{
+		ID:           services.NewUploadID(),
+		OwnerID:      user.ID,
+		PostID:       post.ID,
+		UploadLength: length,
+		ExpiresAt:    time.Now().Add(UploadExpiration),
+	}
+	// Tên file trống thì đặt theo định dạng khi hoàn tất
+	if name := metadata["filename"]; name != "" {
+		upload.Filename = attachmentFilename(name, "")
+	}
+	if rawMetadata != "" {
+		upload.Metadata = &rawMetadata
+	}
+	if err := database.DB.Create(&upload).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Location", "/api/uploads/"+upload.ID)
+	setUploadExpires(c, &upload)
+	c.Status(http.StatusCreated)
+}
+
+// HEAD /uploads/:id — offset hiện tại để client tiếp tục upload
+func HeadUpload(c *gin.Context) {
+	upload, ok := loadUpload(c)
+	if !ok {
+		return
+	}
+	c.Header("Upload-Offset", strconv.FormatInt(upload.UploadOffset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(upload.UploadLength, 10))
+	if upload.Metadata != nil {
+		c.Header("Upload-Metadata", *upload.Metadata)
+	}
+	c.Header("Cache-Control", "no-store")
+	setUploadExpires(c, upload)
+	c.Status(http.StatusOK)
+}
+
+// GET /uploads/:id — trạng thái upload dạng JSON, kèm attachment khi đã hoàn tất
+func GetUpload(c *gin.Context) {
+	if !requireFiles(c) {
+		return
+	}
+	upload, ok := loadUpload(c)
+	if !ok {
+		return
+	}
+	view := models.UploadView{Upload: *upload}
+	if upload.AttachmentID != nil {
+		var attachment models.Attachment
+		if err := database.DB.First(&attachment, *upload.AttachmentID).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		attachmentView, err := attachmentView(c, &attachment)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		view.Attachment = attachmentView
+	}
+	c.JSON(http.StatusOK, view)
+}
+
+// PATCH /uploads/:id — ghi tiếp dữ liệu từ Upload-Offset (phải khớp offset hiện tại, sai trả 409).
+// Nhận đủ dữ liệu thì upload thành attachment của post; PATCH rỗng ở offset cuối thử lại
+// bước này nếu lần trước lỗi.
+func PatchUpload(c *gin.Context) {
+	if !requireFiles(c) {
+		return
+	}
+	if c.ContentType() != tusChunkContentType {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Content-Type must be " + tusChunkContentType})
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Upload-Offset must be a non-negative integer"})
+		return
+	}
+	upload, ok := loadUpload(c)
+	if !ok {
+		return
+	}
+	if offset != upload.UploadOffset {
+		c.Header("Upload-Offset", strconv.FormatInt(upload.UploadOffset, 10))
+		c.JSON(http.StatusConflict, gin.H{"error": "Upload-Offset does not match the current offset"})
+		return
+	}
+	remaining := upload.UploadLength - upload.UploadOffset
+	if c.Request.ContentLength > remaining {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body exceeds Upload-Length"})
+		return
+	}
+
+	if remaining > 0 && !appendUploadChunk(c, upload, remaining) {
+		return
+	}
+	if upload.Complete() && upload.AttachmentID == nil && !finalizeUpload(c, upload) {
+		return
+	}
+
+	c.Header("Upload-Offset", strconv.FormatInt(upload.UploadOffset, 10))
+	setUploadExpires(c, upload)
+	c.Status(http.StatusNoContent)
+}
+
+// DELETE /uploads/:id — hủy upload (tus termination), xóa dữ liệu đã nhận.
+// Attachment đã tạo từ upload không bị xóa.
+func TerminateUpload(c *gin.Context) {
+	if !requireFiles(c) {
+		return
+	}
+	upload, ok := loadUpload(c)
+	if !ok {
+		return
+	}
+	if err := services.DeleteUpload(c.Request.Context(), database.DB, Files, upload.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// POST /uploads/:id — cho client không gửi được PATCH/DELETE, dùng header X-HTTP-Method-Override
+func OverrideUploadMethod(c *gin.Context) {
+	switch strings.ToUpper(c.GetHeader("X-HTTP-Method-Override")) {
+	case http.MethodPatch:
+		PatchUpload(c)
+	case http.MethodDelete:
+		TerminateUpload(c)
+	default:
+		c.JSON(http.StatusMethodNotAllowed, gin.H{"error": "Use PATCH or DELETE"})
+	}
+}
+
+// loadUpload tìm upload :id của user hiện tại, tự ghi response 404/410/500 nếu lỗi.
+// Upload của người khác trả 404 như không tồn tại.
+func loadUpload(c *gin.Context) (*models.Upload, bool) {
+	user, _ := middleware.CurrentUser(c)
+	var upload models.Upload
+	if err := database.DB.Where("id = ? AND owner_id = ?", c.Param("id"), user.ID).First(&upload).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Upload not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return nil, false
+	}
+	if !upload.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusGone, gin.H{"error": "Upload has expired"})
+		return nil, false
+	}
+	return &upload, true
+}
+
+// appendUploadChunk lưu body của request thành đoạn tiếp theo (không quá remaining byte),
+// tự ghi response lỗi. Body được ghi ra file tạm trước: mạng chập chờn làm request đứt
+// giữa chừng thì phần đã nhận vẫn được lưu để client tiếp tục từ offset mới.
+func appendUploadChunk(c *gin.Context, upload *models.Upload, remaining int64) bool {
+	tmp, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	n, readErr := io.Copy(tmp, io.LimitReader(c.Request.Body, remaining+1))
+	if n > remaining {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body exceeds Upload-Length"})
+		return false
+	}
+	if n > 0 {
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return false
+		}
+		// Client ngắt kết nối thì context của request bị hủy, phần đã nhận vẫn phải được lưu
+		ctx := context.WithoutCancel(c.Request.Context())
+		err := services.AppendUploadPart(ctx, database.DB, Files, upload, tmp, n, time.Now().Add(UploadExpiration))
+		if errors.Is(err, services.ErrUploadConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Upload-Offset does not match the current offset"})
+			return false
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return false
+		}
+	}
+	if readErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Upload interrupted: " + readErr.Error()})
+		return false
+	}
+	return true
+}
+
+// finalizeUpload biến upload đã nhận đủ dữ liệu thành attachment của post, tự ghi response lỗi.
+// Định dạng không được phép thì upload bị xóa và trả 415.
+func finalizeUpload(c *gin.Context, upload *models.Upload) bool {
+	ctx := c.Request.Context()
+	reader, err := services.OpenUpload(ctx, database.DB, Files, upload.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	defer reader.Close()
+
+	prepared, err := services.PrepareUpload(reader, upload.UploadLength, services.UploadAttachment)
+	if errors.Is(err, services.ErrUnsupportedUpload) {
+		if err := services.DeleteUpload(ctx, database.DB, Files, upload.ID); err != nil {
+			log.Printf("❌ Xóa upload %s thất bại: %v", upload.ID, err)
+		}
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	attachment, err := saveAttachment(c, upload.PostID, upload.OwnerID, upload.Filename, prepared)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+
+	// Hai request cùng hoàn tất một upload: chỉ một attachment được giữ lại
+	result := database.DB.Model(&models.Upload{}).
+		Where("id = ? AND attachment_id IS NULL", upload.ID).
+		Update("attachment_id", attachment.ID)
+	if result.Error != nil || result.RowsAffected == 0 {
+		if err := database.DB.Delete(attachment).Error; err == nil {
+			services.DeleteFiles(ctx, Files, attachment.Keys()...)
+		}
+		if result.Error != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		} else {
+			c.JSON(http.StatusConflict, gin.H{"error": "Upload has already been completed"})
+		}
+		return false
+	}
+	upload.AttachmentID = &attachment.ID
+
+	// Dữ liệu đã nằm trong attachment; xóa lỗi thì các đoạn được dọn khi upload hết hạn
+	if err := services.DeleteUploadParts(ctx, database.DB, Files, upload.ID); err != nil {
+		log.Printf("❌ Xóa dữ liệu của upload %s thất bại: %v", upload.ID, err)
+	}
+	return true
+}
+
+// parseUploadMetadata đọc header Upload-Metadata: các cặp "key base64(value)" cách nhau bởi dấu phẩy
+func parseUploadMetadata(header string) (map[string]string, error) {
+	metadata := map[string]string{}
+	if header == "" {
+		return metadata, nil
+	}
+	if len(header) > maxUploadMetadataLength {
+		return nil, errors.New("Upload-Metadata is too long")
+	}
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" || strings.ContainsAny(encoded, " ") {
+			return nil, errors.New("invalid Upload-Metadata")
+		}
+		if _, exists := metadata[key]; exists {
+			return nil, errors.New("duplicate Upload-Metadata key: " + key)
+		}
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, errors.New("invalid Upload-Metadata value for " + key)
+		}
+		metadata[key] = string(value)
+	}
+	return metadata, nil
+}
+
+// setUploadExpires gắn header Upload-Expires (tus expiration)
+func setUploadExpires(c *gin.Context, upload *models.Upload) {
+	c.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+}
@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"myapp/database"
+	"myapp/models"
+)
+
+var uploadColumns = []string{"id", "owner_id", "post_id", "filename", "upload_length", "upload_offset", "attachment_id", "expires_at"}
+
+// expectLoadUpload khớp truy vấn upload "abc" của user 7
+func expectLoadUpload(mock sqlmock.Sqlmock, length, offset int64) {
+	mock.ExpectQuery("SELECT \\* FROM `uploads` WHERE id = \\? AND owner_id = \\?").
+		WithArgs("abc", 7, 1).
+		WillReturnRows(sqlmock.NewRows(uploadColumns).
+			AddRow("abc", 7, 1, "notes.txt", length, offset, nil, time.Now().Add(time.Hour)))
+}
+
+func newPatchContext(offset string, body string) (*gin.Context, *httptest.ResponseRecorder) {
+	c, w := newAuthedContext(&models.User{ID: 7}, "PATCH", "/api/uploads/abc", nil)
+	c.Request.Body = io.NopCloser(strings.NewReader(body))
+	c.Request.ContentLength = int64(len(body))
+	c.Request.Header.Set("Content-Type", tusChunkContentType)
+	c.Request.Header.Set("Upload-Offset", offset)
+	c.Params = gin.Params{{Key: "id", Value: "abc"}}
+	return c, w
+}
+
+func TestCreateUpload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	useLocalFiles(t)
+
+	mock.ExpectQuery("SELECT `id`,`author_id`,`status` FROM `posts` WHERE `posts`.`id` = \\?").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "author_id", "status"}).AddRow(1, 7, models.PostDraft))
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `uploads`").
+		WithArgs(sqlmock.AnyArg(), 7, 1, "notes.txt", "post_id MQ==,filename bm90ZXMudHh0", 15, 0, nil, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "POST", "/api/uploads", nil)
+	c.Request.Header.Set("Upload-Length", "15")
+	c.Request.Header.Set("Upload-Metadata", "post_id MQ==,filename bm90ZXMudHh0")
+	CreateUpload(c)
+
+	require.Equal(t, http.StatusCreated, c.Writer.Status(), w.Body.String())
+	assert.Regexp(t, "^/api/uploads/[0-9a-f]{32}$", w.Header().Get("Location"))
+	assert.NotEmpty(t, w.Header().Get("Upload-Expires"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateUpload_RequiresPost(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	useLocalFiles(t)
+
+	c, w := newAuthedContext(&models.User{ID: 7}, "POST", "/api/uploads", nil)
+	c.Request.Header.Set("Upload-Length", "15")
+	c.Request.Header.Set("Upload-Metadata", "filename bm90ZXMudHh0")
+	CreateUpload(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestPatchUpload_AppendsChunk(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	useLocalFiles(t)
+
+	expectLoadUpload(mock, 15, 0)
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `uploads` SET .* WHERE id = \\? AND upload_offset = \\?").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO `upload_parts`").
+		WithArgs("abc", 0, sqlmock.AnyArg(), 6).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	c, w := newPatchContext("0", "hello ")
+	PatchUpload(c)
+
+	assert.Equal(t, http.StatusNoContent, c.Writer.Status(), w.Body.String())
+	assert.Equal(t, "6", w.Header().Get("Upload-Offset"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPatchUpload_OffsetMismatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	useLocalFiles(t)
+
+	expectLoadUpload(mock, 15, 6)
+
+	c, w := newPatchContext("0", "hello ")
+	PatchUpload(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "6", w.Header().Get("Upload-Offset"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPatchUpload_FinalizesIntoAttachment(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	store := useLocalFiles(t)
+	ctx := t.Context()
+	require.NoError(t, store.Put(ctx, "uploads/abc/1", strings.NewReader("hello "), 6, ""))
+	require.NoError(t, store.Put(ctx, "uploads/abc/2", strings.NewReader("tus world"), 9, ""))
+
+	// Đã nhận đủ dữ liệu nhưng lần hoàn tất trước bị lỗi, PATCH rỗng thử lại
+	expectLoadUpload(mock, 15, 15)
+	mock.ExpectQuery("SELECT \\* FROM `upload_parts` WHERE upload_id = \\? ORDER BY start_offset").
+		WillReturnRows(sqlmock.NewRows([]string{"upload_id", "start_offset", "storage_key", "size"}).
+			AddRow("abc", 0, "uploads/abc/1", 6).
+			AddRow("abc", 6, "uploads/abc/2", 9))
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `attachments`").
+		WithArgs(1, 7, sqlmock.AnyArg(), nil, "notes.txt", "text/plain", 15, nil, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(9, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `uploads` SET `attachment_id`=\\?,`updated_at`=\\? WHERE id = \\? AND attachment_id IS NULL").
+		WithArgs(9, sqlmock.AnyArg(), "abc").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT `storage_key` FROM `upload_parts` WHERE upload_id = \\?").
+		WillReturnRows(sqlmock.NewRows([]string{"storage_key"}).AddRow("uploads/abc/1").AddRow("uploads/abc/2"))
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `upload_parts` WHERE upload_id = \\?").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	c, w := newPatchContext("15", "")
+	PatchUpload(c)
+
+	require.Equal(t, http.StatusNoContent, c.Writer.Status(), w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+	// Các đoạn đã được xóa, attachment chứa dữ liệu ghép lại
+	_, _, err := store.Open(ctx, "uploads/abc/1")
+	assert.Error(t, err)
+}
+
+func TestHeadUpload_OtherUsersUploadIsHidden(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT \\* FROM `uploads` WHERE id = \\? AND owner_id = \\?").
+		WithArgs("abc", 8, 1).
+		WillReturnRows(sqlmock.NewRows(uploadColumns))
+
+	c, w := newAuthedContext(&models.User{ID: 8}, "HEAD", "/api/uploads/abc", nil)
+	c.Params = gin.Params{{Key: "id", Value: "abc"}}
+	HeadUpload(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestParseUploadMetadata(t *testing.T) {
+	metadata, err := parseUploadMetadata("filename d29ybGRfZG9taW5hdGlvbl9wbGFuLnBkZg==,is_confidential")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"filename": "world_domination_plan.pdf", "is_confidential": ""}, metadata)
+
+	_, err = parseUploadMetadata("filename not-base64!")
+	assert.Error(t, err)
+	_, err = parseUploadMetadata("a YQ==,a Yg==")
+	assert.Error(t, err)
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"myapp/blobstore"
+	"myapp/config"
+	"myapp/services"
+)
+
+// Files là nơi lưu file upload (avatar, attachment); nil = chưa cấu hình, các API upload trả 503
+var Files blobstore.Storage
+
+// fileLinkTTL là thời hạn của link tải đã ký trả về cho client
+const fileLinkTTL = 15 * time.Minute
+
+// multipartOverhead là phần dư cho boundary/header của multipart ngoài dung lượng file
+const multipartOverhead = 1 << 20
+
+// uploadLimit đọc giới hạn dung lượng (byte) từ env, sai thì dùng fallback
+func uploadLimit(key string, fallback int64) int64 {
+	limit, err := strconv.ParseInt(config.GetEnv(key, ""), 10, 64)
+	if err != nil || limit <= 0 {
+		return fallback
+	}
+	return limit
+}
+
+// GET /files/*key — tải file của storage local qua link đã ký (không cần token).
+// Storage S3 trả link thẳng tới S3 nên không đi qua route này.
+func DownloadFile(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if Files == nil || !blobstore.ValidKey(key) ||
+		!services.VerifySignedPath(c.Request.URL.Path, c.Query("expires"), c.Query("signature")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invalid or expired download link"})
+		return
+	}
+
+	reader, obj, err := Files.Open(c.Request.Context(), key)
+	if errors.Is(err, blobstore.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer reader.Close()
+
+	// nosniff để trình duyệt không tự đoán định dạng khác (ví dụ text thành HTML)
+	c.DataFromReader(http.StatusOK, obj.Size, obj.ContentType, reader, map[string]string{
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "private, max-age=" + strconv.Itoa(int(fileLinkTTL/time.Second)),
+	})
+}
+
+// requireFiles ghi 503 nếu chưa cấu hình storage
+func requireFiles(c *gin.Context) bool {
+	if Files == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "File storage is not configured"})
+		return false
+	}
+	return true
+}
+
+// readUpload lấy file ở field "file" của form multipart, không quá limit byte.
+// Tự ghi response 400/413 nếu lỗi.
+func readUpload(c *gin.Context, limit int64) (*multipart.FileHeader, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit+multipartOverhead)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("file must be at most %d bytes", limit)})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "file is required (multipart field \"file\")"})
+		}
+		return nil, false
+	}
+	if header.Size > limit {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("file must be at most %d bytes", limit)})
+		return nil, false
+	}
+	return header, true
+}
+
+// prepareUpload kiểm tra định dạng và xử lý file theo kind, tự ghi response 400/415/500 nếu lỗi
+func prepareUpload(c *gin.Context, header *multipart.FileHeader, kind string) (*services.PreparedUpload, func(), bool) {
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+	upload, err := services.PrepareUpload(file, header.Size, kind)
+	if err != nil {
+		file.Close()
+		if errors.Is(err, services.ErrUnsupportedUpload) {
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return nil, nil, false
+	}
+	return upload, func() { file.Close() }, true
+}
+
+// fileURL trả về link tải đã ký của key, rỗng nếu key nil
+func fileURL(c *gin.Context, key *string) (string, error) {
+	if key == nil {
+		return "", nil
+	}
+	return Files.URL(c.Request.Context(), *key, fileLinkTTL)
+}
@@ -1,11 +1,17 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"myapp/database"
 	"myapp/models"
+	"myapp/services"
 )
 
 // POST /users
@@ -16,20 +22,325 @@ func CreateUser(c *gin.Context) {
 		return
 	}
 
+	// Không cho client tự đặt role khi đăng ký
+	user.Role = models.RoleUser
+
+	// Không chọn handle thì sinh từ tên
+	if user.Handle != "" {
+		handle, ok := checkHandle(c, user.Handle, 0)
+		if !ok {
+			return
+		}
+		user.Handle = handle
+	} else {
+		handle, err := services.UniqueHandle(database.DB, user.Name, 0)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		user.Handle = handle
+	}
+
 	if err := database.DB.Create(&user).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, user)
+	// Trả về profile để không lộ hash của password
+	c.JSON(http.StatusOK, user.Profile())
 }
 
-// GET /users
+// GET /users — hỗ trợ ?fields=id,name và ?include=posts
 func GetUsers(c *gin.Context) {
+	sel, ok := bindFieldSelection(c, userFields)
+	if !ok {
+		return
+	}
+
 	var users []models.User
-	if err := database.DB.Find(&users).Error; err != nil {
+	if err := sel.apply(filterUsers(database.DB, c)).Find(&users).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, users)
+
+	body, err := sel.render(users)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	jsonWithETag(c, http.StatusOK, body)
+}
+
+// GET /users/:id — hỗ trợ ?fields=id,name và ?include=posts
+func GetUser(c *gin.Context) {
+	sel, ok := bindFieldSelection(c, userFields)
+	if !ok {
+		return
+	}
+
+	if !sel.customized() {
+		user, ok := loadUser(c)
+		if !ok {
+			return
+		}
+		if notModified(c, user.ETag()) {
+			return
+		}
+		c.JSON(http.StatusOK, user.Profile())
+		return
+	}
+
+	// Response phụ thuộc vào field/quan hệ được chọn nên ETag tính theo body
+	user, ok := findUser(c, sel.apply(database.DB))
+	if !ok {
+		return
+	}
+	body, err := sel.render(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	jsonWithETag(c, http.StatusOK, body)
+}
+
+// PUT /users/:id — thay thế toàn bộ thông tin, yêu cầu If-Match
+func UpdateUser(c *gin.Context) {
+	user, ok := loadUser(c)
+	if !ok || !requireIfMatch(c, user.ETag()) {
+		return
+	}
+
+	var body userInput
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	saveUser(c, user, changedUserColumns(user, body))
+}
+
+// PATCH /users/:id — yêu cầu If-Match. Hỗ trợ:
+//   - application/json: chỉ cập nhật các field được gửi lên
+//   - application/merge-patch+json (RFC 7396)
+//   - application/json-patch+json (RFC 6902)
+func PatchUser(c *gin.Context) {
+	user, ok := loadUser(c)
+	if !ok || !requireIfMatch(c, user.ETag()) {
+		return
+	}
+
+	if isPatchDocument(c) {
+		var doc userDocument
+		if !applyPatchDocument(c, user.Profile(), userReadOnlyFields, &doc) {
+			return
+		}
+		saveUser(c, user, changedUserColumns(user, doc.userInput))
+		return
+	}
+
+	var body struct {
+		Email *string `json:"email" binding:"omitempty,email,max=255"`
+		Name  *string `json:"name" binding:"omitempty,min=1,max=255"`
+		Role  *string `json:"role" binding:"omitempty,oneof=user admin"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if body.Email != nil {
+		updates["email"] = *body.Email
+	}
+	if body.Name != nil {
+		updates["name"] = *body.Name
+	}
+	if body.Role != nil {
+		updates["role"] = *body.Role
+	}
+	saveUser(c, user, updates)
+}
+
+// userInput là dữ liệu user có thể chỉnh sửa, dùng chung cho PUT và patch
+type userInput struct {
+	Email string `json:"email" binding:"required,email,max=255"`
+	Name  string `json:"name" binding:"required,min=1,max=255"`
+	Role  string `json:"role" binding:"required,oneof=user admin"`
+}
+
+// userDocument là tài liệu user mà merge patch / JSON patch thao tác lên
+type userDocument struct {
+	ID      uint   `json:"id"`
+	Handle  string `json:"handle"`
+	Version uint   `json:"version"`
+	userInput
+}
+
+// Các field không được phép patch (password chỉ đổi qua /users/me/password, handle qua /users/me)
+var userReadOnlyFields = []string{"id", "handle", "version", "password", "token_version"}
+
+// changedUserColumns chỉ trả về các cột thực sự thay đổi
+func changedUserColumns(user *models.User, input userInput) map[string]interface{} {
+	updates := map[string]interface{}{}
+	if input.Email != user.Email {
+		updates["email"] = input.Email
+	}
+	if input.Name != user.Name {
+		updates["name"] = input.Name
+	}
+	if input.Role != user.Role {
+		updates["role"] = input.Role
+	}
+	return updates
+}
+
+// DELETE /users/:id — yêu cầu If-Match
+func DeleteUser(c *gin.Context) {
+	user, ok := loadUser(c)
+	if !ok || !requireIfMatch(c, user.ETag()) {
+		return
+	}
+
+	// Post, attachment và upload bị xóa theo khóa ngoại, file trong storage xóa sau
+	var attachments []models.Attachment
+	var uploadParts []string
+	if Files != nil {
+		posts := database.DB.Model(&models.Post{}).Select("id").Where("author_id = ?", user.ID)
+		if err := database.DB.Where("uploader_id = ? OR post_id IN (?)", user.ID, posts).Find(&attachments).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		var err error
+		uploadParts, err = services.UploadPartKeys(database.DB, "uploads.owner_id = ? OR uploads.post_id IN (?)", user.ID, posts)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := services.DeleteUser(database.DB, user, time.Now()); err != nil {
+		if errors.Is(err, services.ErrUserModified) {
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Resource has been modified, reload and try again"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	if Files != nil {
+		services.DeleteFiles(c.Request.Context(), Files, avatarKeys(user.AvatarKey, user.AvatarThumbKey)...)
+		for i := range attachments {
+			services.DeleteFiles(c.Request.Context(), Files, attachments[i].Keys()...)
+		}
+		services.DeleteFiles(c.Request.Context(), Files, uploadParts...)
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// loadUser tìm user theo :id, tự ghi response 400/404/500 nếu lỗi
+func loadUser(c *gin.Context) (*models.User, bool) {
+	return findUser(c, database.DB)
+}
+
+// findUser giống loadUser nhưng chạy trên query cho trước (đã Select/Preload)
+func findUser(c *gin.Context, query *gorm.DB) (*models.User, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user id"})
+		return nil, false
+	}
+
+	var user models.User
+	if err := query.First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return nil, false
+	}
+	return &user, true
+}
+
+// saveUser cập nhật user với điều kiện version chưa đổi kể từ lúc đọc
+func saveUser(c *gin.Context, user *models.User, updates map[string]interface{}) {
+	if email, ok := updates["email"].(string); ok && email != user.Email {
+		inUse, err := emailInUse(email, user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if inUse {
+			c.JSON(http.StatusConflict, gin.H{"error": "Email is already in use"})
+			return
+		}
+	}
+
+	if len(updates) > 0 {
+		result := database.DB.Model(user).Where("version = ?", user.Version).Updates(updates)
+		if result.Error != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+			return
+		}
+		if result.RowsAffected == 0 {
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Resource has been modified, reload and try again"})
+			return
+		}
+	}
+
+	c.Header("ETag", user.ETag())
+	c.JSON(http.StatusOK, user.Profile())
+}
+
+// emailInUse kiểm tra email đã thuộc về user khác hay chưa
+func emailInUse(email string, exceptID uint) (bool, error) {
+	query := database.DB.Model(&models.User{}).Where("email = ?", email)
+	if exceptID != 0 {
+		query = query.Where("id <> ?", exceptID)
+	}
+	var count int64
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+// checkHandle chuẩn hóa handle do user chọn và kiểm tra hợp lệ, chưa thuộc về user khác
+// (exceptID là chính user đó). Tự ghi response 400/409/500 nếu không dùng được.
+func checkHandle(c *gin.Context, raw string, exceptID uint) (string, bool) {
+	handle := services.NormalizeHandle(raw)
+	if err := services.ValidateHandle(handle); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return "", false
+	}
+	inUse, err := services.HandleInUse(database.DB, handle, exceptID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return "", false
+	}
+	if inUse {
+		c.JSON(http.StatusConflict, gin.H{"error": "Handle is already in use"})
+		return "", false
+	}
+	return handle, true
+}
+
+// filterUsers áp dụng các bộ lọc của danh sách users (dùng chung với export)
+//   - q: tìm theo name hoặc email
+//   - role: lọc theo role
+//   - email: lọc chính xác theo email
+func filterUsers(query *gorm.DB, c *gin.Context) *gorm.DB {
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		like := "%" + escapeLike(q) + "%"
+		query = query.Where("name LIKE ? OR email LIKE ?", like, like)
+	}
+	if role := c.Query("role"); role != "" {
+		query = query.Where("role = ?", role)
+	}
+	if email := c.Query("email"); email != "" {
+		query = query.Where("email = ?", email)
+	}
+	return query
+}
+
+// escapeLike escape các ký tự đặc biệt của LIKE
+func escapeLike(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(s)
 }
@@ -38,6 +38,8 @@ func TestCreateUser_Success(t *testing.T) {
 	database.DB = gormDB
 
 	// Mock SQL expectations
+	mock.ExpectQuery("SELECT `handle` FROM `users`").
+		WillReturnRows(sqlmock.NewRows([]string{"handle"}))
 	mock.ExpectBegin()
 	mock.ExpectExec("INSERT INTO `users`").
 		WithArgs("John Doe", "john@example.com", "1234567890").
@@ -70,6 +72,7 @@ func TestCreateUser_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "John Doe", response.Name)
 	assert.Equal(t, "john@example.com", response.Email)
+	assert.NotContains(t, w.Body.String(), "password")
 
 	// Verify all expectations were met
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -109,6 +112,9 @@ func TestCreateUser_DatabaseError(t *testing.T) {
 	database.DB = gormDB
 
 	// Mock SQL expectations với lỗi
+	mock.ExpectQuery("SELECT `handle` FROM `users`").
+		WithArgs(0, "johndoe%").
+		WillReturnRows(sqlmock.NewRows([]string{"handle"}))
 	mock.ExpectBegin()
 	mock.ExpectExec("INSERT INTO `users`").
 		WillReturnError(gorm.ErrInvalidData)
@@ -139,6 +145,7 @@ func TestCreateUser_DatabaseError(t *testing.T) {
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
 	assert.NotEmpty(t, response["error"])
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestGetUsers_Success(t *testing.T) {
@@ -243,3 +250,378 @@ func TestGetUsers_EmptyResult(t *testing.T) {
 	// Verify all expectations were met
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestGetUsers_Filters(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	// Ký tự % trong từ khóa phải được escape
+	mock.ExpectQuery("SELECT \\* FROM `users` WHERE \\(name LIKE \\? OR email LIKE \\?\\) AND role = \\?").
+		WithArgs("%50\\%%", "%50\\%%", "admin").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}))
+
+	// Create request
+	req, _ := http.NewRequest("GET", "/users?q=50%25&role=admin", nil)
+
+	// Create response recorder
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	// Execute
+	GetUsers(c)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// newUserRequest tạo context cho các endpoint /users/:id
+func newUserRequest(method, body string, headers map[string]string) (*gin.Context, *httptest.ResponseRecorder) {
+	req, _ := http.NewRequest(method, "/users/1", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	return c, w
+}
+
+func expectLoadUser(mock sqlmock.Sqlmock, version int) {
+	mock.ExpectQuery("SELECT \\* FROM `users` WHERE `users`.`id` = \\?").
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "role", "version"}).
+			AddRow(1, "John Doe", "john@example.com", "user", version))
+}
+
+func TestGetUser_ETag(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadUser(mock, 3)
+
+	// Execute
+	c, w := newUserRequest("GET", "", nil)
+	GetUser(c)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"user-1-v3"`, w.Header().Get("ETag"))
+	assert.NotContains(t, w.Body.String(), "password")
+}
+
+func TestGetUser_NotModified(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadUser(mock, 3)
+
+	// Execute
+	c, w := newUserRequest("GET", "", map[string]string{"If-None-Match": `W/"user-1-v3"`})
+	GetUser(c)
+
+	// Assert
+	assert.Equal(t, http.StatusNotModified, c.Writer.Status())
+	assert.Empty(t, w.Body.String())
+}
+
+func TestGetUsers_NotModified(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	for i := 0; i < 2; i++ {
+		mock.ExpectQuery("SELECT \\* FROM `users`").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).AddRow(1, "John Doe", "john@example.com"))
+	}
+
+	// Lần đầu lấy ETag
+	c, w := newUserRequest("GET", "", nil)
+	GetUsers(c)
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	// Lần hai gửi If-None-Match
+	c, w = newUserRequest("GET", "", map[string]string{"If-None-Match": etag})
+	GetUsers(c)
+
+	// Assert
+	assert.Equal(t, http.StatusNotModified, c.Writer.Status())
+	assert.Empty(t, w.Body.String())
+}
+
+func TestUpdateUser_MissingIfMatch(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadUser(mock, 1)
+
+	// Execute
+	c, w := newUserRequest("PUT", `{"email":"john@example.com","name":"John","role":"user"}`, nil)
+	UpdateUser(c)
+
+	// Assert
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+}
+
+func TestUpdateUser_StaleIfMatch(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadUser(mock, 2)
+
+	// Execute
+	c, w := newUserRequest("PUT", `{"email":"john@example.com","name":"John","role":"user"}`,
+		map[string]string{"If-Match": `"user-1-v1"`})
+	UpdateUser(c)
+
+	// Assert
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Equal(t, `"user-1-v2"`, w.Header().Get("ETag"))
+}
+
+func TestPatchUser_Success(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadUser(mock, 2)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `users` SET `name`=\\?,`version`=version \\+ 1 WHERE version = \\? AND `id` = \\?").
+		WithArgs("Johnny", 2, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Execute
+	c, w := newUserRequest("PATCH", `{"name":"Johnny"}`, map[string]string{"If-Match": `"user-1-v2"`})
+	PatchUser(c)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"user-1-v3"`, w.Header().Get("ETag"))
+	assert.Contains(t, w.Body.String(), "Johnny")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPatchUser_ConcurrentUpdate(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadUser(mock, 2)
+
+	// Một admin khác đã cập nhật giữa lúc đọc và ghi
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `users` SET").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	// Execute
+	c, w := newUserRequest("PATCH", `{"name":"Johnny"}`, map[string]string{"If-Match": `"user-1-v2"`})
+	PatchUser(c)
+
+	// Assert
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+}
+
+// expectUserCleanup khớp các bước dọn dữ liệu của user 1 trước khi xóa
+func expectUserCleanup(mock sqlmock.Sqlmock) {
+	mock.ExpectExec("UPDATE posts p\\s+JOIN \\(SELECT post_id, COUNT\\(\\*\\) AS n FROM comments").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE `comments` SET `content`=\\?,`deleted_at`=\\?,`updated_at`=\\? WHERE author_id = \\? AND deleted_at IS NULL").
+		WithArgs("", sqlmock.AnyArg(), sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("UPDATE tags t\\s+JOIN \\(SELECT pt.tag_id, COUNT\\(\\*\\) AS n FROM post_tags pt").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	for _, table := range []string{"reactions", "reaction_counts"} {
+		mock.ExpectExec("DELETE FROM `"+table+"` WHERE target_type = \\? AND target_id IN \\(SELECT `id` FROM `comments` WHERE post_id IN \\(SELECT `id` FROM `posts` WHERE author_id = \\?\\)\\)").
+			WithArgs(models.ReactionTargetComment, 1).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+	for _, table := range []string{"reactions", "reaction_counts"} {
+		mock.ExpectExec("DELETE FROM `"+table+"` WHERE target_type = \\? AND target_id IN \\(SELECT `id` FROM `posts` WHERE author_id = \\?\\)").
+			WithArgs(models.ReactionTargetPost, 1).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+	mock.ExpectExec("UPDATE reaction_counts rc\\s+JOIN reactions r ON .* WHERE r.user_id = \\?").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("UPDATE users u JOIN follows f ON f.followee_id = u.id\\s+SET u.follower_count = .* WHERE f.follower_id = \\?").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("UPDATE users u JOIN follows f ON f.follower_id = u.id\\s+SET u.following_count = .* WHERE f.followee_id = \\?").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func TestDeleteUser_Success(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadUser(mock, 2)
+
+	mock.ExpectBegin()
+	expectUserCleanup(mock)
+	mock.ExpectExec("DELETE FROM `users` WHERE version = \\? AND `users`.`id` = \\?").
+		WithArgs(2, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Execute
+	c, _ := newUserRequest("DELETE", "", map[string]string{"If-Match": `"user-1-v2"`})
+	DeleteUser(c)
+
+	// Assert
+	assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUser_NotFound(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+
+	mock.ExpectQuery("SELECT \\* FROM `users` WHERE `users`.`id` = \\?").
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	// Execute
+	c, w := newUserRequest("GET", "", nil)
+	GetUser(c)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestPatchUser_MergePatch(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadUser(mock, 2)
+
+	// Chỉ cột thay đổi (role) được ghi
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `users` SET `role`=\\?,`version`=version \\+ 1 WHERE version = \\? AND `id` = \\?").
+		WithArgs("admin", 2, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Execute
+	c, w := newUserRequest("PATCH", `{"role":"admin","name":"John Doe"}`, map[string]string{
+		"Content-Type": "application/merge-patch+json",
+		"If-Match":     `"user-1-v2"`,
+	})
+	PatchUser(c)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"role":"admin"`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPatchUser_JSONPatch(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadUser(mock, 2)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `users` SET `name`=\\?,`version`=version \\+ 1 WHERE version = \\? AND `id` = \\?").
+		WithArgs("Johnny", 2, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Execute
+	patch := `[{"op":"test","path":"/id","value":1},{"op":"replace","path":"/name","value":"Johnny"}]`
+	c, w := newUserRequest("PATCH", patch, map[string]string{
+		"Content-Type": "application/json-patch+json",
+		"If-Match":     `"user-1-v2"`,
+	})
+	PatchUser(c)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPatchUser_RejectsReadOnlyFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := map[string]struct{ contentType, patch string }{
+		"merge patch id":       {"application/merge-patch+json", `{"id":2}`},
+		"merge patch password": {"application/merge-patch+json", `{"password":"hacked123"}`},
+		"json patch password":  {"application/json-patch+json", `[{"op":"add","path":"/password","value":"hacked123"}]`},
+		"json patch root":      {"application/json-patch+json", `[{"op":"replace","path":"","value":{}}]`},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mock, gormDB := setupTestDB(t)
+			database.DB = gormDB
+			expectLoadUser(mock, 1)
+
+			c, w := newUserRequest("PATCH", tc.patch, map[string]string{
+				"Content-Type": tc.contentType,
+				"If-Match":     `"user-1-v1"`,
+			})
+			PatchUser(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), "read-only")
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPatchUser_InvalidResult(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadUser(mock, 1)
+
+	// Xóa email làm tài liệu không còn hợp lệ
+	c, w := newUserRequest("PATCH", `{"email":null}`, map[string]string{
+		"Content-Type": "application/merge-patch+json",
+		"If-Match":     `"user-1-v1"`,
+	})
+	PatchUser(c)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Email")
+}
+
+func TestPatchUser_FailedTestOperation(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mock, gormDB := setupTestDB(t)
+	database.DB = gormDB
+	expectLoadUser(mock, 1)
+
+	c, w := newUserRequest("PATCH", `[{"op":"test","path":"/name","value":"Someone else"}]`, map[string]string{
+		"Content-Type": "application/json-patch+json",
+		"If-Match":     `"user-1-v1"`,
+	})
+	PatchUser(c)
+
+	// Assert
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
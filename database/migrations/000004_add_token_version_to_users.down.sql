@@ -0,0 +1,3 @@
+-- Xóa cột 'token_version' để hoàn tác migration.
+ALTER TABLE users
+  DROP COLUMN token_version;
@@ -0,0 +1,4 @@
+-- Thêm cột 'token_version' để thu hồi các phiên đăng nhập (JWT) cũ.
+ALTER TABLE users
+  -- Mỗi lần đổi mật khẩu, giá trị này tăng lên và mọi token mang version cũ sẽ bị từ chối.
+  ADD COLUMN token_version INT UNSIGNED NOT NULL DEFAULT 0 AFTER password;
@@ -0,0 +1,2 @@
+-- Xóa bảng 'email_changes' để hoàn tác migration.
+DROP TABLE IF EXISTS email_changes;
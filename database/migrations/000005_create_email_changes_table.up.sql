@@ -0,0 +1,28 @@
+-- Tạo bảng 'email_changes' lưu các yêu cầu đổi email đang chờ xác nhận.
+CREATE TABLE email_changes (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+
+  -- user_id: user yêu cầu đổi email.
+  user_id INT NOT NULL,
+
+  -- old_email/new_email: địa chỉ cũ và địa chỉ mới (để gửi thông báo cho cả hai).
+  old_email VARCHAR(255) NOT NULL,
+  new_email VARCHAR(255) NOT NULL,
+
+  -- token: mã xác nhận gửi tới địa chỉ mới, duy nhất.
+  token VARCHAR(64) NOT NULL UNIQUE,
+
+  -- expires_at: hết hạn thì yêu cầu không còn hiệu lực.
+  expires_at TIMESTAMP NOT NULL,
+
+  -- confirmed_at: NULL nghĩa là chưa xác nhận.
+  confirmed_at TIMESTAMP NULL,
+
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  -- ON DELETE CASCADE: xóa user thì xóa luôn các yêu cầu đổi email.
+  CONSTRAINT fk_email_changes_user
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB;
@@ -0,0 +1,3 @@
+-- Xóa cột 'role' để hoàn tác migration.
+ALTER TABLE users
+  DROP COLUMN role;
@@ -0,0 +1,4 @@
+-- Thêm cột 'role' để phân quyền (user thường / admin).
+ALTER TABLE users
+  -- Mặc định mọi user đều là 'user', admin được cấp thủ công.
+  ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user' AFTER name;
@@ -0,0 +1,3 @@
+-- Xóa cột 'version' để hoàn tác migration.
+ALTER TABLE users
+  DROP COLUMN version;
@@ -0,0 +1,4 @@
+-- Thêm cột 'version' cho optimistic concurrency (ETag / If-Match).
+ALTER TABLE users
+  -- Tăng lên 1 sau mỗi lần cập nhật; ETag của user được sinh từ giá trị này.
+  ADD COLUMN version INT UNSIGNED NOT NULL DEFAULT 1 AFTER token_version;
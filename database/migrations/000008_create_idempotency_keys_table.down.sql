@@ -0,0 +1,2 @@
+-- Xóa bảng 'idempotency_keys' để hoàn tác migration.
+DROP TABLE IF EXISTS idempotency_keys;
@@ -0,0 +1,30 @@
+-- Tạo bảng 'idempotency_keys' lưu kết quả của các request POST có header Idempotency-Key.
+CREATE TABLE idempotency_keys (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+
+  -- idem_key: giá trị header Idempotency-Key do client gửi.
+  idem_key VARCHAR(255) NOT NULL,
+
+  -- scope: hash của method + path + Authorization, để key của user này không đụng user khác.
+  scope CHAR(64) NOT NULL,
+
+  -- fingerprint: hash của body request, dùng để phát hiện key bị dùng lại với body khác.
+  fingerprint CHAR(64) NOT NULL,
+
+  -- status: 'processing' khi request đang chạy, 'completed' khi đã lưu response.
+  status VARCHAR(20) NOT NULL,
+
+  -- Response đã lưu để trả lại khi client retry.
+  response_status INT NULL,
+  response_content_type VARCHAR(255) NULL,
+  response_body MEDIUMBLOB NULL,
+
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  -- expires_at: hết hạn thì key được coi như chưa dùng và bị dọn dẹp định kỳ.
+  expires_at TIMESTAMP NOT NULL,
+
+  -- Mỗi key chỉ dùng một lần trong cùng scope.
+  UNIQUE KEY uq_idempotency_keys_key_scope (idem_key, scope),
+  INDEX idx_idempotency_keys_expires_at (expires_at)
+) ENGINE=InnoDB;
@@ -0,0 +1,4 @@
+-- Hoàn tác migration: xóa cột đếm và bảng 'comments'.
+ALTER TABLE posts
+  DROP COLUMN comment_count;
+DROP TABLE IF EXISTS comments;
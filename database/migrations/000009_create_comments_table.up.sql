@@ -0,0 +1,47 @@
+-- Tạo bảng 'comments' lưu bình luận dạng cây dưới mỗi bài viết.
+CREATE TABLE comments (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+
+  -- post_id: bài viết chứa bình luận.
+  post_id INT NOT NULL,
+
+  -- parent_id: bình luận được trả lời, NULL nếu là bình luận gốc.
+  parent_id INT NULL,
+
+  -- root_id: bình luận gốc của thread, NULL nếu chính nó là gốc.
+  -- Dùng để lấy cả thread bằng một truy vấn.
+  root_id INT NULL,
+
+  -- depth: độ sâu trong cây, bình luận gốc là 0.
+  depth INT UNSIGNED NOT NULL DEFAULT 0,
+
+  author_id INT NOT NULL,
+  content TEXT NOT NULL,
+
+  -- deleted_at: khác NULL nghĩa là bình luận đã bị xóa (tombstone),
+  -- dòng vẫn được giữ để không làm gãy cây trả lời.
+  deleted_at TIMESTAMP NULL,
+
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+  INDEX idx_comments_post_parent (post_id, parent_id),
+  INDEX idx_comments_root (root_id),
+
+  CONSTRAINT fk_comments_post
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_comments_parent
+    FOREIGN KEY (parent_id)
+    REFERENCES comments(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_comments_author
+    FOREIGN KEY (author_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+-- comment_count: số bình luận chưa bị xóa, cập nhật cùng transaction với bảng comments.
+ALTER TABLE posts
+  ADD COLUMN comment_count INT UNSIGNED NOT NULL DEFAULT 0;
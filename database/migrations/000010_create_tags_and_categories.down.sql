@@ -0,0 +1,5 @@
+-- Hoàn tác migration: xóa các bảng nối trước rồi tới bảng chính.
+DROP TABLE IF EXISTS post_categories;
+DROP TABLE IF EXISTS categories;
+DROP TABLE IF EXISTS post_tags;
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,48 @@
+-- Tạo bảng 'tags': tag tự do, được chuẩn hóa thành slug.
+CREATE TABLE tags (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  name VARCHAR(50) NOT NULL,
+
+  -- slug: dạng chuẩn hóa của tên, dùng để so khớp và lọc.
+  slug VARCHAR(50) NOT NULL UNIQUE,
+
+  -- usage_count: số post đang gắn tag, cập nhật cùng transaction với post_tags.
+  usage_count INT UNSIGNED NOT NULL DEFAULT 0,
+
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  INDEX idx_tags_usage (usage_count)
+) ENGINE=InnoDB;
+
+-- Bảng nối post - tag (nhiều - nhiều).
+CREATE TABLE post_tags (
+  post_id INT NOT NULL,
+  tag_id INT NOT NULL,
+  PRIMARY KEY (post_id, tag_id),
+  INDEX idx_post_tags_tag (tag_id),
+  CONSTRAINT fk_post_tags_post FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+  CONSTRAINT fk_post_tags_tag FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+-- Tạo bảng 'categories': cây danh mục, parent_id NULL là danh mục gốc.
+CREATE TABLE categories (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  name VARCHAR(100) NOT NULL,
+  slug VARCHAR(100) NOT NULL UNIQUE,
+  parent_id INT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+  -- Không cho xóa danh mục còn danh mục con (kiểm tra thêm ở tầng ứng dụng).
+  CONSTRAINT fk_categories_parent FOREIGN KEY (parent_id) REFERENCES categories(id)
+) ENGINE=InnoDB;
+
+-- Bảng nối post - category (nhiều - nhiều).
+CREATE TABLE post_categories (
+  post_id INT NOT NULL,
+  category_id INT NOT NULL,
+  PRIMARY KEY (post_id, category_id),
+  INDEX idx_post_categories_category (category_id),
+  CONSTRAINT fk_post_categories_post FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+  CONSTRAINT fk_post_categories_category FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
+) ENGINE=InnoDB;
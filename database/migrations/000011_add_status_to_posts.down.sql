@@ -0,0 +1,6 @@
+-- Xóa các cột trạng thái để hoàn tác migration.
+ALTER TABLE posts
+  DROP INDEX idx_posts_status_scheduled,
+  DROP COLUMN scheduled_for,
+  DROP COLUMN published_at,
+  DROP COLUMN status;
@@ -0,0 +1,15 @@
+-- Thêm trạng thái cho posts: draft, scheduled, published, archived.
+ALTER TABLE posts
+  ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'draft' AFTER author_id,
+
+  -- published_at: thời điểm post được xuất bản (lần gần nhất).
+  ADD COLUMN published_at TIMESTAMP NULL AFTER status,
+
+  -- scheduled_for: thời điểm sẽ tự động xuất bản, chỉ có giá trị khi status = 'scheduled'.
+  ADD COLUMN scheduled_for TIMESTAMP NULL AFTER published_at,
+
+  -- Scheduler tìm post đến hạn theo (status, scheduled_for).
+  ADD INDEX idx_posts_status_scheduled (status, scheduled_for);
+
+-- Các post có sẵn trước khi có workflow vẫn đang hiển thị nên coi là đã xuất bản.
+UPDATE posts SET status = 'published', published_at = created_at;
@@ -0,0 +1,2 @@
+-- Xóa bảng 'post_revisions' để hoàn tác migration.
+DROP TABLE IF EXISTS post_revisions;
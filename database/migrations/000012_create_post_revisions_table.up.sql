@@ -0,0 +1,30 @@
+-- Tạo bảng 'post_revisions' lưu lịch sử chỉnh sửa title/content của post.
+CREATE TABLE post_revisions (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  post_id INT NOT NULL,
+
+  -- revision: số thứ tự tăng dần trong từng post, bắt đầu từ 1.
+  revision INT UNSIGNED NOT NULL,
+
+  title VARCHAR(255) NOT NULL,
+  content TEXT NULL,
+
+  -- editor_id: user tạo ra revision này.
+  editor_id INT NOT NULL,
+
+  -- restored_from: revision được khôi phục (nếu revision này sinh ra từ restore).
+  restored_from INT UNSIGNED NULL,
+
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  UNIQUE KEY uq_post_revisions_post_revision (post_id, revision),
+
+  CONSTRAINT fk_post_revisions_post
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_post_revisions_editor
+    FOREIGN KEY (editor_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB;
@@ -0,0 +1,3 @@
+-- Xóa các bảng reaction để hoàn tác migration.
+DROP TABLE IF EXISTS reaction_counts;
+DROP TABLE IF EXISTS reactions;
@@ -0,0 +1,33 @@
+-- Tạo bảng 'reactions': mỗi user chỉ có một reaction mỗi loại trên một post/comment.
+CREATE TABLE reactions (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+
+  -- target_type/target_id: đối tượng được react ('post' hoặc 'comment').
+  target_type VARCHAR(20) NOT NULL,
+  target_id INT NOT NULL,
+
+  user_id INT NOT NULL,
+
+  -- type: like, love, haha, wow, sad, angry.
+  type VARCHAR(20) NOT NULL,
+
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  UNIQUE KEY uq_reactions_target_user_type (target_type, target_id, user_id, type),
+  INDEX idx_reactions_user (user_id),
+
+  CONSTRAINT fk_reactions_user
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+-- Bộ đếm reaction theo từng loại, cập nhật cùng transaction với bảng 'reactions'.
+-- Có thể tính lại từ bảng 'reactions' bằng lệnh: go run . repair-reaction-counts
+CREATE TABLE reaction_counts (
+  target_type VARCHAR(20) NOT NULL,
+  target_id INT NOT NULL,
+  type VARCHAR(20) NOT NULL,
+  count INT UNSIGNED NOT NULL DEFAULT 0,
+  PRIMARY KEY (target_type, target_id, type)
+) ENGINE=InnoDB;
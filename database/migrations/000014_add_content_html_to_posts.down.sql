@@ -0,0 +1,3 @@
+-- Xóa cột HTML đã render để hoàn tác migration.
+ALTER TABLE posts
+  DROP COLUMN content_html;
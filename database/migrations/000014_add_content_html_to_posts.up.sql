@@ -0,0 +1,4 @@
+-- content_html: HTML đã render từ Markdown trong content và đã lọc qua sanitizer.
+-- Post có sẵn để NULL, chạy `go run . render-posts` để render lại.
+ALTER TABLE posts
+  ADD COLUMN content_html MEDIUMTEXT NULL AFTER content;
@@ -0,0 +1,7 @@
+-- Xóa các chỉ mục FULLTEXT để hoàn tác migration.
+ALTER TABLE users
+  DROP INDEX ft_users_name_email,
+  DROP INDEX ft_users_name;
+
+ALTER TABLE posts
+  DROP INDEX ft_posts_title_content;
@@ -0,0 +1,9 @@
+-- Chỉ mục FULLTEXT cho tìm kiếm (GET /api/search), dùng với MATCH ... AGAINST.
+-- Danh sách cột trong MATCH phải trùng đúng với một chỉ mục nên user có hai chỉ mục:
+-- chỉ theo name (user thường) và theo name + email (admin).
+ALTER TABLE posts
+  ADD FULLTEXT INDEX ft_posts_title_content (title, content);
+
+ALTER TABLE users
+  ADD FULLTEXT INDEX ft_users_name (name),
+  ADD FULLTEXT INDEX ft_users_name_email (name, email);
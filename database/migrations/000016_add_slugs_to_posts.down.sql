@@ -0,0 +1,6 @@
+-- Xóa bảng lịch sử slug và cột slug để hoàn tác migration.
+DROP TABLE IF EXISTS post_slugs;
+
+ALTER TABLE posts
+  DROP INDEX uq_posts_slug,
+  DROP COLUMN slug;
@@ -0,0 +1,20 @@
+-- slug: đường dẫn dễ đọc sinh từ title (bỏ dấu tiếng Việt), duy nhất.
+-- Post có sẵn để NULL, chạy `go run . slug-posts` để sinh slug.
+ALTER TABLE posts
+  ADD COLUMN slug VARCHAR(255) NULL AFTER title,
+  ADD UNIQUE INDEX uq_posts_slug (slug);
+
+-- Tạo bảng 'post_slugs' lưu các slug cũ của post (sau khi đổi title) để chuyển hướng 301.
+-- Slug cũ vẫn thuộc về post đó, post khác không được dùng lại.
+CREATE TABLE post_slugs (
+  slug VARCHAR(255) PRIMARY KEY,
+  post_id INT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  INDEX idx_post_slugs_post (post_id),
+
+  CONSTRAINT fk_post_slugs_post
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB;
@@ -0,0 +1,9 @@
+-- Xóa bảng follows, bộ đếm và chỉ mục timeline để hoàn tác migration.
+ALTER TABLE posts
+  DROP INDEX idx_posts_author_published;
+
+ALTER TABLE users
+  DROP COLUMN following_count,
+  DROP COLUMN follower_count;
+
+DROP TABLE IF EXISTS follows;
@@ -0,0 +1,29 @@
+-- Tạo bảng 'follows': follower_id theo dõi followee_id.
+CREATE TABLE follows (
+  follower_id INT NOT NULL,
+  followee_id INT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (follower_id, followee_id),
+  -- Danh sách follower của một user, mới nhất trước.
+  INDEX idx_follows_followee (followee_id, created_at),
+
+  CONSTRAINT fk_follows_follower
+    FOREIGN KEY (follower_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_follows_followee
+    FOREIGN KEY (followee_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+-- Bộ đếm follow, cập nhật cùng transaction với bảng 'follows'.
+-- Có thể tính lại bằng lệnh: go run . repair-follow-counts
+ALTER TABLE users
+  ADD COLUMN follower_count INT UNSIGNED NOT NULL DEFAULT 0,
+  ADD COLUMN following_count INT UNSIGNED NOT NULL DEFAULT 0;
+
+-- Timeline lấy post đã xuất bản của các tác giả được theo dõi theo published_at.
+ALTER TABLE posts
+  ADD INDEX idx_posts_author_published (author_id, status, published_at);
@@ -0,0 +1,4 @@
+-- Xóa các bảng thông báo để hoàn tác migration.
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS notification_actors;
+DROP TABLE IF EXISTS notifications;
@@ -0,0 +1,78 @@
+-- Tạo bảng 'notifications': thông báo trong ứng dụng của từng user.
+-- Các sự kiện giống nhau (cùng loại, cùng đối tượng) được gộp vào một thông báo chưa đọc,
+-- ví dụ "5 người đã react post của bạn".
+CREATE TABLE notifications (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+
+  -- user_id: người nhận thông báo.
+  user_id INT NOT NULL,
+
+  -- type: comment, follow, mention, reaction.
+  type VARCHAR(20) NOT NULL,
+
+  -- actor_id: người gây ra sự kiện gần nhất; actor_count: số người khác nhau đã được gộp.
+  actor_id INT NOT NULL,
+  actor_count INT UNSIGNED NOT NULL DEFAULT 1,
+
+  -- target_type/target_id: đối tượng của sự kiện ('post', 'comment' hoặc 'user').
+  target_type VARCHAR(20) NOT NULL,
+  target_id INT NOT NULL,
+
+  -- post_id: post liên quan (nếu có) để client mở đúng trang; xóa post thì xóa thông báo.
+  post_id INT NULL,
+
+  -- group_key: khóa gộp, chỉ có giá trị khi chưa đọc nên mỗi nhóm có tối đa một thông báo mở.
+  group_key VARCHAR(100) NULL,
+
+  read_at TIMESTAMP NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+  UNIQUE KEY uq_notifications_user_group (user_id, group_key),
+  INDEX idx_notifications_user_updated (user_id, updated_at),
+  INDEX idx_notifications_user_read (user_id, read_at),
+
+  CONSTRAINT fk_notifications_user
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_notifications_actor
+    FOREIGN KEY (actor_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_notifications_post
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+-- Những người đã được gộp vào một thông báo, để mỗi người chỉ được đếm một lần.
+CREATE TABLE notification_actors (
+  notification_id INT NOT NULL,
+  actor_id INT NOT NULL,
+
+  PRIMARY KEY (notification_id, actor_id),
+
+  CONSTRAINT fk_notification_actors_notification
+    FOREIGN KEY (notification_id)
+    REFERENCES notifications(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_notification_actors_actor
+    FOREIGN KEY (actor_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+-- Tùy chọn nhận thông báo theo loại; không có dòng nghĩa là đang bật.
+CREATE TABLE notification_preferences (
+  user_id INT NOT NULL,
+  type VARCHAR(20) NOT NULL,
+  enabled BOOLEAN NOT NULL,
+
+  PRIMARY KEY (user_id, type),
+
+  CONSTRAINT fk_notification_preferences_user
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB;
@@ -0,0 +1,12 @@
+-- Xóa các bảng và cột moderation để hoàn tác migration.
+ALTER TABLE users
+  DROP COLUMN suspended_at,
+  DROP COLUMN suspended_until;
+
+ALTER TABLE comments
+  DROP COLUMN hidden_at;
+
+UPDATE posts SET status = IF(published_at IS NULL, 'draft', 'published') WHERE status = 'hidden';
+
+DROP TABLE IF EXISTS moderation_log;
+DROP TABLE IF EXISTS reports;
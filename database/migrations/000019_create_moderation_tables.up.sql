@@ -0,0 +1,85 @@
+-- Tạo bảng 'reports': báo cáo vi phạm do user gửi hoặc do bộ lọc từ cấm tự tạo.
+CREATE TABLE reports (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+
+  -- reporter_id: người báo cáo; NULL khi do hệ thống tự gắn cờ (bộ lọc từ cấm).
+  reporter_id INT NULL,
+
+  -- target_type/target_id: đối tượng bị báo cáo ('post', 'comment' hoặc 'user').
+  target_type VARCHAR(20) NOT NULL,
+  target_id INT NOT NULL,
+
+  -- reason: spam, harassment, hate_speech, nudity, violence, other, banned_words (hệ thống).
+  reason VARCHAR(30) NOT NULL,
+  details TEXT NULL,
+
+  -- status: open -> in_review (đã giao cho moderator) -> resolved / dismissed.
+  status VARCHAR(20) NOT NULL DEFAULT 'open',
+  assignee_id INT NULL,
+
+  -- resolution: hành động đã áp dụng khi đóng báo cáo (hide, suspend, dismiss).
+  resolution VARCHAR(20) NULL,
+  resolution_note TEXT NULL,
+  resolved_by INT NULL,
+  resolved_at TIMESTAMP NULL,
+
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+  -- Mỗi user chỉ báo cáo một đối tượng một lần.
+  UNIQUE KEY uq_reports_reporter_target (reporter_id, target_type, target_id),
+  -- Hàng đợi moderation: báo cáo theo trạng thái, cũ nhất trước.
+  INDEX idx_reports_status_created (status, created_at),
+  INDEX idx_reports_target (target_type, target_id, status),
+  INDEX idx_reports_assignee (assignee_id, status),
+
+  CONSTRAINT fk_reports_reporter
+    FOREIGN KEY (reporter_id)
+    REFERENCES users(id)
+    ON DELETE SET NULL,
+  CONSTRAINT fk_reports_assignee
+    FOREIGN KEY (assignee_id)
+    REFERENCES users(id)
+    ON DELETE SET NULL,
+  CONSTRAINT fk_reports_resolved_by
+    FOREIGN KEY (resolved_by)
+    REFERENCES users(id)
+    ON DELETE SET NULL
+) ENGINE=InnoDB;
+
+-- Tạo bảng 'moderation_log': nhật ký mọi hành động moderation, chỉ được thêm.
+-- Không có khóa ngoại để nhật ký còn nguyên khi user/nội dung bị xóa.
+CREATE TABLE moderation_log (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+
+  -- moderator_id: admin thực hiện; NULL khi do hệ thống (tự gắn cờ).
+  moderator_id INT NULL,
+
+  -- action: flag, assign, hide, unhide, suspend, unsuspend, dismiss.
+  action VARCHAR(20) NOT NULL,
+  target_type VARCHAR(20) NOT NULL,
+  target_id INT NOT NULL,
+  report_id INT NULL,
+  note TEXT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  INDEX idx_moderation_log_target (target_type, target_id, created_at),
+  INDEX idx_moderation_log_created (created_at)
+) ENGINE=InnoDB;
+
+CREATE TRIGGER moderation_log_no_update BEFORE UPDATE ON moderation_log
+  FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'moderation_log is append-only';
+
+CREATE TRIGGER moderation_log_no_delete BEFORE DELETE ON moderation_log
+  FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'moderation_log is append-only';
+
+-- Comment bị moderator ẩn: vẫn giữ vị trí trong cây nhưng không hiện nội dung.
+-- Post bị ẩn dùng status 'hidden' nên không cần thêm cột.
+ALTER TABLE comments
+  ADD COLUMN hidden_at TIMESTAMP NULL AFTER deleted_at;
+
+-- Tài khoản bị khóa: suspended_at có giá trị khi đang bị khóa,
+-- suspended_until là lúc tự mở khóa (NULL = khóa vô thời hạn).
+ALTER TABLE users
+  ADD COLUMN suspended_at TIMESTAMP NULL,
+  ADD COLUMN suspended_until TIMESTAMP NULL;
@@ -0,0 +1,6 @@
+-- Xóa bảng mentions và cột handle để hoàn tác migration.
+DROP TABLE IF EXISTS mentions;
+
+ALTER TABLE users
+  DROP INDEX uq_users_handle,
+  DROP COLUMN handle;
@@ -0,0 +1,44 @@
+-- handle: tên dùng để nhắc user (@handle) trong post và comment, duy nhất.
+-- User có sẵn để NULL, chạy `go run . assign-handles` để sinh handle từ tên.
+ALTER TABLE users
+  ADD COLUMN handle VARCHAR(30) NULL AFTER name,
+  ADD UNIQUE INDEX uq_users_handle (handle);
+
+-- Tạo bảng 'mentions': các user được nhắc trong một post hoặc comment.
+-- Danh sách được tính lại mỗi khi nội dung thay đổi.
+CREATE TABLE mentions (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+
+  -- user_id: người được nhắc; author_id: tác giả nội dung.
+  user_id INT NOT NULL,
+  author_id INT NOT NULL,
+
+  -- target_type/target_id: nội dung chứa mention ('post' hoặc 'comment').
+  target_type VARCHAR(20) NOT NULL,
+  target_id INT NOT NULL,
+
+  -- post_id: post chứa nội dung (chính nó với mention trong post).
+  post_id INT NOT NULL,
+
+  -- notified_at: thời điểm đã gửi thông báo; mention trong post chưa xuất bản chờ tới lúc xuất bản.
+  notified_at TIMESTAMP NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  UNIQUE KEY uq_mentions_target_user (target_type, target_id, user_id),
+  -- Danh sách mention của một user, mới nhất trước.
+  INDEX idx_mentions_user (user_id, created_at),
+  INDEX idx_mentions_post (post_id),
+
+  CONSTRAINT fk_mentions_user
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_mentions_author
+    FOREIGN KEY (author_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_mentions_post
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB;
@@ -0,0 +1,3 @@
+-- Xóa các bảng danh sách đọc để hoàn tác migration.
+DROP TABLE IF EXISTS reading_list_items;
+DROP TABLE IF EXISTS reading_lists;
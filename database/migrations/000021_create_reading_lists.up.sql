@@ -0,0 +1,44 @@
+-- Tạo bảng 'reading_lists': danh sách đọc (bookmark) do user đặt tên, có thể công khai.
+CREATE TABLE reading_lists (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  user_id INT NOT NULL,
+  name VARCHAR(100) NOT NULL,
+  description VARCHAR(500) NOT NULL DEFAULT '',
+
+  -- is_public: người khác xem được danh sách (chỉ gồm các post họ được xem).
+  is_public BOOLEAN NOT NULL DEFAULT FALSE,
+
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+  -- Tên danh sách không trùng trong cùng một user.
+  UNIQUE KEY uq_reading_lists_user_name (user_id, name),
+
+  CONSTRAINT fk_reading_lists_user
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+-- Tạo bảng 'reading_list_items': các post trong danh sách, sắp theo position.
+-- Xóa post thì item tự bị xóa theo khóa ngoại (giống posts -> users ở migration 000002).
+CREATE TABLE reading_list_items (
+  list_id INT NOT NULL,
+  post_id INT NOT NULL,
+  position INT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  PRIMARY KEY (list_id, post_id),
+  INDEX idx_reading_list_items_position (list_id, position),
+  -- Tra cứu "đã bookmark chưa" theo post.
+  INDEX idx_reading_list_items_post (post_id),
+
+  CONSTRAINT fk_reading_list_items_list
+    FOREIGN KEY (list_id)
+    REFERENCES reading_lists(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_reading_list_items_post
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB;
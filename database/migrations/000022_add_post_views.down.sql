@@ -0,0 +1,3 @@
+DROP INDEX idx_reactions_target_created ON reactions;
+DROP TABLE IF EXISTS post_view_stats;
+ALTER TABLE posts DROP COLUMN view_count;
@@ -0,0 +1,23 @@
+-- Tổng lượt xem của post, cộng dồn theo lô từ bộ đếm trong bộ nhớ (không ghi mỗi lần GET).
+ALTER TABLE posts
+  ADD COLUMN view_count BIGINT UNSIGNED NOT NULL DEFAULT 0;
+
+-- Tạo bảng 'post_view_stats': lượt xem theo từng giờ, dùng để tính bài đang thịnh hành.
+CREATE TABLE post_view_stats (
+  post_id INT NOT NULL,
+
+  -- bucket_start: đầu giờ (UTC) của khung giờ được đếm.
+  bucket_start DATETIME NOT NULL,
+  views INT UNSIGNED NOT NULL DEFAULT 0,
+
+  PRIMARY KEY (post_id, bucket_start),
+  INDEX idx_post_view_stats_bucket (bucket_start),
+
+  CONSTRAINT fk_post_view_stats_post
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+-- Trending lấy các reaction gần đây theo loại đối tượng.
+CREATE INDEX idx_reactions_target_created ON reactions (target_type, created_at);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS attachments;
+ALTER TABLE users
+  DROP COLUMN avatar_thumb_key,
+  DROP COLUMN avatar_key;
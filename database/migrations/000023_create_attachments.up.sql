@@ -0,0 +1,38 @@
+-- Avatar của user: key của ảnh (đã cắt vuông) và thumbnail trong storage.
+ALTER TABLE users
+  ADD COLUMN avatar_key VARCHAR(255) NULL,
+  ADD COLUMN avatar_thumb_key VARCHAR(255) NULL;
+
+-- Tạo bảng 'attachments': file đính kèm của post, nội dung nằm trong storage (local hoặc S3).
+CREATE TABLE attachments (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  post_id INT NOT NULL,
+  uploader_id INT NOT NULL,
+
+  -- storage_key/thumbnail_key: key trong storage; thumbnail chỉ có với ảnh.
+  storage_key VARCHAR(255) NOT NULL,
+  thumbnail_key VARCHAR(255) NULL,
+
+  -- filename: tên file gốc do client gửi, chỉ để hiển thị.
+  filename VARCHAR(255) NOT NULL,
+  -- content_type: định dạng nhận dạng theo nội dung file, không theo client.
+  content_type VARCHAR(100) NOT NULL,
+  size BIGINT UNSIGNED NOT NULL,
+  width INT NULL,
+  height INT NULL,
+
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  UNIQUE KEY uq_attachments_storage_key (storage_key),
+  INDEX idx_attachments_post (post_id, id),
+
+  -- Xóa post thì dòng attachment bị xóa theo; file trong storage do ứng dụng xóa.
+  CONSTRAINT fk_attachments_post
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_attachments_uploader
+    FOREIGN KEY (uploader_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB;
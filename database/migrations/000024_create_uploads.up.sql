@@ -0,0 +1,52 @@
+-- Tạo bảng 'uploads': upload resumable theo giao thức tus 1.0, mỗi upload thuộc về một user
+-- và khi nhận đủ dữ liệu sẽ thành attachment của post_id.
+CREATE TABLE uploads (
+  -- id: chuỗi ngẫu nhiên, nằm trong URL của upload (/api/uploads/:id).
+  id CHAR(32) NOT NULL PRIMARY KEY,
+  owner_id INT NOT NULL,
+  post_id INT NOT NULL,
+
+  filename VARCHAR(255) NOT NULL,
+  -- metadata: header Upload-Metadata nguyên văn, trả lại cho client ở HEAD.
+  metadata TEXT NULL,
+  upload_length BIGINT UNSIGNED NOT NULL,
+  upload_offset BIGINT UNSIGNED NOT NULL DEFAULT 0,
+  -- attachment_id: attachment tạo ra khi upload hoàn tất.
+  attachment_id INT NULL,
+
+  expires_at DATETIME NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+  INDEX idx_uploads_expires (expires_at),
+
+  -- Dòng upload bị xóa theo user/post/attachment; file trong storage do ứng dụng xóa.
+  CONSTRAINT fk_uploads_owner
+    FOREIGN KEY (owner_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_uploads_post
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE,
+  CONSTRAINT fk_uploads_attachment
+    FOREIGN KEY (attachment_id)
+    REFERENCES attachments(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+-- Tạo bảng 'upload_parts': mỗi request PATCH được lưu thành một file riêng trong storage,
+-- ghép lại theo start_offset khi upload hoàn tất.
+CREATE TABLE upload_parts (
+  upload_id CHAR(32) NOT NULL,
+  start_offset BIGINT UNSIGNED NOT NULL,
+  storage_key VARCHAR(255) NOT NULL,
+  size BIGINT UNSIGNED NOT NULL,
+
+  PRIMARY KEY (upload_id, start_offset),
+
+  CONSTRAINT fk_upload_parts_upload
+    FOREIGN KEY (upload_id)
+    REFERENCES uploads(id)
+    ON DELETE CASCADE
+) ENGINE=InnoDB;
@@ -0,0 +1,5 @@
+-- response_headers: các header của response đã lưu (JSON, ví dụ Location, ETag),
+-- trả lại cùng body khi client retry.
+-- Từ migration này scope được tính theo user đã xác thực thay vì header Authorization.
+ALTER TABLE idempotency_keys
+  ADD COLUMN response_headers TEXT NULL AFTER response_content_type;
@@ -0,0 +1,16 @@
+ALTER TABLE comments
+  DROP FOREIGN KEY fk_comments_parent,
+  DROP FOREIGN KEY fk_comments_author;
+
+DELETE FROM comments WHERE author_id IS NULL;
+
+ALTER TABLE comments
+  MODIFY author_id INT NOT NULL,
+  ADD CONSTRAINT fk_comments_parent
+    FOREIGN KEY (parent_id)
+    REFERENCES comments(id)
+    ON DELETE CASCADE,
+  ADD CONSTRAINT fk_comments_author
+    FOREIGN KEY (author_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE;
@@ -0,0 +1,16 @@
+-- Xóa user không còn xóa comment của họ: comment thành tombstone (ứng dụng xử lý)
+-- và author_id về NULL, các trả lời của người khác vẫn giữ nguyên vị trí trong cây.
+ALTER TABLE comments
+  DROP FOREIGN KEY fk_comments_parent,
+  DROP FOREIGN KEY fk_comments_author;
+
+ALTER TABLE comments
+  MODIFY author_id INT NULL,
+  ADD CONSTRAINT fk_comments_parent
+    FOREIGN KEY (parent_id)
+    REFERENCES comments(id)
+    ON DELETE SET NULL,
+  ADD CONSTRAINT fk_comments_author
+    FOREIGN KEY (author_id)
+    REFERENCES users(id)
+    ON DELETE SET NULL;
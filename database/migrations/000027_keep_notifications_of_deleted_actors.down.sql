@@ -0,0 +1,11 @@
+ALTER TABLE notifications
+  DROP FOREIGN KEY fk_notifications_actor;
+
+DELETE FROM notifications WHERE actor_id IS NULL;
+
+ALTER TABLE notifications
+  MODIFY actor_id INT NOT NULL,
+  ADD CONSTRAINT fk_notifications_actor
+    FOREIGN KEY (actor_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE;
@@ -0,0 +1,11 @@
+-- Xóa người gây ra sự kiện không còn xóa thông báo của người nhận: actor_id về NULL
+-- và thông báo hiển thị "Someone" (các actor còn lại vẫn được đếm trong actor_count).
+ALTER TABLE notifications
+  DROP FOREIGN KEY fk_notifications_actor;
+
+ALTER TABLE notifications
+  MODIFY actor_id INT NULL,
+  ADD CONSTRAINT fk_notifications_actor
+    FOREIGN KEY (actor_id)
+    REFERENCES users(id)
+    ON DELETE SET NULL;
@@ -0,0 +1,241 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"time"
+)
+
+// Content-Type của từng định dạng feed
+const (
+	RSSContentType  = "application/rss+xml; charset=utf-8"
+	AtomContentType = "application/atom+xml; charset=utf-8"
+	JSONContentType = "application/feed+json; charset=utf-8"
+)
+
+// Feed là dữ liệu chung, được xuất ra RSS 2.0, Atom 1.0 hoặc JSON Feed 1.1
+type Feed struct {
+	Title       string
+	Description string
+	Link        string // trang mà feed mô tả
+	FeedURL     string // URL của chính feed (rel="self")
+	Updated     time.Time
+	Items       []Item
+}
+
+// Item là một bài trong feed
+type Item struct {
+	ID          string // định danh không đổi của bài
+	Title       string
+	URL         string
+	ContentHTML string
+	Author      string
+	Tags        []string
+	Published   time.Time
+	Updated     time.Time
+}
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	DCNS    string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Self          rssSelf   `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssSelf struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// RSS xuất feed dạng RSS 2.0
+func (f *Feed) RSS() ([]byte, error) {
+	doc := rssDocument{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		DCNS:    "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        f.Link,
+			Description: f.Description,
+			Self:        rssSelf{Href: f.FeedURL, Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+	if !f.Updated.IsZero() {
+		doc.Channel.LastBuildDate = f.Updated.UTC().Format(time.RFC1123Z)
+	}
+	for _, item := range f.Items {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.URL,
+			GUID:        rssGUID{IsPermaLink: item.ID == item.URL, Value: item.ID},
+			PubDate:     item.Published.UTC().Format(time.RFC1123Z),
+			Creator:     item.Author,
+			Categories:  item.Tags,
+			Description: item.ContentHTML,
+		})
+	}
+	return marshalXML(doc)
+}
+
+type atomDocument struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     atomPerson     `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+	Content    atomContent    `xml:"content"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// Atom xuất feed dạng Atom 1.0
+func (f *Feed) Atom() ([]byte, error) {
+	doc := atomDocument{
+		Title:   f.Title,
+		ID:      f.FeedURL,
+		Updated: f.Updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: f.FeedURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: f.Link, Rel: "alternate"},
+		},
+	}
+	for _, item := range f.Items {
+		entry := atomEntry{
+			Title:     item.Title,
+			ID:        item.ID,
+			Link:      atomLink{Href: item.URL, Rel: "alternate"},
+			Published: item.Published.UTC().Format(time.RFC3339),
+			Updated:   item.Updated.UTC().Format(time.RFC3339),
+			Author:    atomPerson{Name: item.Author},
+			Content:   atomContent{Type: "html", Value: item.ContentHTML},
+		}
+		for _, tag := range item.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+	return marshalXML(doc)
+}
+
+type jsonDocument struct {
+	Version     string     `json:"version"`
+	Title       string     `json:"title"`
+	HomePageURL string     `json:"home_page_url,omitempty"`
+	FeedURL     string     `json:"feed_url"`
+	Description string     `json:"description,omitempty"`
+	Items       []jsonItem `json:"items"`
+}
+
+type jsonItem struct {
+	ID            string       `json:"id"`
+	URL           string       `json:"url"`
+	Title         string       `json:"title"`
+	ContentHTML   string       `json:"content_html"`
+	DatePublished string       `json:"date_published"`
+	DateModified  string       `json:"date_modified"`
+	Authors       []jsonAuthor `json:"authors,omitempty"`
+	Tags          []string     `json:"tags,omitempty"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+}
+
+// JSON xuất feed theo JSON Feed 1.1 (https://jsonfeed.org/version/1.1)
+func (f *Feed) JSON() ([]byte, error) {
+	doc := jsonDocument{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.Link,
+		FeedURL:     f.FeedURL,
+		Description: f.Description,
+		Items:       []jsonItem{},
+	}
+	for _, item := range f.Items {
+		entry := jsonItem{
+			ID:            item.ID,
+			URL:           item.URL,
+			Title:         item.Title,
+			ContentHTML:   item.ContentHTML,
+			DatePublished: item.Published.UTC().Format(time.RFC3339),
+			DateModified:  item.Updated.UTC().Format(time.RFC3339),
+			Tags:          item.Tags,
+		}
+		if item.Author != "" {
+			entry.Authors = []jsonAuthor{{Name: item.Author}}
+		}
+		doc.Items = append(doc.Items, entry)
+	}
+
+	// Giữ nguyên <, >, & trong content_html cho dễ đọc thay vì \u003c...
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+func marshalXML(doc interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
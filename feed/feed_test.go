@@ -0,0 +1,73 @@
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sampleFeed() *Feed {
+	published := time.Date(2026, 1, 2, 3, 4, 5, 0, time.FixedZone("ICT", 7*3600))
+	return &Feed{
+		Title:   "Posts",
+		Link:    "https://example.com/api/posts",
+		FeedURL: "https://example.com/feeds/posts.rss",
+		Updated: published,
+		Items: []Item{{
+			ID: "https://example.com/api/posts/1", URL: "https://example.com/api/posts/1",
+			Title: "A <b>", ContentHTML: "<p>x</p>", Author: "An", Tags: []string{"go"},
+			Published: published, Updated: published,
+		}},
+	}
+}
+
+func TestRSS(t *testing.T) {
+	body, err := sampleFeed().RSS()
+	assert.NoError(t, err)
+
+	var doc struct {
+		Items []struct {
+			Title       string `xml:"title"`
+			PubDate     string `xml:"pubDate"`
+			Description string `xml:"description"`
+		} `xml:"channel>item"`
+	}
+	assert.NoError(t, xml.Unmarshal(body, &doc))
+	assert.Equal(t, "A <b>", doc.Items[0].Title)
+	assert.Equal(t, "Thu, 01 Jan 2026 20:04:05 +0000", doc.Items[0].PubDate)
+	assert.Equal(t, "<p>x</p>", doc.Items[0].Description)
+	assert.Contains(t, string(body), `<atom:link href="https://example.com/feeds/posts.rss" rel="self" type="application/rss+xml">`)
+	assert.Contains(t, string(body), `<guid isPermaLink="true">`)
+}
+
+func TestAtom(t *testing.T) {
+	body, err := sampleFeed().Atom()
+	assert.NoError(t, err)
+
+	var doc struct {
+		Updated string `xml:"updated"`
+		Entries []struct {
+			Author  string `xml:"author>name"`
+			Content string `xml:"content"`
+		} `xml:"entry"`
+	}
+	assert.NoError(t, xml.Unmarshal(body, &doc))
+	assert.Equal(t, "2026-01-01T20:04:05Z", doc.Updated)
+	assert.Equal(t, "An", doc.Entries[0].Author)
+	assert.Equal(t, "<p>x</p>", doc.Entries[0].Content)
+}
+
+func TestJSON_EmptyFeedHasItemsArray(t *testing.T) {
+	f := sampleFeed()
+	f.Items = nil
+	body, err := f.JSON()
+	assert.NoError(t, err)
+
+	var doc map[string]interface{}
+	assert.NoError(t, json.Unmarshal(body, &doc))
+	assert.Equal(t, "https://jsonfeed.org/version/1.1", doc["version"])
+	assert.Equal(t, []interface{}{}, doc["items"])
+}
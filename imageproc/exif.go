@@ -0,0 +1,147 @@
+package imageproc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+)
+
+// Orientation đọc thẻ Orientation (0x0112) trong EXIF của ảnh JPEG, trả về 1 (không xoay)
+// nếu không có hoặc không đọc được
+func Orientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		// Hết phần header (Start of Scan / End of Image)
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+// tiffOrientation tìm thẻ Orientation trong IFD0 của khối TIFF
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	offset := int(order.Uint32(tiff[4:]))
+	if offset+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for n := 0; n < count; n++ {
+		entry := offset + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			value := int(order.Uint16(tiff[entry+8:]))
+			if value < 1 || value > 8 {
+				return 1
+			}
+			return value
+		}
+	}
+	return 1
+}
+
+// Orient xoay/lật ảnh theo giá trị Orientation của EXIF (1-8) để ảnh hiển thị đúng chiều
+func Orient(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	src := toRGBA(img)
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for dy := 0; dy < dh; dy++ {
+		for dx := 0; dx < dw; dx++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // lật ngang
+				sx, sy = w-1-dx, dy
+			case 3: // xoay 180°
+				sx, sy = w-1-dx, h-1-dy
+			case 4: // lật dọc
+				sx, sy = dx, h-1-dy
+			case 5: // chuyển vị
+				sx, sy = dy, dx
+			case 6: // xoay 90° theo chiều kim đồng hồ
+				sx, sy = dy, h-1-dx
+			case 7: // chuyển vị ngược
+				sx, sy = w-1-dy, h-1-dx
+			case 8: // xoay 90° ngược chiều kim đồng hồ
+				sx, sy = w-1-dy, dx
+			}
+			copy(dst.Pix[dst.PixOffset(dx, dy):][:4], src.Pix[src.PixOffset(sx, sy):][:4])
+		}
+	}
+	return dst
+}
+
+// ErrInvalidWebP là lỗi khi file không phải WebP hợp lệ
+var ErrInvalidWebP = errors.New("invalid webp file")
+
+// StripWebPMetadata bỏ các chunk EXIF và XMP khỏi file WebP (thư viện chuẩn không giải mã
+// được WebP nên không mã hóa lại được như JPEG/PNG), giữ nguyên dữ liệu ảnh
+func StripWebPMetadata(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, ErrInvalidWebP
+	}
+	out := make([]byte, 12, len(data))
+	copy(out, data[:12])
+	for i := 12; i < len(data); {
+		if i+8 > len(data) {
+			return nil, ErrInvalidWebP
+		}
+		fourCC := string(data[i : i+4])
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + size + size%2 // chunk lẻ có 1 byte đệm
+		if size < 0 || end > len(data) {
+			return nil, ErrInvalidWebP
+		}
+		switch fourCC {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte{}, data[i:end]...)
+			if len(chunk) > 8 {
+				// Bỏ cờ EXIF (bit 3) và XMP (bit 2) của chunk VP8X
+				chunk[8] &^= 0x08 | 0x04
+			}
+			out = append(out, chunk...)
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, nil
+}
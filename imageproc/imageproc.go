@@ -0,0 +1,139 @@
+// Package imageproc xử lý ảnh upload: giải mã có giới hạn kích thước, xoay theo EXIF,
+// mã hóa lại (bỏ toàn bộ metadata) và tạo thumbnail. Chỉ dùng thư viện chuẩn.
+package imageproc
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// Định dạng ảnh giải mã được
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatGIF  = "gif"
+)
+
+// jpegQuality là chất lượng khi mã hóa lại JPEG
+const jpegQuality = 90
+
+// ErrTooManyPixels là lỗi khi ảnh lớn hơn giới hạn (chặn ảnh "bom" giải nén ra hàng GB)
+var ErrTooManyPixels = errors.New("image dimensions are too large")
+
+// Decode giải mã ảnh JPEG/PNG/GIF (GIF lấy khung đầu) không quá maxPixels điểm ảnh.
+// Ảnh JPEG được xoay/lật theo thẻ Orientation của EXIF để hiển thị đúng sau khi bỏ metadata.
+func Decode(data []byte, maxPixels int) (image.Image, string, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, "", ErrTooManyPixels
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	if format == FormatJPEG {
+		img = Orient(img, Orientation(data))
+	}
+	return img, format, nil
+}
+
+// Encode mã hóa ảnh theo format (GIF được ghi thành một khung tĩnh). Ảnh mới không mang
+// theo EXIF, GPS hay metadata nào của file gốc.
+func Encode(w io.Writer, img image.Image, format string) error {
+	switch format {
+	case FormatJPEG:
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: jpegQuality})
+	case FormatGIF:
+		return gif.Encode(w, img, nil)
+	default:
+		return png.Encode(w, img)
+	}
+}
+
+// Fit thu nhỏ ảnh (giữ tỉ lệ) để vừa khung maxWidth x maxHeight; ảnh đã vừa thì giữ nguyên
+func Fit(img image.Image, maxWidth, maxHeight int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxWidth && h <= maxHeight {
+		return img
+	}
+	// So sánh w/h với maxWidth/maxHeight mà không dùng số thực
+	if w*maxHeight > h*maxWidth {
+		h = max(1, h*maxWidth/w)
+		w = maxWidth
+	} else {
+		w = max(1, w*maxHeight/h)
+		h = maxHeight
+	}
+	return resize(toRGBA(img), w, h)
+}
+
+// Square cắt phần vuông ở giữa ảnh (dùng cho avatar)
+func Square(img image.Image) image.Image {
+	b := img.Bounds()
+	size := min(b.Dx(), b.Dy())
+	x := b.Min.X + (b.Dx()-size)/2
+	y := b.Min.Y + (b.Dy()-size)/2
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(dst, dst.Bounds(), img, image.Pt(x, y), draw.Src)
+	return dst
+}
+
+// Opaque cho biết ảnh không có điểm nào trong suốt (có thể lưu JPEG mà không mất nền)
+func Opaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+	return dst
+}
+
+// resize thu nhỏ bằng bộ lọc hộp: mỗi điểm ảnh đích là trung bình các điểm nguồn nó phủ
+func resize(src *image.RGBA, w, h int) *image.RGBA {
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for dy := 0; dy < h; dy++ {
+		y0 := dy * sh / h
+		y1 := max(y0+1, (dy+1)*sh/h)
+		for dx := 0; dx < w; dx++ {
+			x0 := dx * sw / w
+			x1 := max(x0+1, (dx+1)*sw/w)
+			var r, g, b, a, n int
+			for y := y0; y < y1; y++ {
+				i := src.PixOffset(x0, y)
+				for x := x0; x < x1; x++ {
+					r += int(src.Pix[i])
+					g += int(src.Pix[i+1])
+					b += int(src.Pix[i+2])
+					a += int(src.Pix[i+3])
+					i += 4
+					n++
+				}
+			}
+			j := dst.PixOffset(dx, dy)
+			dst.Pix[j] = uint8(r / n)
+			dst.Pix[j+1] = uint8(g / n)
+			dst.Pix[j+2] = uint8(b / n)
+			dst.Pix[j+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,103 @@
+package imageproc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// jpegWithOrientation tạo ảnh JPEG w x h có khối EXIF chỉ chứa thẻ Orientation
+func jpegWithOrientation(t *testing.T, w, h, orientation int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	// Góc trên trái màu đỏ để kiểm tra chiều xoay
+	for y := 0; y < min(h, 16); y++ {
+		for x := 0; x < min(w, 16); x++ {
+			img.Set(x, y, color.RGBA{255, 0, 0, 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}))
+
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01\x01\x12\x00\x03\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00")
+	binary.BigEndian.PutUint16(tiff[18:], uint16(orientation))
+	app1 := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(app1)+2))
+	segment = append(segment, app1...)
+
+	data := buf.Bytes()
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+func TestOrientation(t *testing.T) {
+	assert.Equal(t, 6, Orientation(jpegWithOrientation(t, 4, 2, 6)))
+	assert.Equal(t, 1, Orientation([]byte("not a jpeg")))
+}
+
+func TestDecode_AppliesOrientationAndStripsExif(t *testing.T) {
+	data := jpegWithOrientation(t, 40, 20, 6)
+
+	img, format, err := Decode(data, 10000)
+	require.NoError(t, err)
+	assert.Equal(t, FormatJPEG, format)
+	assert.Equal(t, image.Rect(0, 0, 20, 40), img.Bounds())
+	// Xoay 90° theo chiều kim đồng hồ: góc trên trái cũ sang góc trên phải
+	r, _, _, _ := img.At(19, 0).RGBA()
+	assert.Greater(t, r>>8, uint32(200))
+
+	var out bytes.Buffer
+	require.NoError(t, Encode(&out, img, format))
+	assert.NotContains(t, out.String(), "Exif")
+	assert.Equal(t, 1, Orientation(out.Bytes()))
+}
+
+func TestDecode_TooManyPixels(t *testing.T) {
+	_, _, err := Decode(jpegWithOrientation(t, 40, 20, 1), 799)
+
+	assert.ErrorIs(t, err, ErrTooManyPixels)
+}
+
+func TestFitAndSquare(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 100))
+
+	assert.Equal(t, image.Rect(0, 0, 200, 50), Fit(img, 200, 200).Bounds())
+	assert.Equal(t, image.Rect(0, 0, 400, 100), Fit(img, 500, 500).Bounds())
+	assert.Equal(t, image.Rect(0, 0, 100, 100), Square(img).Bounds())
+}
+
+func TestStripWebPMetadata(t *testing.T) {
+	chunk := func(fourCC string, payload []byte) []byte {
+		header := make([]byte, 8)
+		copy(header, fourCC)
+		binary.LittleEndian.PutUint32(header[4:], uint32(len(payload)))
+		out := append(header, payload...)
+		if len(payload)%2 == 1 {
+			out = append(out, 0)
+		}
+		return out
+	}
+	body := append([]byte("WEBP"), chunk("VP8X", []byte{0x0C, 0, 0, 0, 0, 0, 0, 0, 0, 0})...)
+	body = append(body, chunk("VP8 ", []byte{1, 2, 3})...)
+	body = append(body, chunk("EXIF", []byte("gps data"))...)
+	body = append(body, chunk("XMP ", []byte("<x/>"))...)
+	data := append([]byte("RIFF\x00\x00\x00\x00"), body...)
+	binary.LittleEndian.PutUint32(data[4:], uint32(len(body)))
+
+	out, err := StripWebPMetadata(data)
+
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), "gps data")
+	assert.NotContains(t, string(out), "XMP ")
+	assert.Contains(t, string(out), "VP8 ")
+	assert.Equal(t, byte(0), out[20]&0x0C)
+	assert.Equal(t, uint32(len(out)-8), binary.LittleEndian.Uint32(out[4:]))
+
+	_, err = StripWebPMetadata([]byte("RIFF\x00\x00\x00\x00WAVE"))
+	assert.ErrorIs(t, err, ErrInvalidWebP)
+}
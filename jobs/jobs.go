@@ -0,0 +1,180 @@
+package jobs
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"sync"
+	"time"
+)
+
+// Status là trạng thái của một background job
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+)
+
+// Thời gian giữ lại job đã kết thúc trước khi bị dọn khỏi bộ nhớ
+const retention = 24 * time.Hour
+
+// Info là trạng thái của một job tại một thời điểm
+type Info struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	OwnerID    uint        `json:"owner_id"`
+	Status     Status      `json:"status"`
+	Processed  int         `json:"processed"`
+	Progress   float64     `json:"progress"`
+	Error      string      `json:"error,omitempty"`
+	Result     interface{} `json:"result,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+	FinishedAt *time.Time  `json:"finished_at,omitempty"`
+
+	// Dữ liệu nội bộ, không trả về cho client (vd: đường dẫn file report)
+	Artifacts map[string]string `json:"-"`
+}
+
+// Job là một tác vụ chạy nền (import, export, ...) mà client có thể poll tiến độ
+type Job struct {
+	Info
+
+	cleanup []func()
+	mu      sync.Mutex
+}
+
+// Report cập nhật tiến độ: số dòng đã xử lý và tỉ lệ hoàn thành (0..1)
+func (j *Job) Report(processed int, progress float64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.Processed = processed
+	if progress > 1 {
+		progress = 1
+	}
+	j.Progress = progress
+}
+
+// SetArtifact lưu dữ liệu nội bộ gắn với job
+func (j *Job) SetArtifact(key, value string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.Artifacts == nil {
+		j.Artifacts = map[string]string{}
+	}
+	j.Artifacts[key] = value
+}
+
+// OnCleanup đăng ký hàm dọn dẹp (vd: xóa file tạm) khi job bị xóa khỏi bộ nhớ
+func (j *Job) OnCleanup(fn func()) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.cleanup = append(j.cleanup, fn)
+}
+
+// Snapshot trả về bản sao trạng thái của job để đọc an toàn
+func (j *Job) Snapshot() Info {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	info := j.Info
+	info.Artifacts = make(map[string]string, len(j.Artifacts))
+	for k, v := range j.Artifacts {
+		info.Artifacts[k] = v
+	}
+	return info
+}
+
+func (j *Job) finish(result interface{}, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now()
+	j.FinishedAt = &now
+	j.Result = result
+	if err != nil {
+		j.Status = StatusFailed
+		j.Error = err.Error()
+		return
+	}
+	j.Status = StatusCompleted
+	j.Progress = 1
+}
+
+var (
+	mu    sync.Mutex
+	store = map[string]*Job{}
+)
+
+// Start tạo job mới và chạy fn trong goroutine riêng.
+// Kết quả trả về của fn được gán vào Job.Result khi hoàn thành.
+func Start(jobType string, ownerID uint, fn func(j *Job) (interface{}, error)) *Job {
+	job := &Job{Info: Info{
+		ID:        newID(),
+		Type:      jobType,
+		OwnerID:   ownerID,
+		Status:    StatusPending,
+		CreatedAt: time.Now(),
+	}}
+
+	mu.Lock()
+	purgeLocked(time.Now())
+	store[job.ID] = job
+	mu.Unlock()
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("❌ Job %s (%s) panic: %v", job.ID, job.Type, r)
+				job.finish(nil, &panicError{r})
+			}
+		}()
+
+		job.mu.Lock()
+		job.Status = StatusRunning
+		job.mu.Unlock()
+
+		result, err := fn(job)
+		job.finish(result, err)
+	}()
+
+	return job
+}
+
+// Get tìm job theo ID
+func Get(id string) (*Job, bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	job, ok := store[id]
+	return job, ok
+}
+
+// purgeLocked xóa các job đã kết thúc quá thời gian lưu giữ
+func purgeLocked(now time.Time) {
+	for id, job := range store {
+		snapshot := job.Snapshot()
+		if snapshot.FinishedAt != nil && now.Sub(*snapshot.FinishedAt) > retention {
+			delete(store, id)
+			job.mu.Lock()
+			cleanup := job.cleanup
+			job.mu.Unlock()
+			for _, fn := range cleanup {
+				fn()
+			}
+		}
+	}
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+type panicError struct{ value interface{} }
+
+func (p *panicError) Error() string {
+	return "job panicked"
+}
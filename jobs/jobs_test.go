@@ -0,0 +1,84 @@
+package jobs
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// waitFor chờ job kết thúc hoặc hết thời gian
+func waitFor(t *testing.T, job *Job) Info {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		info := job.Snapshot()
+		if info.FinishedAt != nil {
+			return info
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("job did not finish in time")
+	return Info{}
+}
+
+func TestStart_Completed(t *testing.T) {
+	job := Start("test", 7, func(j *Job) (interface{}, error) {
+		j.Report(10, 0.5)
+		j.SetArtifact("report", "/tmp/report.csv")
+		return map[string]int{"total": 10}, nil
+	})
+
+	info := waitFor(t, job)
+	assert.Equal(t, StatusCompleted, info.Status)
+	assert.Equal(t, uint(7), info.OwnerID)
+	assert.Equal(t, 10, info.Processed)
+	assert.Equal(t, 1.0, info.Progress)
+	assert.Equal(t, map[string]int{"total": 10}, info.Result)
+	assert.Equal(t, "/tmp/report.csv", info.Artifacts["report"])
+
+	found, ok := Get(job.ID)
+	assert.True(t, ok)
+	assert.Equal(t, job, found)
+}
+
+func TestStart_Failed(t *testing.T) {
+	job := Start("test", 1, func(j *Job) (interface{}, error) {
+		return nil, errors.New("boom")
+	})
+
+	info := waitFor(t, job)
+	assert.Equal(t, StatusFailed, info.Status)
+	assert.Equal(t, "boom", info.Error)
+}
+
+func TestStart_Panic(t *testing.T) {
+	job := Start("test", 1, func(j *Job) (interface{}, error) {
+		panic("unexpected")
+	})
+
+	info := waitFor(t, job)
+	assert.Equal(t, StatusFailed, info.Status)
+}
+
+func TestPurge_RemovesOldJobsAndRunsCleanup(t *testing.T) {
+	cleaned := false
+	job := Start("test", 1, func(j *Job) (interface{}, error) {
+		return nil, nil
+	})
+	job.OnCleanup(func() { cleaned = true })
+	waitFor(t, job)
+
+	mu.Lock()
+	purgeLocked(time.Now().Add(retention + time.Minute))
+	mu.Unlock()
+
+	_, ok := Get(job.ID)
+	assert.False(t, ok)
+	assert.True(t, cleaned)
+}
+
+func TestGet_Unknown(t *testing.T) {
+	_, ok := Get("does-not-exist")
+	assert.False(t, ok)
+}
@@ -0,0 +1,354 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidPatch là lỗi khi patch sai cú pháp hoặc trỏ tới vị trí không tồn tại
+var ErrInvalidPatch = errors.New("invalid patch")
+
+// ErrTestFailed là lỗi khi một thao tác "test" không khớp
+var ErrTestFailed = errors.New("patch test operation failed")
+
+func invalid(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidPatch, fmt.Sprintf(format, args...))
+}
+
+// MergePatch áp dụng merge patch (RFC 7396) lên doc
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	var target, p interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, invalid("%v", err)
+	}
+	return json.Marshal(mergePatch(target, p))
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+			continue
+		}
+		targetObj[key] = mergePatch(targetObj[key], value)
+	}
+	return targetObj
+}
+
+// MergePatchFields trả về các field cấp cao nhất mà merge patch thay đổi.
+// Patch không phải object thay thế toàn bộ tài liệu nên trả về "".
+func MergePatchFields(patch []byte) ([]string, error) {
+	var p interface{}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, invalid("%v", err)
+	}
+	obj, ok := p.(map[string]interface{})
+	if !ok {
+		return []string{""}, nil
+	}
+	fields := make([]string, 0, len(obj))
+	for key := range obj {
+		fields = append(fields, key)
+	}
+	return fields, nil
+}
+
+// Operation là một thao tác trong JSON Patch
+type Operation struct {
+	Op    string           `json:"op"`
+	Path  string           `json:"path"`
+	From  string           `json:"from,omitempty"`
+	Value *json.RawMessage `json:"value,omitempty"`
+}
+
+// DecodeOperations đọc danh sách thao tác JSON Patch
+func DecodeOperations(patch []byte) ([]Operation, error) {
+	var ops []Operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, invalid("patch must be an array of operations: %v", err)
+	}
+	for i, op := range ops {
+		switch op.Op {
+		case "add", "replace", "test":
+			if op.Value == nil {
+				return nil, invalid("operation %d (%s) requires a value", i, op.Op)
+			}
+		case "remove":
+		case "move", "copy":
+			if _, err := parsePointer(op.From); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, invalid("operation %d has unknown op %q", i, op.Op)
+		}
+		if _, err := parsePointer(op.Path); err != nil {
+			return nil, err
+		}
+	}
+	return ops, nil
+}
+
+// JSONPatchFields trả về các field cấp cao nhất bị thay đổi bởi patch
+// ("test" không thay đổi gì; "move" thay đổi cả from). "" nghĩa là toàn bộ tài liệu.
+func JSONPatchFields(patch []byte) ([]string, error) {
+	ops, err := DecodeOperations(patch)
+	if err != nil {
+		return nil, err
+	}
+	var fields []string
+	for _, op := range ops {
+		switch op.Op {
+		case "test":
+			continue
+		case "move":
+			fields = append(fields, topLevel(op.From))
+		}
+		fields = append(fields, topLevel(op.Path))
+	}
+	return fields, nil
+}
+
+func topLevel(pointer string) string {
+	tokens, _ := parsePointer(pointer)
+	if len(tokens) == 0 {
+		return ""
+	}
+	return tokens[0]
+}
+
+// Apply áp dụng JSON Patch (RFC 6902) lên doc. Patch được áp dụng nguyên tử:
+// chỉ cần một thao tác lỗi thì không có thay đổi nào được trả về.
+func Apply(doc, patch []byte) ([]byte, error) {
+	ops, err := DecodeOperations(patch)
+	if err != nil {
+		return nil, err
+	}
+	var target interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+
+	for i, op := range ops {
+		target, err = applyOperation(target, op)
+		if err != nil {
+			if errors.Is(err, ErrTestFailed) {
+				return nil, fmt.Errorf("%w at operation %d (%s)", ErrTestFailed, i, op.Path)
+			}
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return json.Marshal(target)
+}
+
+func applyOperation(doc interface{}, op Operation) (interface{}, error) {
+	path, _ := parsePointer(op.Path)
+
+	var value interface{}
+	if op.Value != nil {
+		if err := json.Unmarshal(*op.Value, &value); err != nil {
+			return nil, invalid("%v", err)
+		}
+	}
+
+	switch op.Op {
+	case "add":
+		return add(doc, path, value)
+	case "remove":
+		doc, _, err := remove(doc, path)
+		return doc, err
+	case "replace":
+		if _, err := get(doc, path); err != nil {
+			return nil, err
+		}
+		doc, _, err := remove(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+	case "move":
+		from, _ := parsePointer(op.From)
+		if len(from) < len(path) && reflect.DeepEqual(from, path[:len(from)]) {
+			return nil, invalid("cannot move a value into one of its children")
+		}
+		doc, moved, err := remove(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, moved)
+	case "copy":
+		from, _ := parsePointer(op.From)
+		source, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, deepCopy(source))
+	case "test":
+		current, err := get(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(current, value) {
+			return nil, ErrTestFailed
+		}
+		return doc, nil
+	}
+	return nil, invalid("unknown op %q", op.Op)
+}
+
+// parsePointer tách JSON Pointer (RFC 6901) thành các token
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, invalid("pointer %q must start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func get(doc interface{}, path []string) (interface{}, error) {
+	node := doc
+	for _, token := range path {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			child, ok := n[token]
+			if !ok {
+				return nil, invalid("path /%s does not exist", strings.Join(path, "/"))
+			}
+			node = child
+		case []interface{}:
+			i, err := arrayIndex(token, len(n)-1)
+			if err != nil {
+				return nil, err
+			}
+			node = n[i]
+		default:
+			return nil, invalid("path /%s does not exist", strings.Join(path, "/"))
+		}
+	}
+	return node, nil
+}
+
+// update gọi fn trên container cha của token cuối và trả về tài liệu mới
+func update(node interface{}, path []string, fn func(parent interface{}, key string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return fn(node, path[0])
+	}
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, ok := n[path[0]]
+		if !ok {
+			return nil, invalid("path segment %q does not exist", path[0])
+		}
+		updated, err := update(child, path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		n[path[0]] = updated
+		return n, nil
+	case []interface{}:
+		i, err := arrayIndex(path[0], len(n)-1)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := update(n[i], path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		n[i] = updated
+		return n, nil
+	}
+	return nil, invalid("path segment %q is not a container", path[0])
+}
+
+func add(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return update(doc, path, func(parent interface{}, key string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			p[key] = value
+			return p, nil
+		case []interface{}:
+			i := len(p)
+			if key != "-" {
+				var err error
+				if i, err = arrayIndex(key, len(p)); err != nil {
+					return nil, err
+				}
+			}
+			p = append(p, nil)
+			copy(p[i+1:], p[i:])
+			p[i] = value
+			return p, nil
+		}
+		return nil, invalid("cannot add to a non-container value")
+	})
+}
+
+func remove(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, invalid("cannot remove the whole document")
+	}
+	var removed interface{}
+	doc, err := update(doc, path, func(parent interface{}, key string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			value, ok := p[key]
+			if !ok {
+				return nil, invalid("path %q does not exist", key)
+			}
+			removed = value
+			delete(p, key)
+			return p, nil
+		case []interface{}:
+			i, err := arrayIndex(key, len(p)-1)
+			if err != nil {
+				return nil, err
+			}
+			removed = p[i]
+			return append(p[:i], p[i+1:]...), nil
+		}
+		return nil, invalid("cannot remove from a non-container value")
+	})
+	return doc, removed, err
+}
+
+// arrayIndex đọc chỉ số mảng hợp lệ trong khoảng [0, max]
+func arrayIndex(token string, max int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, invalid("invalid array index %q", token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > max {
+		return 0, invalid("array index %q out of range", token)
+	}
+	return i, nil
+}
+
+func deepCopy(value interface{}) interface{} {
+	data, _ := json.Marshal(value)
+	var copied interface{}
+	json.Unmarshal(data, &copied)
+	return copied
+}
@@ -0,0 +1,103 @@
+package jsonpatch
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergePatch(t *testing.T) {
+	// Ví dụ từ RFC 7396
+	doc := `{"title":"Goodbye!","author":{"givenName":"John","familyName":"Doe"},"tags":["example","sample"],"content":"This will be unchanged"}`
+	patch := `{"title":"Hello!","phoneNumber":"+01-123-456-7890","author":{"familyName":null},"tags":["example"]}`
+
+	result, err := MergePatch([]byte(doc), []byte(patch))
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"title":"Hello!","author":{"givenName":"John"},"tags":["example"],"content":"This will be unchanged","phoneNumber":"+01-123-456-7890"}`, string(result))
+}
+
+func TestMergePatchFields(t *testing.T) {
+	fields, err := MergePatchFields([]byte(`{"name":"x","id":null}`))
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"name", "id"}, fields)
+
+	fields, err = MergePatchFields([]byte(`"replace everything"`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{""}, fields)
+
+	_, err = MergePatchFields([]byte(`{`))
+	assert.ErrorIs(t, err, ErrInvalidPatch)
+}
+
+func TestApply(t *testing.T) {
+	cases := []struct {
+		name, doc, patch, expected string
+	}{
+		{"add field", `{"a":1}`, `[{"op":"add","path":"/b","value":2}]`, `{"a":1,"b":2}`},
+		{"add to array", `{"a":[1,3]}`, `[{"op":"add","path":"/a/1","value":2}]`, `{"a":[1,2,3]}`},
+		{"append to array", `{"a":[1]}`, `[{"op":"add","path":"/a/-","value":2}]`, `{"a":[1,2]}`},
+		{"remove", `{"a":1,"b":2}`, `[{"op":"remove","path":"/b"}]`, `{"a":1}`},
+		{"remove from array", `{"a":[1,2,3]}`, `[{"op":"remove","path":"/a/1"}]`, `{"a":[1,3]}`},
+		{"replace", `{"a":{"b":1}}`, `[{"op":"replace","path":"/a/b","value":"x"}]`, `{"a":{"b":"x"}}`},
+		{"move", `{"a":1}`, `[{"op":"move","from":"/a","path":"/b"}]`, `{"b":1}`},
+		{"copy", `{"a":{"x":1}}`, `[{"op":"copy","from":"/a","path":"/b"}]`, `{"a":{"x":1},"b":{"x":1}}`},
+		{"escaped pointer", `{"a/b":1,"m~n":2}`, `[{"op":"replace","path":"/a~1b","value":3},{"op":"remove","path":"/m~0n"}]`, `{"a/b":3}`},
+		{"test passes", `{"a":[1,{"b":true}]}`, `[{"op":"test","path":"/a/1/b","value":true}]`, `{"a":[1,{"b":true}]}`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := Apply([]byte(tc.doc), []byte(tc.patch))
+			assert.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(result))
+		})
+	}
+}
+
+func TestApply_Errors(t *testing.T) {
+	cases := []struct {
+		name, doc, patch string
+		target           error
+	}{
+		{"test fails", `{"a":1}`, `[{"op":"test","path":"/a","value":2}]`, ErrTestFailed},
+		{"replace missing", `{"a":1}`, `[{"op":"replace","path":"/b","value":2}]`, ErrInvalidPatch},
+		{"remove missing", `{"a":1}`, `[{"op":"remove","path":"/b"}]`, ErrInvalidPatch},
+		{"index out of range", `{"a":[1]}`, `[{"op":"add","path":"/a/5","value":2}]`, ErrInvalidPatch},
+		{"leading zero index", `{"a":[1,2]}`, `[{"op":"remove","path":"/a/01"}]`, ErrInvalidPatch},
+		{"unknown op", `{}`, `[{"op":"merge","path":"/a"}]`, ErrInvalidPatch},
+		{"missing value", `{}`, `[{"op":"add","path":"/a"}]`, ErrInvalidPatch},
+		{"bad pointer", `{}`, `[{"op":"add","path":"a","value":1}]`, ErrInvalidPatch},
+		{"not an array", `{}`, `{"op":"add"}`, ErrInvalidPatch},
+		{"move into child", `{"a":{"b":1}}`, `[{"op":"move","from":"/a","path":"/a/b/c"}]`, ErrInvalidPatch},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Apply([]byte(tc.doc), []byte(tc.patch))
+			assert.True(t, errors.Is(err, tc.target), "got %v", err)
+		})
+	}
+}
+
+func TestApply_IsAtomic(t *testing.T) {
+	doc := []byte(`{"a":1}`)
+
+	_, err := Apply(doc, []byte(`[{"op":"replace","path":"/a","value":2},{"op":"test","path":"/a","value":3}]`))
+
+	assert.ErrorIs(t, err, ErrTestFailed)
+	assert.Equal(t, `{"a":1}`, string(doc))
+}
+
+func TestJSONPatchFields(t *testing.T) {
+	fields, err := JSONPatchFields([]byte(`[
+		{"op":"test","path":"/id","value":1},
+		{"op":"replace","path":"/name","value":"x"},
+		{"op":"move","from":"/email","path":"/tags/0"},
+		{"op":"add","path":"","value":{}}
+	]`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name", "email", "tags", ""}, fields)
+}
@@ -0,0 +1,73 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"net/smtp"
+	"sync"
+
+	"myapp/config"
+)
+
+// Mailer gửi email tới một địa chỉ
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// Default là mailer dùng chung cho toàn ứng dụng.
+// Nếu để nil, lần gửi đầu tiên sẽ khởi tạo từ env: có SMTP_HOST thì gửi qua SMTP,
+// ngược lại chỉ ghi log (phù hợp cho dev/test).
+var Default Mailer
+
+var initOnce sync.Once
+
+// Send gửi email bằng mailer mặc định
+func Send(to, subject, body string) error {
+	initOnce.Do(func() {
+		if Default == nil {
+			Default = newFromEnv()
+		}
+	})
+	return Default.Send(to, subject, body)
+}
+
+func newFromEnv() Mailer {
+	host := config.GetEnv("SMTP_HOST", "")
+	if host == "" {
+		return LogMailer{}
+	}
+	return &SMTPMailer{
+		Host:     host,
+		Port:     config.GetEnv("SMTP_PORT", "587"),
+		Username: config.GetEnv("SMTP_USER", ""),
+		Password: config.GetEnv("SMTP_PASS", ""),
+		From:     config.GetEnv("SMTP_FROM", "no-reply@localhost"),
+	}
+}
+
+// LogMailer chỉ ghi nội dung email ra log
+type LogMailer struct{}
+
+func (LogMailer) Send(to, subject, body string) error {
+	log.Printf("📧 [mail] to=%s subject=%q\n%s", to, subject, body)
+	return nil
+}
+
+// SMTPMailer gửi email qua SMTP server
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(to, subject, body string) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		m.From, to, subject, body)
+	return smtp.SendMail(m.Host+":"+m.Port, auth, m.From, []string{to}, []byte(msg))
+}
@@ -1,9 +1,25 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"myapp/blobstore"
 	"myapp/config"
+	"myapp/controllers"
 	"myapp/database"
+	"myapp/middleware"
 	"myapp/routes"
+	"myapp/search"
+	"myapp/services"
 )
 
 func main() {
@@ -13,10 +29,115 @@ func main() {
 	// Kết nối DB
 	database.InitDB()
 
+	// Chạy lệnh CLI nếu có, ví dụ: go run . import-users -file users.csv
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1], os.Args[2:]); err != nil {
+			log.Fatal("❌ ", err)
+		}
+		return
+	}
+
+	// Dọn các Idempotency-Key đã hết hạn mỗi giờ
+	middleware.StartIdempotencyCleanup(time.Hour)
+
+	// Xuất bản các post đã đến lịch, chu kỳ cấu hình qua POST_SCHEDULER_INTERVAL
+	services.StartPostScheduler(database.DB, durationEnv("POST_SCHEDULER_INTERVAL", 30*time.Second))
+
+	// Ghi thông báo chạy nền, request chỉ đưa sự kiện vào hàng đợi (NOTIFICATION_QUEUE_SIZE)
+	queueSize, err := strconv.Atoi(config.GetEnv("NOTIFICATION_QUEUE_SIZE", "1000"))
+	if err != nil || queueSize <= 0 {
+		queueSize = 1000
+	}
+	services.StartNotificationDelivery(database.DB, queueSize)
+
+	// Backend tìm kiếm cho GET /api/search, mặc định dùng FULLTEXT của MySQL
+	searcher, err := search.New(config.GetEnv("SEARCH_BACKEND", "mysql"), database.DB)
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	controllers.SearchBackend = searcher
+
+	// Từ cấm: nội dung chứa các từ này tự được gắn cờ cho moderator
+	filter, err := services.LoadWordFilter(config.GetEnv("BANNED_WORDS", ""), config.GetEnv("BANNED_WORDS_FILE", ""))
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	controllers.ContentFilter = filter
+
+	// Nơi lưu file upload: STORAGE_BACKEND=local (thư mục STORAGE_DIR) hoặc s3
+	files, err := newStorage(config.GetEnv("STORAGE_BACKEND", "local"))
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	controllers.Files = files
+
+	// Upload resumable (tus) bị xóa sau UPLOAD_EXPIRATION kể từ lần nhận dữ liệu cuối
+	controllers.UploadExpiration = durationEnv("UPLOAD_EXPIRATION", controllers.UploadExpiration)
+	services.StartUploadExpiry(database.DB, files, durationEnv("UPLOAD_CLEANUP_INTERVAL", time.Hour))
+
+	// Lượt xem post được đếm trong bộ nhớ và ghi vào DB theo lô mỗi VIEW_FLUSH_INTERVAL;
+	// một người xem chỉ tính một lần mỗi post trong VIEW_DEDUP_WINDOW
+	views := services.NewViewAggregator(database.DB, durationEnv("VIEW_DEDUP_WINDOW", 30*time.Minute))
+	views.Start(durationEnv("VIEW_FLUSH_INTERVAL", time.Minute))
+	controllers.Views = views
+	controllers.TrendingHalfLife = durationEnv("TRENDING_HALF_LIFE", controllers.TrendingHalfLife)
+
 	// Setup routes
 	r := routes.SetupRouter()
 
 	// Lấy port từ env
 	port := config.GetEnv("APP_PORT", "8080")
-	r.Run(":" + port)
+	srv := &http.Server{Addr: ":" + port, Handler: r}
+
+	// Tắt êm khi nhận SIGINT/SIGTERM: ngừng nhận request mới, chờ request đang xử lý
+	// (tối đa SHUTDOWN_TIMEOUT) rồi ghi nốt thông báo trong hàng đợi và lượt xem còn trong bộ nhớ
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("❌ ", err)
+		}
+	}()
+	<-ctx.Done()
+	stop()
+	log.Println("🛑 Đang tắt server...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), durationEnv("SHUTDOWN_TIMEOUT", 10*time.Second))
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("❌ Tắt server không êm: %v", err)
+	}
+	services.StopNotificationDelivery()
+	if err := views.Stop(); err != nil {
+		log.Printf("❌ Ghi lượt xem post khi tắt server thất bại: %v", err)
+	}
+	log.Println("✅ Đã tắt server")
+}
+
+// durationEnv đọc biến môi trường dạng duration (ví dụ "30s"), sai hoặc không dương thì dùng fallback
+func durationEnv(key string, fallback time.Duration) time.Duration {
+	d, err := time.ParseDuration(config.GetEnv(key, fallback.String()))
+	if err != nil || d <= 0 {
+		return fallback
+	}
+	return d
+}
+
+// newStorage tạo storage cho file upload theo backend
+func newStorage(backend string) (blobstore.Storage, error) {
+	switch backend {
+	case "local":
+		return blobstore.NewLocal(config.GetEnv("STORAGE_DIR", "storage/uploads"), "/api/files", services.SignPath), nil
+	case "s3":
+		return blobstore.NewS3(blobstore.S3Config{
+			Endpoint:  config.GetEnv("S3_ENDPOINT", "https://s3.amazonaws.com"),
+			Region:    config.GetEnv("S3_REGION", "us-east-1"),
+			Bucket:    config.GetEnv("S3_BUCKET", ""),
+			AccessKey: config.GetEnv("S3_ACCESS_KEY", ""),
+			SecretKey: config.GetEnv("S3_SECRET_KEY", ""),
+			PathStyle: config.GetEnv("S3_PATH_STYLE", "false") == "true",
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q (available: local, s3)", backend)
+	}
 }
@@ -0,0 +1,609 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	autolinkRe      = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9.+-]{1,31}:[^\s<>]*)>`)
+	emailAutolinkRe = regexp.MustCompile(`^<([a-zA-Z0-9.!#$%&'*+/=?^_` + "`" + `{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*)>`)
+	inlineHTMLRe    = regexp.MustCompile(`^(?:<[A-Za-z][A-Za-z0-9-]*(?:\s+[A-Za-z_:][A-Za-z0-9_.:-]*(?:\s*=\s*(?:[^\s"'=<>` + "`" + `]+|'[^']*'|"[^"]*"))?)*\s*/?>|</[A-Za-z][A-Za-z0-9-]*\s*>|<!--[\s\S]*?-->)`)
+	entityRe        = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});`)
+	tagRe           = regexp.MustCompile(`<[^>]*>`)
+	mentionRe       = regexp.MustCompile(`^@([A-Za-z][A-Za-z0-9_]{2,29})`)
+)
+
+// inode là một phần tử trong danh sách inline: HTML đã escape, hoặc dãy delimiter * / _
+type inode struct {
+	html              string
+	delim             byte
+	count, orig       int
+	canOpen, canClose bool
+	prev, next        *inode
+	dprev, dnext      *inode // delimiter stack
+}
+
+func (n *inode) String() string {
+	if n.delim != 0 {
+		return strings.Repeat(string(n.delim), n.count)
+	}
+	return n.html
+}
+
+type bracket struct {
+	node   *inode
+	pos    int // vị trí ngay sau '[' trong source
+	image  bool
+	active bool
+	delims *inode // đỉnh delimiter stack lúc gặp '['
+}
+
+type inlineParser struct {
+	src        string
+	pos        int
+	refs       map[string]linkRef
+	head, tail *inode
+	delims     *inode
+	brackets   []*bracket
+	mentions   *mentionSet
+}
+
+// mentionSet thu thập các @handle gặp khi render và cho biết handle nào được render thành link
+type mentionSet struct {
+	links map[string]string // handle viết thường -> href
+	found []string
+	seen  map[string]bool
+}
+
+func (m *mentionSet) add(handle string) {
+	if m.seen == nil {
+		m.seen = map[string]bool{}
+	}
+	if !m.seen[handle] {
+		m.seen[handle] = true
+		m.found = append(m.found, handle)
+	}
+}
+
+// renderInline chuyển nội dung inline (emphasis, code span, link, ảnh, HTML, @mention) thành HTML
+func renderInline(src string, refs map[string]linkRef, mentions *mentionSet) string {
+	p := &inlineParser{src: src, refs: refs, mentions: mentions}
+	for p.pos < len(src) {
+		switch c := src[p.pos]; c {
+		case '\\':
+			p.backslash()
+		case '`':
+			p.codeSpan()
+		case '*', '_':
+			p.delimiterRun(c)
+		case '!':
+			if p.pos+1 < len(src) && src[p.pos+1] == '[' {
+				p.openBracket(true)
+			} else {
+				p.text("!")
+				p.pos++
+			}
+		case '[':
+			p.openBracket(false)
+		case ']':
+			p.closeBracket()
+		case '<':
+			p.angle()
+		case '&':
+			p.entity()
+		case '@':
+			p.mention()
+		case '\n':
+			p.lineBreak()
+		default:
+			end := p.pos + 1
+			for end < len(src) && !strings.ContainsRune("\\`*_![]<&@\n", rune(src[end])) {
+				end++
+			}
+			p.text(escapeText(src[p.pos:end]))
+			p.pos = end
+		}
+	}
+	p.processEmphasis(nil)
+	return render(p.head)
+}
+
+func render(from *inode) string {
+	var b strings.Builder
+	for n := from; n != nil; n = n.next {
+		b.WriteString(n.String())
+	}
+	return b.String()
+}
+
+func (p *inlineParser) push(n *inode) {
+	n.prev = p.tail
+	if p.tail != nil {
+		p.tail.next = n
+	} else {
+		p.head = n
+	}
+	p.tail = n
+}
+
+func (p *inlineParser) text(s string) {
+	p.push(&inode{html: s})
+}
+
+func (p *inlineParser) backslash() {
+	if p.pos+1 < len(p.src) {
+		next := p.src[p.pos+1]
+		if next == '\n' {
+			p.text("<br />\n")
+			p.pos = skipSpaces(p.src, p.pos+2)
+			return
+		}
+		if isASCIIPunct(next) {
+			p.text(escapeText(string(next)))
+			p.pos += 2
+			return
+		}
+	}
+	p.text(`\`)
+	p.pos++
+}
+
+func (p *inlineParser) codeSpan() {
+	start := p.pos
+	after := start
+	for after < len(p.src) && p.src[after] == '`' {
+		after++
+	}
+	n := after - start
+
+	// Dãy backtick đóng phải dài đúng bằng dãy mở
+	for i := after; i < len(p.src); {
+		if p.src[i] != '`' {
+			i++
+			continue
+		}
+		j := i
+		for j < len(p.src) && p.src[j] == '`' {
+			j++
+		}
+		if j-i == n {
+			content := strings.ReplaceAll(p.src[after:i], "\n", " ")
+			if len(content) >= 2 && content[0] == ' ' && content[len(content)-1] == ' ' && strings.Trim(content, " ") != "" {
+				content = content[1 : len(content)-1]
+			}
+			p.text("<code>" + escapeText(content) + "</code>")
+			p.pos = j
+			return
+		}
+		i = j
+	}
+	p.text(p.src[start:after])
+	p.pos = after
+}
+
+func (p *inlineParser) delimiterRun(c byte) {
+	start := p.pos
+	for p.pos < len(p.src) && p.src[p.pos] == c {
+		p.pos++
+	}
+	before, after := '\n', '\n'
+	if start > 0 {
+		before, _ = utf8.DecodeLastRuneInString(p.src[:start])
+	}
+	if p.pos < len(p.src) {
+		after, _ = utf8.DecodeRuneInString(p.src[p.pos:])
+	}
+
+	left := !unicode.IsSpace(after) && (!isPunct(after) || unicode.IsSpace(before) || isPunct(before))
+	right := !unicode.IsSpace(before) && (!isPunct(before) || unicode.IsSpace(after) || isPunct(after))
+	canOpen, canClose := left, right
+	if c == '_' {
+		canOpen = left && (!right || isPunct(before))
+		canClose = right && (!left || isPunct(after))
+	}
+
+	n := &inode{delim: c, count: p.pos - start, orig: p.pos - start, canOpen: canOpen, canClose: canClose}
+	p.push(n)
+	if canOpen || canClose {
+		n.dprev = p.delims
+		if p.delims != nil {
+			p.delims.dnext = n
+		}
+		p.delims = n
+	}
+}
+
+func (p *inlineParser) removeDelim(d *inode) {
+	if d.dprev != nil {
+		d.dprev.dnext = d.dnext
+	}
+	if d.dnext != nil {
+		d.dnext.dprev = d.dprev
+	}
+	if p.delims == d {
+		p.delims = d.dprev
+	}
+	d.dprev, d.dnext = nil, nil
+}
+
+// processEmphasis ghép các cặp delimiter phía trên bottom thành <em>/<strong>
+// theo thuật toán của CommonMark
+func (p *inlineParser) processEmphasis(bottom *inode) {
+	var closer *inode
+	for d := p.delims; d != nil && d != bottom; d = d.dprev {
+		closer = d
+	}
+
+	type openerKey struct {
+		delim   byte
+		canOpen bool
+		mod     int
+	}
+	openersBottom := map[openerKey]*inode{}
+
+	for closer != nil {
+		if !closer.canClose {
+			closer = closer.dnext
+			continue
+		}
+
+		key := openerKey{closer.delim, closer.canOpen, closer.orig % 3}
+		floor, limited := openersBottom[key]
+		var opener *inode
+		if !limited || floor != nil {
+			for d := closer.dprev; d != nil && d != bottom && !(limited && d == floor); d = d.dprev {
+				if d.delim != closer.delim || !d.canOpen {
+					continue
+				}
+				// Quy tắc "bội của 3" khi một bên vừa mở vừa đóng được
+				if (d.canClose || closer.canOpen) && (d.orig+closer.orig)%3 == 0 && !(d.orig%3 == 0 && closer.orig%3 == 0) {
+					continue
+				}
+				opener = d
+				break
+			}
+		}
+
+		if opener == nil {
+			openersBottom[key] = closer.dprev
+			next := closer.dnext
+			if !closer.canOpen {
+				p.removeDelim(closer)
+			}
+			closer = next
+			continue
+		}
+
+		use, tag := 1, "em"
+		if opener.count >= 2 && closer.count >= 2 {
+			use, tag = 2, "strong"
+		}
+		opener.count -= use
+		closer.count -= use
+		insertAfter(opener, &inode{html: "<" + tag + ">"})
+		insertBefore(closer, &inode{html: "</" + tag + ">"})
+
+		for d := closer.dprev; d != nil && d != opener; {
+			prev := d.dprev
+			p.removeDelim(d)
+			d = prev
+		}
+		if opener.count == 0 {
+			p.removeDelim(opener)
+		}
+		if closer.count == 0 {
+			next := closer.dnext
+			p.removeDelim(closer)
+			closer = next
+		}
+	}
+
+	for p.delims != nil && p.delims != bottom {
+		p.removeDelim(p.delims)
+	}
+}
+
+func insertAfter(at, n *inode) {
+	n.prev, n.next = at, at.next
+	if at.next != nil {
+		at.next.prev = n
+	}
+	at.next = n
+}
+
+func insertBefore(at, n *inode) {
+	n.prev, n.next = at.prev, at
+	if at.prev != nil {
+		at.prev.next = n
+	}
+	at.prev = n
+}
+
+func (p *inlineParser) openBracket(image bool) {
+	text := "["
+	if image {
+		text = "!["
+	}
+	n := &inode{html: text}
+	p.push(n)
+	p.pos += len(text)
+	p.brackets = append(p.brackets, &bracket{node: n, pos: p.pos, image: image, active: true, delims: p.delims})
+}
+
+func (p *inlineParser) closeBracket() {
+	p.pos++
+	if len(p.brackets) == 0 {
+		p.text("]")
+		return
+	}
+	br := p.brackets[len(p.brackets)-1]
+	p.brackets = p.brackets[:len(p.brackets)-1]
+	if !br.active {
+		p.text("]")
+		return
+	}
+
+	dest, title, end, ok := p.linkTarget(p.src[br.pos : p.pos-1])
+	if !ok {
+		p.text("]")
+		return
+	}
+	p.pos = end
+
+	p.processEmphasis(br.delims)
+	inner := render(br.node.next)
+	titleAttr := ""
+	if title != "" {
+		titleAttr = ` title="` + escapeAttr(title) + `"`
+	}
+	if br.image {
+		alt := strings.ReplaceAll(tagRe.ReplaceAllString(inner, ""), `"`, "&quot;")
+		br.node.html = `<img src="` + escapeAttr(dest) + `" alt="` + alt + `"` + titleAttr + ` />`
+	} else {
+		br.node.html = `<a href="` + escapeAttr(dest) + `"` + titleAttr + `>` + inner + `</a>`
+		// Không cho link lồng trong link
+		for _, b := range p.brackets {
+			if !b.image {
+				b.active = false
+			}
+		}
+	}
+	br.node.next = nil
+	p.tail = br.node
+}
+
+// linkTarget đọc phần sau ']': (dest "title"), [ref], [] hoặc dùng chính label làm ref
+func (p *inlineParser) linkTarget(label string) (dest, title string, end int, ok bool) {
+	s, i := p.src, p.pos
+	if i < len(s) && s[i] == '(' {
+		if dest, title, end, ok := parseInlineLink(s, i+1); ok {
+			return dest, title, end, true
+		}
+	}
+
+	ref, end := label, i
+	if i < len(s) && s[i] == '[' {
+		if j := strings.IndexByte(s[i+1:], ']'); j >= 0 {
+			if j > 0 {
+				ref = s[i+1 : i+1+j]
+			}
+			end = i + 2 + j
+		}
+	}
+	def, ok := p.refs[normalizeLabel(ref)]
+	if !ok {
+		return "", "", 0, false
+	}
+	return def.dest, def.title, end, true
+}
+
+func parseInlineLink(s string, i int) (dest, title string, end int, ok bool) {
+	i = skipLinkSpace(s, i)
+	if i < len(s) && s[i] == '<' {
+		j := i + 1
+		for j < len(s) && s[j] != '>' && s[j] != '<' && s[j] != '\n' {
+			if s[j] == '\\' {
+				j++
+			}
+			j++
+		}
+		if j >= len(s) || s[j] != '>' {
+			return "", "", 0, false
+		}
+		dest, i = s[i+1:j], j+1
+	} else {
+		depth, j := 0, i
+		for j < len(s) && s[j] > ' ' {
+			if s[j] == '\\' && j+1 < len(s) && isASCIIPunct(s[j+1]) {
+				j += 2
+				continue
+			}
+			if s[j] == '(' {
+				depth++
+			} else if s[j] == ')' {
+				if depth == 0 {
+					break
+				}
+				depth--
+			}
+			j++
+		}
+		if depth != 0 {
+			return "", "", 0, false
+		}
+		dest, i = s[i:j], j
+	}
+
+	beforeTitle := i
+	i = skipLinkSpace(s, i)
+	if i > beforeTitle && i < len(s) && (s[i] == '"' || s[i] == '\'' || s[i] == '(') {
+		closing := s[i]
+		if closing == '(' {
+			closing = ')'
+		}
+		j := i + 1
+		for j < len(s) && s[j] != closing {
+			if s[j] == '\\' {
+				j++
+			}
+			j++
+		}
+		if j >= len(s) {
+			return "", "", 0, false
+		}
+		title = unescapeString(s[i+1 : j])
+		i = skipLinkSpace(s, j+1)
+	}
+	if i >= len(s) || s[i] != ')' {
+		return "", "", 0, false
+	}
+	return normalizeURL(unescapeString(dest)), title, i + 1, true
+}
+
+// skipLinkSpace bỏ khoảng trắng, cho phép tối đa một lần xuống dòng
+func skipLinkSpace(s string, i int) int {
+	newline := false
+	for i < len(s) {
+		switch {
+		case s[i] == ' ' || s[i] == '\t':
+		case s[i] == '\n' && !newline:
+			newline = true
+		default:
+			return i
+		}
+		i++
+	}
+	return i
+}
+
+func skipSpaces(s string, i int) int {
+	for i < len(s) && s[i] == ' ' {
+		i++
+	}
+	return i
+}
+
+func (p *inlineParser) angle() {
+	rest := p.src[p.pos:]
+	if m := autolinkRe.FindStringSubmatch(rest); m != nil {
+		p.text(`<a href="` + escapeAttr(normalizeURL(m[1])) + `">` + escapeText(m[1]) + `</a>`)
+		p.pos += len(m[0])
+		return
+	}
+	if m := emailAutolinkRe.FindStringSubmatch(rest); m != nil {
+		p.text(`<a href="mailto:` + escapeAttr(m[1]) + `">` + escapeText(m[1]) + `</a>`)
+		p.pos += len(m[0])
+		return
+	}
+	// HTML thô được giữ nguyên để Sanitize quyết định
+	if m := inlineHTMLRe.FindString(rest); m != "" {
+		p.text(m)
+		p.pos += len(m)
+		return
+	}
+	p.text("&lt;")
+	p.pos++
+}
+
+func (p *inlineParser) entity() {
+	if m := entityRe.FindString(p.src[p.pos:]); m != "" {
+		p.text(escapeText(html.UnescapeString(m)))
+		p.pos += len(m)
+		return
+	}
+	p.text("&amp;")
+	p.pos++
+}
+
+// mention: @handle đứng sau ký tự không thuộc từ/URL/email. Handle có trong links được render
+// thành link, trừ khi nằm trong text của link khác (không lồng thẻ <a>).
+func (p *inlineParser) mention() {
+	m := mentionRe.FindStringSubmatch(p.src[p.pos:])
+	if m == nil || p.mentions == nil || !mentionBoundary(p.src, p.pos, p.pos+len(m[0])) {
+		p.text("@")
+		p.pos++
+		return
+	}
+	handle := strings.ToLower(m[1])
+	p.mentions.add(handle)
+	if href, ok := p.mentions.links[handle]; ok && len(p.brackets) == 0 {
+		p.text(`<a href="` + escapeAttr(href) + `">` + escapeText(m[0]) + `</a>`)
+	} else {
+		p.text(escapeText(m[0]))
+	}
+	p.pos += len(m[0])
+}
+
+// mentionBoundary: trước "@" không phải chữ/số hoặc ký tự của email/URL, và handle không bị cắt giữa chừng
+func mentionBoundary(src string, start, end int) bool {
+	if start > 0 {
+		r, _ := utf8.DecodeLastRuneInString(src[:start])
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_./@+-:=", r) {
+			return false
+		}
+	}
+	if end < len(src) {
+		r, _ := utf8.DecodeRuneInString(src[end:])
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// lineBreak: hai khoảng trắng trở lên trước xuống dòng là hard break
+func (p *inlineParser) lineBreak() {
+	hard := false
+	if p.tail != nil && p.tail.delim == 0 {
+		trimmed := strings.TrimRight(p.tail.html, " ")
+		hard = len(p.tail.html)-len(trimmed) >= 2
+		p.tail.html = trimmed
+	}
+	if hard {
+		p.text("<br />\n")
+	} else {
+		p.text("\n")
+	}
+	p.pos = skipSpaces(p.src, p.pos+1)
+}
+
+func isASCIIPunct(c byte) bool {
+	return c < utf8.RuneSelf && strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func isPunct(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+// unescapeString bỏ backslash escape và giải mã entity trong URL/title
+func unescapeString(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return html.UnescapeString(b.String())
+}
+
+func normalizeURL(url string) string {
+	return strings.ReplaceAll(url, " ", "%20")
+}
+
+var (
+	textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+)
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+func escapeAttr(s string) string {
+	return attrEscaper.Replace(s)
+}
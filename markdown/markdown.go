@@ -0,0 +1,637 @@
+package markdown
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxNesting giới hạn số cấp blockquote/list lồng nhau; sâu hơn được coi là paragraph
+const maxNesting = 32
+
+// Render chuyển Markdown (CommonMark, kèm bảng và fenced code kiểu GFM) thành HTML.
+// Kết quả luôn đi qua Sanitize nên an toàn để trả thẳng cho trình duyệt;
+// các heading được gắn id để làm anchor.
+func Render(source string) string {
+	return renderDocument(source, nil)
+}
+
+// RenderWithMentions giống Render nhưng các @handle có trong links (khóa là handle viết thường)
+// được render thành link tới links[handle]. @handle trong code không phải mention.
+func RenderWithMentions(source string, links map[string]string) string {
+	return renderDocument(source, &mentionSet{links: links})
+}
+
+// Mentions trả về các handle được nhắc (@handle) trong source, viết thường, không trùng,
+// theo thứ tự xuất hiện. Bỏ qua code span, code block, HTML thô và @ trong email/URL.
+func Mentions(source string) []string {
+	mentions := &mentionSet{}
+	renderDocument(source, mentions)
+	return mentions.found
+}
+
+func renderDocument(source string, mentions *mentionSet) string {
+	p := &blockParser{refs: map[string]linkRef{}}
+	blocks := p.parse(splitLines(source), 0)
+
+	var b strings.Builder
+	r := &renderer{refs: p.refs, mentions: mentions}
+	r.blocks(&b, blocks, false)
+	return addHeadingAnchors(Sanitize(b.String()))
+}
+
+type blockKind int
+
+const (
+	paragraphBlock blockKind = iota
+	headingBlock
+	thematicBreakBlock
+	codeBlock
+	htmlBlock
+	quoteBlock
+	listBlock
+	itemBlock
+	tableBlock
+)
+
+type block struct {
+	kind     blockKind
+	level    int      // cấp heading
+	lines    []string // nội dung thô của paragraph, heading, code, html
+	lang     string   // ngôn ngữ của fenced code
+	children []*block
+	ordered  bool
+	start    int
+	tight    bool
+	align    []string   // căn lề từng cột của bảng
+	rows     [][]string // rows[0] là header
+}
+
+type linkRef struct {
+	dest, title string
+}
+
+var (
+	atxHeadingRe    = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+|$)(.*)$`)
+	setextRe        = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	thematicBreakRe = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	fenceOpenRe     = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*(.*)$")
+	fenceCloseRe    = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})[ \t]*$")
+	listMarkerRe    = regexp.MustCompile(`^( {0,3})([-+*]|\d{1,9}[.)])([ \t]*)(.*)$`)
+	htmlBlockRe     = regexp.MustCompile(`^ {0,3}(?:<!--|</?[A-Za-z][A-Za-z0-9-]*(?:[\s/>]|$))`)
+	tableDelimRe    = regexp.MustCompile(`^:?-+:?$`)
+	linkRefDefRe    = regexp.MustCompile(`^ {0,3}\[((?:[^\[\]\\]|\\.)+)\]:[ \t]*(<[^<>]*>|\S+)(?:[ \t]+("(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'|\((?:[^()\\]|\\.)*\)))?[ \t]*$`)
+)
+
+// splitLines chuẩn hóa xuống dòng; tab được giữ nguyên (quan trọng với code)
+// và chỉ được tính theo tab stop 4 cột khi xét thụt lề
+func splitLines(source string) []string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\r", "\n")
+	source = strings.ReplaceAll(source, "\x00", "\uFFFD")
+	return strings.Split(source, "\n")
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+// indentOf trả về số cột thụt lề, tab nhảy tới tab stop kế tiếp
+func indentOf(line string) int {
+	col := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case ' ':
+			col++
+		case '\t':
+			col += 4 - col%4
+		default:
+			return col
+		}
+	}
+	return col
+}
+
+// removeIndent bỏ tối đa n cột thụt lề; tab vượt quá n được đổi phần dư thành khoảng trắng
+func removeIndent(line string, n int) string {
+	col := 0
+	for i := 0; i < len(line) && col < n; i++ {
+		switch line[i] {
+		case ' ':
+			col++
+		case '\t':
+			next := col + 4 - col%4
+			if next > n {
+				return strings.Repeat(" ", next-n) + line[i+1:]
+			}
+			col = next
+		default:
+			return line[i:]
+		}
+		if col == n {
+			return line[i+1:]
+		}
+	}
+	if col < n {
+		return ""
+	}
+	return line
+}
+
+type blockParser struct {
+	refs map[string]linkRef
+}
+
+// parse tách các dòng thành cây block; depth là số cấp quote/list đang lồng
+func (p *blockParser) parse(lines []string, depth int) []*block {
+	var blocks []*block
+	var para []string
+	flush := func() {
+		if text := p.extractRefs(para); len(text) > 0 {
+			blocks = append(blocks, &block{kind: paragraphBlock, lines: text})
+		}
+		para = nil
+	}
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if isBlank(line) {
+			flush()
+			i++
+			continue
+		}
+		indent := indentOf(line)
+
+		// Dòng thụt 4 khoảng trắng nối tiếp paragraph, ngược lại là indented code
+		if indent >= 4 {
+			if len(para) > 0 {
+				para = append(para, line)
+				i++
+				continue
+			}
+			j := i
+			var code []string
+			for j < len(lines) && (isBlank(lines[j]) || indentOf(lines[j]) >= 4) {
+				code = append(code, removeIndent(lines[j], 4))
+				j++
+			}
+			for len(code) > 0 && isBlank(code[len(code)-1]) {
+				code = code[:len(code)-1]
+			}
+			blocks = append(blocks, &block{kind: codeBlock, lines: code})
+			i = j
+			continue
+		}
+
+		if len(para) > 0 {
+			if m := setextRe.FindStringSubmatch(line); m != nil {
+				level := 2
+				if m[1][0] == '=' {
+					level = 1
+				}
+				if text := p.extractRefs(para); len(text) > 0 {
+					blocks = append(blocks, &block{kind: headingBlock, level: level, lines: text})
+					para = nil
+					i++
+					continue
+				}
+				para = nil
+			}
+		}
+
+		if m := fenceOpenRe.FindStringSubmatch(line); m != nil && !(m[2][0] == '`' && strings.Contains(m[3], "`")) {
+			flush()
+			fence := m[2]
+			var code []string
+			j := i + 1
+			for ; j < len(lines); j++ {
+				if c := fenceCloseRe.FindStringSubmatch(lines[j]); c != nil && c[1][0] == fence[0] && len(c[1]) >= len(fence) {
+					j++
+					break
+				}
+				code = append(code, removeIndent(lines[j], len(m[1])))
+			}
+			lang := ""
+			if fields := strings.Fields(m[3]); len(fields) > 0 {
+				lang = unescapeString(fields[0])
+			}
+			blocks = append(blocks, &block{kind: codeBlock, lines: code, lang: lang})
+			i = j
+			continue
+		}
+
+		if m := atxHeadingRe.FindStringSubmatch(line); m != nil {
+			flush()
+			blocks = append(blocks, &block{kind: headingBlock, level: len(m[1]), lines: []string{headingText(m[2])}})
+			i++
+			continue
+		}
+
+		if thematicBreakRe.MatchString(line) {
+			flush()
+			blocks = append(blocks, &block{kind: thematicBreakBlock})
+			i++
+			continue
+		}
+
+		if _, ok := quoteLine(line); ok && depth < maxNesting {
+			flush()
+			var inner []string
+			j := i
+			for j < len(lines) {
+				if rest, ok := quoteLine(lines[j]); ok {
+					inner = append(inner, rest)
+					j++
+					continue
+				}
+				// Lazy continuation: dòng thường nối tiếp paragraph trong quote
+				if isBlank(lines[j]) || startsBlock(lines[j]) || isBlank(inner[len(inner)-1]) {
+					break
+				}
+				inner = append(inner, lines[j])
+				j++
+			}
+			blocks = append(blocks, &block{kind: quoteBlock, children: p.parse(inner, depth+1)})
+			i = j
+			continue
+		}
+
+		if item, ok := listMarker(line); ok && depth < maxNesting && (len(para) == 0 || item.canInterrupt()) {
+			flush()
+			list, next := p.parseList(lines, i, item, depth)
+			blocks = append(blocks, list)
+			i = next
+			continue
+		}
+
+		if len(para) == 0 && htmlBlockRe.MatchString(line) {
+			j := i
+			for j < len(lines) && !isBlank(lines[j]) {
+				j++
+			}
+			blocks = append(blocks, &block{kind: htmlBlock, lines: lines[i:j]})
+			i = j
+			continue
+		}
+
+		if align, ok := tableDelimiter(lines, i); ok {
+			flush()
+			table := &block{kind: tableBlock, align: align, rows: [][]string{fitRow(splitRow(line), len(align))}}
+			j := i + 2
+			for j < len(lines) && !isBlank(lines[j]) && !startsBlock(lines[j]) {
+				table.rows = append(table.rows, fitRow(splitRow(lines[j]), len(align)))
+				j++
+			}
+			blocks = append(blocks, table)
+			i = j
+			continue
+		}
+
+		para = append(para, line)
+		i++
+	}
+	flush()
+	return blocks
+}
+
+// parseList đọc các item liên tiếp cùng loại marker, trả về list và dòng kế tiếp
+func (p *blockParser) parseList(lines []string, i int, first listItemStart, depth int) (*block, int) {
+	list := &block{kind: listBlock, ordered: first.ordered, start: first.start, tight: true}
+	for i < len(lines) {
+		item, ok := listMarker(lines[i])
+		if !ok || item.ordered != first.ordered || item.marker != first.marker || thematicBreakRe.MatchString(lines[i]) {
+			break
+		}
+
+		content := []string{item.first}
+		i++
+		for i < len(lines) {
+			line := lines[i]
+			switch {
+			case isBlank(line):
+				content = append(content, "")
+			case indentOf(line) >= item.width:
+				content = append(content, removeIndent(line, item.width))
+			case !isBlank(content[len(content)-1]) && !startsBlock(line):
+				// Lazy continuation của paragraph trong item
+				content = append(content, line)
+			default:
+				goto done
+			}
+			i++
+		}
+	done:
+		trailing := 0
+		for len(content) > 0 && isBlank(content[len(content)-1]) {
+			content = content[:len(content)-1]
+			trailing++
+		}
+		children := p.parse(content, depth+1)
+		if len(children) > 1 && containsBlank(content) {
+			list.tight = false
+		}
+		list.children = append(list.children, &block{kind: itemBlock, children: children})
+
+		if trailing > 0 {
+			next, ok := listMarker(lineAt(lines, i))
+			if !ok || next.ordered != first.ordered || next.marker != first.marker {
+				break
+			}
+			list.tight = false
+		}
+	}
+	return list, i
+}
+
+func lineAt(lines []string, i int) string {
+	if i < len(lines) {
+		return lines[i]
+	}
+	return ""
+}
+
+func containsBlank(lines []string) bool {
+	for _, line := range lines {
+		if isBlank(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// startsBlock cho biết dòng mở đầu một block mới (ngắt lazy continuation/bảng)
+func startsBlock(line string) bool {
+	if atxHeadingRe.MatchString(line) || fenceOpenRe.MatchString(line) || thematicBreakRe.MatchString(line) {
+		return true
+	}
+	if _, ok := quoteLine(line); ok {
+		return true
+	}
+	item, ok := listMarker(line)
+	return ok && !item.empty
+}
+
+func quoteLine(line string) (string, bool) {
+	indent := indentOf(line)
+	if indent > 3 || indent >= len(line) || line[indent] != '>' {
+		return "", false
+	}
+	rest := line[indent+1:]
+	return strings.TrimPrefix(rest, " "), true
+}
+
+// headingText bỏ chuỗi # đóng của ATX heading
+func headingText(text string) string {
+	text = strings.TrimSpace(text)
+	trimmed := strings.TrimRight(text, "#")
+	if trimmed == "" {
+		return ""
+	}
+	if strings.HasSuffix(trimmed, " ") {
+		return strings.TrimSpace(trimmed)
+	}
+	return text
+}
+
+type listItemStart struct {
+	ordered bool
+	marker  byte // ký tự bullet, hoặc '.' / ')' với list có thứ tự
+	start   int
+	width   int // số cột nội dung của item thụt vào
+	first   string
+	empty   bool
+}
+
+// canInterrupt: item rỗng hoặc list có thứ tự không bắt đầu từ 1 không được cắt ngang paragraph
+func (l listItemStart) canInterrupt() bool {
+	return !l.empty && (!l.ordered || l.start == 1)
+}
+
+func listMarker(line string) (listItemStart, bool) {
+	m := listMarkerRe.FindStringSubmatch(line)
+	if m == nil {
+		return listItemStart{}, false
+	}
+	marker, spaces, rest := m[2], len(m[3]), m[4]
+	if rest != "" && spaces == 0 {
+		return listItemStart{}, false
+	}
+
+	item := listItemStart{marker: marker[len(marker)-1], first: rest}
+	if len(marker) > 1 || (marker[0] >= '0' && marker[0] <= '9') {
+		item.ordered = true
+		item.start, _ = strconv.Atoi(marker[:len(marker)-1])
+	}
+	item.width = len(m[1]) + len(marker)
+	switch {
+	case rest == "":
+		item.empty = true
+		item.width++
+	case spaces > 4:
+		// Nội dung thụt sâu là indented code bên trong item
+		item.width++
+		item.first = strings.Repeat(" ", spaces-1) + rest
+	default:
+		item.width += spaces
+	}
+	return item, true
+}
+
+// tableDelimiter kiểm tra lines[i] là header và lines[i+1] là dòng phân cách của bảng
+func tableDelimiter(lines []string, i int) ([]string, bool) {
+	if i+1 >= len(lines) || !strings.Contains(lines[i], "|") || !strings.Contains(lines[i+1], "|") {
+		return nil, false
+	}
+	cells := splitRow(lines[i+1])
+	align := make([]string, len(cells))
+	for k, cell := range cells {
+		if !tableDelimRe.MatchString(cell) {
+			return nil, false
+		}
+		switch {
+		case strings.HasPrefix(cell, ":") && strings.HasSuffix(cell, ":"):
+			align[k] = "center"
+		case strings.HasPrefix(cell, ":"):
+			align[k] = "left"
+		case strings.HasSuffix(cell, ":"):
+			align[k] = "right"
+		}
+	}
+	if len(splitRow(lines[i])) != len(align) {
+		return nil, false
+	}
+	return align, true
+}
+
+func splitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// fitRow cắt/thêm ô cho đủ số cột của header
+func fitRow(cells []string, n int) []string {
+	for len(cells) < n {
+		cells = append(cells, "")
+	}
+	return cells[:n]
+}
+
+// extractRefs lấy các link reference definition ở đầu paragraph, trả về phần còn lại
+func (p *blockParser) extractRefs(lines []string) []string {
+	for len(lines) > 0 {
+		m := linkRefDefRe.FindStringSubmatch(lines[0])
+		if m == nil || strings.TrimSpace(m[1]) == "" {
+			break
+		}
+		label := normalizeLabel(m[1])
+		if _, exists := p.refs[label]; !exists {
+			dest := m[2]
+			if strings.HasPrefix(dest, "<") {
+				dest = dest[1 : len(dest)-1]
+			}
+			title := ""
+			if len(m[3]) >= 2 {
+				title = unescapeString(m[3][1 : len(m[3])-1])
+			}
+			p.refs[label] = linkRef{dest: normalizeURL(unescapeString(dest)), title: title}
+		}
+		lines = lines[1:]
+	}
+	return lines
+}
+
+// normalizeLabel so khớp label không phân biệt hoa thường và khoảng trắng
+func normalizeLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+type renderer struct {
+	refs     map[string]linkRef
+	mentions *mentionSet
+}
+
+func (r *renderer) blocks(b *strings.Builder, blocks []*block, tight bool) {
+	for _, blk := range blocks {
+		r.block(b, blk, tight)
+	}
+}
+
+func (r *renderer) block(b *strings.Builder, blk *block, tight bool) {
+	switch blk.kind {
+	case paragraphBlock:
+		text := renderInline(paragraphText(blk.lines), r.refs, r.mentions)
+		if tight {
+			b.WriteString(text)
+			return
+		}
+		b.WriteString("<p>" + text + "</p>\n")
+	case headingBlock:
+		tag := "h" + strconv.Itoa(blk.level)
+		b.WriteString("<" + tag + ">" + renderInline(paragraphText(blk.lines), r.refs, r.mentions) + "</" + tag + ">\n")
+	case thematicBreakBlock:
+		b.WriteString("<hr />\n")
+	case codeBlock:
+		b.WriteString("<pre><code")
+		if blk.lang != "" {
+			b.WriteString(` class="language-` + escapeAttr(blk.lang) + `"`)
+		}
+		b.WriteString(">")
+		for _, line := range blk.lines {
+			b.WriteString(escapeText(line) + "\n")
+		}
+		b.WriteString("</code></pre>\n")
+	case htmlBlock:
+		b.WriteString(strings.Join(blk.lines, "\n") + "\n")
+	case quoteBlock:
+		b.WriteString("<blockquote>\n")
+		r.blocks(b, blk.children, false)
+		b.WriteString("</blockquote>\n")
+	case listBlock:
+		tag := "ul"
+		if blk.ordered {
+			tag = "ol"
+		}
+		b.WriteString("<" + tag)
+		if blk.ordered && blk.start != 1 {
+			b.WriteString(` start="` + strconv.Itoa(blk.start) + `"`)
+		}
+		b.WriteString(">\n")
+		for _, item := range blk.children {
+			r.item(b, item, blk.tight)
+		}
+		b.WriteString("</" + tag + ">\n")
+	case tableBlock:
+		r.table(b, blk)
+	}
+}
+
+func (r *renderer) item(b *strings.Builder, item *block, tight bool) {
+	b.WriteString("<li>")
+	for i, child := range item.children {
+		if tight && child.kind == paragraphBlock {
+			r.block(b, child, true)
+			if i < len(item.children)-1 {
+				b.WriteString("\n")
+			}
+			continue
+		}
+		if i == 0 {
+			b.WriteString("\n")
+		}
+		r.block(b, child, false)
+	}
+	b.WriteString("</li>\n")
+}
+
+func (r *renderer) table(b *strings.Builder, blk *block) {
+	row := func(cells []string, tag string) {
+		b.WriteString("<tr>\n")
+		for i, cell := range cells {
+			b.WriteString("<" + tag)
+			if blk.align[i] != "" {
+				b.WriteString(` align="` + blk.align[i] + `"`)
+			}
+			b.WriteString(">" + renderInline(cell, r.refs, r.mentions) + "</" + tag + ">\n")
+		}
+		b.WriteString("</tr>\n")
+	}
+
+	b.WriteString("<table>\n<thead>\n")
+	row(blk.rows[0], "th")
+	b.WriteString("</thead>\n")
+	if len(blk.rows) > 1 {
+		b.WriteString("<tbody>\n")
+		for _, cells := range blk.rows[1:] {
+			row(cells, "td")
+		}
+		b.WriteString("</tbody>\n")
+	}
+	b.WriteString("</table>\n")
+}
+
+// paragraphText bỏ khoảng trắng đầu mỗi dòng và cuối đoạn
+func paragraphText(lines []string) string {
+	trimmed := make([]string, len(lines))
+	for i, line := range lines {
+		trimmed[i] = strings.TrimLeft(line, " ")
+	}
+	return strings.TrimRight(strings.Join(trimmed, "\n"), " ")
+}
@@ -0,0 +1,100 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender_Blocks(t *testing.T) {
+	tests := []struct {
+		name, source, want string
+	}{
+		{"paragraph", "Hello\nworld", "<p>Hello\nworld</p>\n"},
+		{"atx heading", "## Tiêu đề ##", `<h2 id="tiêu-đề">Tiêu đề</h2>` + "\n"},
+		{"setext heading", "Title\n=====", `<h1 id="title">Title</h1>` + "\n"},
+		{"thematic break", "* * *", "<hr />\n"},
+		{"blockquote", "> quoted\nlazy", "<blockquote>\n<p>quoted\nlazy</p>\n</blockquote>\n"},
+		{"tight list", "- a\n- b\n  - c", "<ul>\n<li>a</li>\n<li>b\n<ul>\n<li>c</li>\n</ul>\n</li>\n</ul>\n"},
+		{"loose list", "1. a\n\n2. b", "<ol>\n<li>\n<p>a</p>\n</li>\n<li>\n<p>b</p>\n</li>\n</ol>\n"},
+		{"ordered start", "3) x", "<ol start=\"3\">\n<li>x</li>\n</ol>\n"},
+		{"indented code", "    a < b\n\tc", "<pre><code>a &lt; b\nc\n</code></pre>\n"},
+		{"fenced code keeps tabs", "```go\nfunc f() {\n\treturn\n}\n```", "<pre><code class=\"language-go\">func f() {\n\treturn\n}\n</code></pre>\n"},
+		{"unclosed fence", "~~~\ncode", "<pre><code>code\n</code></pre>\n"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Render(tt.source), tt.name)
+	}
+}
+
+func TestRender_Table(t *testing.T) {
+	html := Render("| Tên | Số |\n|:---|---:|\n| a \\| b | `1` |\n| c |")
+
+	assert.Equal(t, "<table>\n<thead>\n<tr>\n<th align=\"left\">Tên</th>\n<th align=\"right\">Số</th>\n</tr>\n</thead>\n"+
+		"<tbody>\n<tr>\n<td align=\"left\">a | b</td>\n<td align=\"right\"><code>1</code></td>\n</tr>\n"+
+		"<tr>\n<td align=\"left\">c</td>\n<td align=\"right\"></td>\n</tr>\n</tbody>\n</table>\n", html)
+}
+
+func TestRender_Inline(t *testing.T) {
+	tests := []struct {
+		source, want string
+	}{
+		{"*em* **strong** ***both***", "<em>em</em> <strong>strong</strong> <em><strong>both</strong></em>"},
+		{"snake_case_name and a * b", "snake_case_name and a * b"},
+		{"`a <b>` and ``x ` y``", "<code>a &lt;b&gt;</code> and <code>x ` y</code>"},
+		{`\*not em\* &copy; & <`, "*not em* © &amp; &lt;"},
+		{"line  \nbreak\\\nagain", "line<br />\nbreak<br />\nagain"},
+		{`[go](https://go.dev "Go")`, `<a href="https://go.dev" title="Go" rel="nofollow noopener noreferrer">go</a>`},
+		{"![logo *x*](/logo.png)", `<img src="/logo.png" alt="logo x" />`},
+		{"<https://example.com> <me@example.com>", `<a href="https://example.com" rel="nofollow noopener noreferrer">https://example.com</a> <a href="mailto:me@example.com" rel="nofollow noopener noreferrer">me@example.com</a>`},
+		{"[a [b](/b)](/a)", `[a <a href="/b" rel="nofollow noopener noreferrer">b</a>](/a)`},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, "<p>"+tt.want+"</p>\n", Render(tt.source), tt.source)
+	}
+}
+
+func TestRender_ReferenceLinks(t *testing.T) {
+	html := Render("[Docs][d], [d][] and [D]\n\n[d]: <https://example.com/a b> 'Tài liệu'")
+
+	link := `<a href="https://example.com/a%20b" title="Tài liệu" rel="nofollow noopener noreferrer">`
+	assert.Equal(t, "<p>"+link+"Docs</a>, "+link+"d</a> and "+link+"D</a></p>\n", html)
+}
+
+func TestRender_HeadingAnchorsAreUnique(t *testing.T) {
+	html := Render("# Intro\n\n## Intro\n\n### Intro 1\n\n#### ***\n")
+
+	assert.Contains(t, html, `<h1 id="intro">`)
+	assert.Contains(t, html, `<h2 id="intro-1">`)
+	assert.Contains(t, html, `<h3 id="intro-1-1">`)
+	assert.Contains(t, html, `<h4 id="section">`)
+}
+
+func TestRender_DeepNestingDoesNotRecurseForever(t *testing.T) {
+	source := ""
+	for i := 0; i < 1000; i++ {
+		source += ">"
+	}
+	assert.NotPanics(t, func() { Render(source + " x") })
+}
+
+func TestMentions(t *testing.T) {
+	source := "Hi @Alice and @bob_99, cc @alice.\n\n" +
+		"`@code` me@example.com https://x.dev/@carol \\@dave @ab @toolongtoolongtoolongtoolongtoolong\n\n" +
+		"```\n@eve\n```\n\n- (@frank)"
+
+	assert.Equal(t, []string{"alice", "bob_99", "frank"}, Mentions(source))
+	assert.Empty(t, Mentions("no mentions here"))
+}
+
+func TestRenderWithMentions(t *testing.T) {
+	links := map[string]string{"alice": "/api/users/1"}
+
+	html := RenderWithMentions("Hi @Alice, @bob and [@alice](/x) `@alice`", links)
+
+	assert.Contains(t, html, `Hi <a href="/api/users/1"`)
+	assert.Contains(t, html, `>@Alice</a>, @bob and `)
+	assert.NotContains(t, html, `<a href="/x" rel="nofollow noopener noreferrer"><a`, "mention in link text is not linked")
+	assert.Contains(t, html, `<code>@alice</code>`)
+	assert.Equal(t, Render("Hi @Alice"), RenderWithMentions("Hi @Alice", nil))
+}
@@ -0,0 +1,270 @@
+package markdown
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// allowedTags là allowlist thẻ và thuộc tính được giữ lại; mọi thứ khác bị bỏ
+var allowedTags = map[string][]string{
+	"p": nil, "br": nil, "hr": nil,
+	"h1": nil, "h2": nil, "h3": nil, "h4": nil, "h5": nil, "h6": nil,
+	"blockquote": nil, "pre": nil, "code": {"class"},
+	"em": nil, "strong": nil, "del": nil, "s": nil, "sup": nil, "sub": nil, "kbd": nil,
+	"a":   {"href", "title"},
+	"img": {"src", "alt", "title"},
+	"ul":  nil, "ol": {"start"}, "li": nil,
+	"table": nil, "thead": nil, "tbody": nil, "tr": nil, "th": {"align"}, "td": {"align"},
+}
+
+var voidTags = map[string]bool{"br": true, "hr": true, "img": true}
+
+// droppedTags bị bỏ cả nội dung bên trong, không chỉ thẻ
+var droppedTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true, "noscript": true,
+	"template": true, "textarea": true, "title": true, "svg": true, "math": true, "select": true,
+	"noembed": true, "noframes": true, "frameset": true, "xmp": true, "plaintext": true, "head": true,
+}
+
+var languageClassRe = regexp.MustCompile(`^language-[A-Za-z0-9_+#.-]+$`)
+
+// Sanitize lọc HTML theo allowlist: chỉ giữ thẻ/thuộc tính an toàn, chỉ cho URL
+// http(s)/mailto (ảnh chỉ http(s)) hoặc tương đối, và đóng lại các thẻ còn mở.
+// Nội dung chữ của thẻ bị loại vẫn được giữ (đã escape).
+func Sanitize(src string) string {
+	z := html.NewTokenizer(strings.NewReader(src))
+	var b strings.Builder
+	var open []string
+	skipTag, skipDepth := "", 0
+
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			for i := len(open) - 1; i >= 0; i-- {
+				b.WriteString("</" + open[i] + ">")
+			}
+			return b.String()
+
+		case html.TextToken:
+			if skipDepth == 0 {
+				b.WriteString(escapeText(string(z.Text())))
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			name := tok.Data
+			if skipDepth > 0 {
+				if name == skipTag && tt == html.StartTagToken {
+					skipDepth++
+				}
+				continue
+			}
+			if droppedTags[name] {
+				if tt == html.StartTagToken {
+					skipTag, skipDepth = name, 1
+				}
+				continue
+			}
+			attrs, ok := allowedTags[name]
+			if !ok {
+				continue
+			}
+			tag, ok := sanitizeTag(name, tok.Attr, attrs)
+			if !ok {
+				continue
+			}
+			b.WriteString(tag)
+			if voidTags[name] {
+				continue
+			}
+			if tt == html.SelfClosingTagToken {
+				b.WriteString("</" + name + ">")
+			} else {
+				open = append(open, name)
+			}
+
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+			if skipDepth > 0 {
+				if tag == skipTag {
+					skipDepth--
+				}
+				continue
+			}
+			// Đóng cả các thẻ con còn mở để HTML luôn cân bằng
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != tag {
+					continue
+				}
+				for j := len(open) - 1; j >= i; j-- {
+					b.WriteString("</" + open[j] + ">")
+				}
+				open = open[:i]
+				break
+			}
+		}
+		// Comment và doctype bị bỏ qua
+	}
+}
+
+func sanitizeTag(name string, attrs []html.Attribute, allowed []string) (string, bool) {
+	var b strings.Builder
+	b.WriteString("<" + name)
+	seen := map[string]bool{}
+	for _, attr := range attrs {
+		if attr.Namespace != "" || seen[attr.Key] || !containsString(allowed, attr.Key) {
+			continue
+		}
+		value, ok := sanitizeAttr(attr.Key, attr.Val)
+		if !ok {
+			continue
+		}
+		seen[attr.Key] = true
+		b.WriteString(" " + attr.Key + `="` + escapeAttr(value) + `"`)
+	}
+	if name == "img" && !seen["src"] {
+		return "", false
+	}
+	if name == "a" && seen["href"] {
+		b.WriteString(` rel="nofollow noopener noreferrer"`)
+	}
+	if voidTags[name] {
+		b.WriteString(" />")
+	} else {
+		b.WriteString(">")
+	}
+	return b.String(), true
+}
+
+func sanitizeAttr(key, value string) (string, bool) {
+	switch key {
+	case "href":
+		return safeURL(value, "http", "https", "mailto")
+	case "src":
+		return safeURL(value, "http", "https")
+	case "class":
+		return value, languageClassRe.MatchString(value)
+	case "align":
+		return value, value == "left" || value == "center" || value == "right"
+	case "start":
+		n, err := strconv.Atoi(value)
+		return value, err == nil && n >= 0 && len(value) <= 9
+	}
+	return value, true
+}
+
+// safeURL chỉ nhận URL tương đối hoặc có scheme trong danh sách.
+// Khoảng trắng và ký tự điều khiển bị bỏ trước vì trình duyệt cũng bỏ qua chúng
+// (ví dụ "java\tscript:" vẫn chạy như javascript:).
+func safeURL(raw string, schemes ...string) (string, bool) {
+	cleaned := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f || unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, raw)
+	u, err := url.Parse(cleaned)
+	if err != nil {
+		return "", false
+	}
+	if u.Scheme == "" {
+		return cleaned, true
+	}
+	return cleaned, containsString(schemes, strings.ToLower(u.Scheme))
+}
+
+// addHeadingAnchors gắn id (sinh từ nội dung chữ) cho các heading trong HTML đã lọc
+func addHeadingAnchors(src string) string {
+	z := html.NewTokenizer(strings.NewReader(src))
+	var b, inner, text strings.Builder
+	used := map[string]bool{}
+	heading, depth := "", 0
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return b.String()
+		}
+		raw := string(z.Raw())
+		name, _ := z.TagName()
+		tag := string(name)
+
+		switch {
+		case heading == "" && tt == html.StartTagToken && isHeading(tag):
+			heading, depth = tag, 1
+			inner.Reset()
+			text.Reset()
+		case heading == "":
+			b.WriteString(raw)
+		case tt == html.EndTagToken && tag == heading && depth == 1:
+			id := uniqueAnchor(anchorSlug(text.String()), used)
+			b.WriteString("<" + heading + ` id="` + escapeAttr(id) + `">` + inner.String() + raw)
+			heading = ""
+		default:
+			if tag == heading {
+				if tt == html.StartTagToken {
+					depth++
+				} else if tt == html.EndTagToken {
+					depth--
+				}
+			}
+			if tt == html.TextToken {
+				text.WriteString(html.UnescapeString(raw))
+			}
+			inner.WriteString(raw)
+		}
+	}
+}
+
+func isHeading(tag string) bool {
+	return len(tag) == 2 && tag[0] == 'h' && tag[1] >= '1' && tag[1] <= '6'
+}
+
+// anchorSlug giữ chữ (kể cả tiếng Việt có dấu) và số, nối các từ bằng "-"
+func anchorSlug(text string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsNumber(r) || r == '_':
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(r)
+		case unicode.Is(unicode.Mn, r):
+			b.WriteRune(r)
+		case unicode.IsSpace(r) || r == '-':
+			dash = true
+		}
+	}
+	if b.Len() == 0 {
+		return "section"
+	}
+	return b.String()
+}
+
+func uniqueAnchor(slug string, used map[string]bool) string {
+	id := slug
+	for n := 1; used[id]; n++ {
+		id = slug + "-" + strconv.Itoa(n)
+	}
+	used[id] = true
+	return id
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
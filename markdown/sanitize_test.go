@@ -0,0 +1,40 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitize_RemovesScriptsAndHandlers(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{`<script>alert(1)</script>ok`, "ok"},
+		{`<img src="/a.png" onerror="alert(1)">`, `<img src="/a.png" />`},
+		{`<img src="javascript:alert(1)">`, ""},
+		{`<img src="data:image/svg+xml;base64,PHN2Zz4=">`, ""},
+		{`<a href="javascript:alert(1)">x</a>`, "<a>x</a>"},
+		{`<a href="jav&#x09;ascript:alert(1)">x</a>`, "<a>x</a>"},
+		{`<a href=" JAVASCRIPT:alert(1)">x</a>`, "<a>x</a>"},
+		{`<a href="/posts/1" target="_blank">x</a>`, `<a href="/posts/1" rel="nofollow noopener noreferrer">x</a>`},
+		{`<div style="x" onclick="y"><b>bold</b></div>`, "bold"},
+		{`<svg><g><script>alert(1)</script></g><svg></svg></svg>after`, "after"},
+		{`<iframe src="https://evil"></iframe><style>*{}</style>`, ""},
+		{`<!-- comment --><p>text`, "<p>text</p>"},
+		{`<p><em>open</p>`, "<p><em>open</em></p>"},
+		{`<code class="language-go" id="x">c</code><code class="x y">d</code>`, `<code class="language-go">c</code><code>d</code>`},
+		{`<td align="center">a</td><th align="javascript">b</th>`, `<td align="center">a</td><th>b</th>`},
+		{`&lt;script&gt; &amp; "quotes"`, `&lt;script&gt; &amp; "quotes"`},
+		{`<h2 id="evil" onclick="x">t</h2>`, "<h2>t</h2>"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Sanitize(tt.input), tt.input)
+	}
+}
+
+func TestRender_SanitizesRawHTML(t *testing.T) {
+	html := Render("Hi <span onmouseover=\"x\">there</span>\n\n<script>\nalert(1)\n</script>\n\n[x](javascript:alert(1))")
+
+	assert.Equal(t, "<p>Hi there</p>\n\n<p><a>x</a></p>\n", html)
+}
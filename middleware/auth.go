@@ -3,15 +3,21 @@ package middleware
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 
 	"myapp/config"
+	"myapp/database"
+	"myapp/models"
 )
 
 var jwtSecret = []byte(config.GetEnv("JWT_SECRET", "my_secret_key"))
 
+// CurrentUserKey là key lưu user đã xác thực trong gin.Context
+const CurrentUserKey = "currentUser"
+
 func AuthRequired() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -42,7 +48,70 @@ func AuthRequired() gin.HandlerFunc {
 			return
 		}
 
-		// ✅ Nếu hợp lệ → tiếp tục request
+		claims, _ := token.Claims.(jwt.MapClaims)
+		userID, ok := claims["user_id"].(float64)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			c.Abort()
+			return
+		}
+
+		// Lấy user từ DB để biết chính xác ai đang gọi API
+		var user models.User
+		if err := database.DB.First(&user, uint(userID)).Error; err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+			c.Abort()
+			return
+		}
+
+		// Token phát hành trước lần đổi mật khẩu gần nhất sẽ bị thu hồi
+		version, _ := claims["ver"].(float64)
+		if uint(version) != user.TokenVersion {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+			c.Abort()
+			return
+		}
+
+		// Tài khoản bị moderator khóa không gọi được API cần đăng nhập
+		if user.IsSuspended(time.Now()) {
+			c.JSON(http.StatusForbidden, gin.H{"error": SuspendedMessage(&user)})
+			c.Abort()
+			return
+		}
+
+		// ✅ Nếu hợp lệ → lưu user vào context và tiếp tục request
+		c.Set(CurrentUserKey, &user)
+		c.Next()
+	}
+}
+
+// AdminRequired chỉ cho phép admin đi tiếp, phải đặt sau AuthRequired
+func AdminRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := CurrentUser(c)
+		if !ok || !user.IsAdmin() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Admin permission required"})
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
+
+// SuspendedMessage là thông báo lỗi cho tài khoản đang bị khóa
+func SuspendedMessage(user *models.User) string {
+	if user.SuspendedUntil == nil {
+		return "Account is suspended"
+	}
+	return "Account is suspended until " + user.SuspendedUntil.UTC().Format(time.RFC3339)
+}
+
+// CurrentUser trả về user đã được AuthRequired xác thực
+func CurrentUser(c *gin.Context) (*models.User, bool) {
+	value, exists := c.Get(CurrentUserKey)
+	if !exists {
+		return nil, false
+	}
+	user, ok := value.(*models.User)
+	return user, ok
+}
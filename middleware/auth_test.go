@@ -7,11 +7,32 @@ import (
 	"testing"
 	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"myapp/database"
+	"myapp/models"
 )
 
+// setupAuthTestDB gán mock database cho database.DB
+func setupAuthTestDB(t *testing.T) sqlmock.Sqlmock {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	assert.NoError(t, err)
+
+	database.DB = gormDB
+	return mock
+}
+
 func TestAuthRequired_ValidToken(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
@@ -245,3 +266,145 @@ func TestAuthRequired_TokenWithoutExpiration(t *testing.T) {
 	// Assert - token không có expiration vẫn valid
 	assert.Equal(t, http.StatusOK, w.Code)
 }
+
+func TestAuthRequired_SetsCurrentUser(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mock := setupAuthTestDB(t)
+
+	mock.ExpectQuery("SELECT \\* FROM `users` WHERE `users`.`id` = \\?").
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email", "token_version"}).
+			AddRow(1, "John Doe", "john@example.com", 2))
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": 1,
+		"ver":     2,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	})
+	tokenString, _ := token.SignedString(jwtSecret)
+
+	req, _ := http.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+
+	w := httptest.NewRecorder()
+	router := gin.New()
+	router.Use(AuthRequired())
+	router.GET("/protected", func(c *gin.Context) {
+		user, ok := CurrentUser(c)
+		assert.True(t, ok)
+		c.JSON(http.StatusOK, gin.H{"email": user.Email})
+	})
+
+	// Execute
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "john@example.com")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuthRequired_RevokedToken(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mock := setupAuthTestDB(t)
+
+	// User đã đổi mật khẩu nên token_version = 1
+	mock.ExpectQuery("SELECT \\* FROM `users` WHERE `users`.`id` = \\?").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "token_version"}).
+			AddRow(1, "john@example.com", 1))
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": 1,
+		"ver":     0,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	})
+	tokenString, _ := token.SignedString(jwtSecret)
+
+	req, _ := http.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+
+	w := httptest.NewRecorder()
+	router := gin.New()
+	router.Use(AuthRequired())
+	router.GET("/protected", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	// Execute
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "Token has been revoked")
+}
+
+func TestAuthRequired_SuspendedUser(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mock := setupAuthTestDB(t)
+
+	until := time.Now().Add(48 * time.Hour)
+	mock.ExpectQuery("SELECT \\* FROM `users` WHERE `users`.`id` = \\?").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "token_version", "suspended_at", "suspended_until"}).
+			AddRow(1, "john@example.com", 0, time.Now(), until))
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": 1,
+		"ver":     0,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	})
+	tokenString, _ := token.SignedString(jwtSecret)
+
+	req, _ := http.NewRequest("GET", "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+tokenString)
+
+	w := httptest.NewRecorder()
+	router := gin.New()
+	router.Use(AuthRequired())
+	router.GET("/protected", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	// Execute
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "Account is suspended until "+until.UTC().Format(time.RFC3339))
+}
+
+func TestAdminRequired(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+
+	cases := map[string]struct {
+		user *models.User
+		code int
+	}{
+		"admin":     {&models.User{ID: 1, Role: models.RoleAdmin}, http.StatusOK},
+		"normal":    {&models.User{ID: 2, Role: models.RoleUser}, http.StatusForbidden},
+		"anonymous": {nil, http.StatusForbidden},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/admin", func(c *gin.Context) {
+				if tc.user != nil {
+					c.Set(CurrentUserKey, tc.user)
+				}
+				c.Next()
+			}, AdminRequired(), func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"message": "success"})
+			})
+
+			req, _ := http.NewRequest("GET", "/admin", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.code, w.Code)
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"myapp/config"
+	"myapp/database"
+	"myapp/models"
+)
+
+// IdempotencyHeader là header client gửi để đánh dấu request có thể retry an toàn
+const IdempotencyHeader = "Idempotency-Key"
+
+// Giới hạn của request/response được lưu cho Idempotency-Key
+const (
+	maxIdempotentRequestBody  = 1 << 20 // body lớn hơn trả 413
+	maxIdempotentResponseBody = 1 << 20 // response lớn hơn không được lưu để trả lại
+)
+
+// unreplayedHeaders là các header không lưu lại: Content-Type lưu riêng, còn lại do server tự đặt
+var unreplayedHeaders = map[string]bool{
+	"Content-Type":        true,
+	"Content-Length":      true,
+	"Date":                true,
+	"Set-Cookie":          true,
+	"Idempotent-Replayed": true,
+}
+
+// idempotencyTTL là thời gian lưu key, cấu hình qua IDEMPOTENCY_TTL (vd: 24h)
+func idempotencyTTL() time.Duration {
+	ttl, err := time.ParseDuration(config.GetEnv("IDEMPOTENCY_TTL", "24h"))
+	if err != nil || ttl <= 0 {
+		return 24 * time.Hour
+	}
+	return ttl
+}
+
+// Idempotency lưu response (status, header, body) của các request POST có header Idempotency-Key
+// và trả lại đúng response đó khi client retry với cùng key và cùng body.
+// Gắn vào từng route POST nhận JSON, sau AuthRequired; không dùng cho route upload file.
+func Idempotency() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyHeader)
+		if key == "" || c.Request.Method != http.MethodPost {
+			c.Next()
+			return
+		}
+		if len(key) > 255 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must be at most 255 characters"})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentRequestBody))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("request body must be at most %d bytes", maxIdempotentRequestBody)})
+			} else {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			}
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		// Scope gồm method + path + user để key của user này không đụng user khác
+		principal := ""
+		if user, ok := CurrentUser(c); ok {
+			principal = fmt.Sprintf("user:%d", user.ID)
+		}
+		scope := hashParts(c.Request.Method, c.Request.URL.Path, principal)
+		fingerprint := hashParts(string(body))
+
+		record, claimed, err := claimIdempotencyKey(key, scope, fingerprint)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		if !claimed {
+			switch {
+			case record.Fingerprint != fingerprint:
+				c.JSON(http.StatusConflict, gin.H{"error": "Idempotency-Key was already used with a different request"})
+			case record.Status != models.IdempotencyCompleted:
+				c.Header("Retry-After", "1")
+				c.JSON(http.StatusConflict, gin.H{"error": "A request with this Idempotency-Key is still being processed"})
+			default:
+				replayHeaders(c, record.ResponseHeaders)
+				c.Header("Idempotent-Replayed", "true")
+				c.Data(record.ResponseStatus, record.ResponseContentType, record.ResponseBody)
+			}
+			c.Abort()
+			return
+		}
+
+		recorder := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		finished := false
+		defer func() {
+			// Handler panic: giải phóng key để client có thể retry
+			if !finished {
+				releaseIdempotencyKey(record.ID)
+			}
+		}()
+
+		c.Next()
+		finished = true
+
+		// Lỗi phía server không được lưu lại để lần retry sau được xử lý lại
+		if recorder.Status() >= http.StatusInternalServerError {
+			releaseIdempotencyKey(record.ID)
+			return
+		}
+		if recorder.overflow {
+			log.Printf("❌ Response quá %d byte, không lưu cho Idempotency-Key", maxIdempotentResponseBody)
+			releaseIdempotencyKey(record.ID)
+			return
+		}
+		headers, err := savedHeaders(recorder.Header())
+		if err != nil {
+			log.Printf("❌ Không lưu được response cho Idempotency-Key: %v", err)
+			releaseIdempotencyKey(record.ID)
+			return
+		}
+		if err := database.DB.Model(&models.IdempotencyKey{}).Where("id = ?", record.ID).Updates(map[string]interface{}{
+			"status":                models.IdempotencyCompleted,
+			"response_status":       recorder.Status(),
+			"response_content_type": recorder.Header().Get("Content-Type"),
+			"response_headers":      headers,
+			"response_body":         recorder.body.Bytes(),
+		}).Error; err != nil {
+			log.Printf("❌ Không lưu được response cho Idempotency-Key: %v", err)
+		}
+	}
+}
+
+// claimIdempotencyKey cố gắng chiếm key (dựa vào unique index nên an toàn khi có request song song).
+// Trả về claimed = false kèm bản ghi hiện có nếu key đã được request khác chiếm.
+func claimIdempotencyKey(key, scope, fingerprint string) (*models.IdempotencyKey, bool, error) {
+	now := time.Now()
+	for attempt := 0; attempt < 3; attempt++ {
+		record := models.IdempotencyKey{
+			Key:         key,
+			Scope:       scope,
+			Fingerprint: fingerprint,
+			Status:      models.IdempotencyProcessing,
+			ExpiresAt:   now.Add(idempotencyTTL()),
+		}
+		result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+		if result.Error != nil {
+			return nil, false, result.Error
+		}
+		if result.RowsAffected == 1 {
+			return &record, true, nil
+		}
+
+		var existing models.IdempotencyKey
+		err := database.DB.Where("idem_key = ? AND scope = ?", key, scope).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Key vừa được giải phóng, thử chiếm lại
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		if !existing.IsExpired(now) {
+			return &existing, false, nil
+		}
+
+		// Key đã hết hạn thì coi như chưa dùng
+		if err := database.DB.Where("id = ? AND expires_at <= ?", existing.ID, now).
+			Delete(&models.IdempotencyKey{}).Error; err != nil {
+			return nil, false, err
+		}
+	}
+	return nil, false, errors.New("could not claim Idempotency-Key, please retry")
+}
+
+func releaseIdempotencyKey(id uint) {
+	if err := database.DB.Delete(&models.IdempotencyKey{}, id).Error; err != nil {
+		log.Printf("❌ Không giải phóng được Idempotency-Key: %v", err)
+	}
+}
+
+// PurgeExpiredIdempotencyKeys xóa các key đã hết hạn
+func PurgeExpiredIdempotencyKeys() (int64, error) {
+	result := database.DB.Where("expires_at <= ?", time.Now()).Delete(&models.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
+
+// StartIdempotencyCleanup chạy nền việc dọn các key hết hạn theo chu kỳ
+func StartIdempotencyCleanup(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if n, err := PurgeExpiredIdempotencyKeys(); err != nil {
+				log.Printf("❌ Dọn Idempotency-Key thất bại: %v", err)
+			} else if n > 0 {
+				log.Printf("🧹 Đã xóa %d Idempotency-Key hết hạn", n)
+			}
+		}
+	}()
+}
+
+// savedHeaders trả về JSON các header của response cần trả lại khi replay
+func savedHeaders(header http.Header) (string, error) {
+	saved := http.Header{}
+	for name, values := range header {
+		if !unreplayedHeaders[name] {
+			saved[name] = values
+		}
+	}
+	data, err := json.Marshal(saved)
+	return string(data), err
+}
+
+// replayHeaders đặt lại các header đã lưu (dòng cũ chưa có cột này thì bỏ qua)
+func replayHeaders(c *gin.Context, saved string) {
+	if saved == "" {
+		return
+	}
+	var header http.Header
+	if err := json.Unmarshal([]byte(saved), &header); err != nil {
+		log.Printf("❌ Header đã lưu của Idempotency-Key không hợp lệ: %v", err)
+		return
+	}
+	for name, values := range header {
+		for _, value := range values {
+			c.Writer.Header().Add(name, value)
+		}
+	}
+}
+
+func hashParts(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// bodyRecorder ghi lại body của response (tối đa maxIdempotentResponseBody byte)
+// trong khi vẫn gửi cho client
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (r *bodyRecorder) record(b []byte) {
+	if r.overflow || r.body.Len()+len(b) > maxIdempotentResponseBody {
+		r.overflow = true
+		r.body.Reset()
+		return
+	}
+	r.body.Write(b)
+}
+
+func (r *bodyRecorder) Write(b []byte) (int, error) {
+	r.record(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *bodyRecorder) WriteString(s string) (int, error) {
+	r.record([]byte(s))
+	return r.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newIdempotencyRouter(calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Idempotency())
+	router.POST("/items", func(c *gin.Context) {
+		*calls++
+		c.Header("Location", "/items/1")
+		c.JSON(http.StatusCreated, gin.H{"id": 1})
+	})
+	router.GET("/items", func(c *gin.Context) {
+		*calls++
+		c.JSON(http.StatusOK, gin.H{})
+	})
+	return router
+}
+
+func postWithKey(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(http.MethodPost, "/items", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(IdempotencyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+var idempotencyColumns = []string{
+	"id", "idem_key", "scope", "fingerprint", "status",
+	"response_status", "response_content_type", "response_headers", "response_body", "created_at", "expires_at",
+}
+
+func TestIdempotency_WithoutHeaderPassesThrough(t *testing.T) {
+	calls := 0
+	router := newIdempotencyRouter(&calls)
+
+	w := postWithKey(router, "", `{"name":"a"}`)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, 1, calls)
+}
+
+func TestIdempotency_IgnoresNonPost(t *testing.T) {
+	calls := 0
+	router := newIdempotencyRouter(&calls)
+
+	req, _ := http.NewRequest(http.MethodGet, "/items", nil)
+	req.Header.Set(IdempotencyHeader, "abc")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, calls)
+}
+
+func TestIdempotency_FirstRequestStoresResponse(t *testing.T) {
+	mock := setupAuthTestDB(t)
+	calls := 0
+	router := newIdempotencyRouter(&calls)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `idempotency_keys`").WillReturnResult(sqlmock.NewResult(7, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `idempotency_keys` SET").
+		WithArgs([]byte(`{"id":1}`), "application/json; charset=utf-8", `{"Location":["/items/1"]}`, http.StatusCreated, "completed", 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	w := postWithKey(router, "abc", `{"name":"a"}`)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, 1, calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotency_ReplaysCompletedResponse(t *testing.T) {
+	mock := setupAuthTestDB(t)
+	calls := 0
+	router := newIdempotencyRouter(&calls)
+	body := `{"name":"a"}`
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `idempotency_keys`").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT \\* FROM `idempotency_keys`").
+		WillReturnRows(sqlmock.NewRows(idempotencyColumns).AddRow(
+			7, "abc", hashParts(http.MethodPost, "/items", ""), hashParts(body), "completed",
+			http.StatusCreated, "application/json; charset=utf-8", `{"Location":["/items/1"]}`, []byte(`{"id":1}`), time.Now(), time.Now().Add(time.Hour)))
+
+	w := postWithKey(router, "abc", body)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `{"id":1}`, w.Body.String())
+	assert.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, "/items/1", w.Header().Get("Location"))
+	assert.Equal(t, 0, calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotency_DifferentBodyConflicts(t *testing.T) {
+	mock := setupAuthTestDB(t)
+	calls := 0
+	router := newIdempotencyRouter(&calls)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `idempotency_keys`").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT \\* FROM `idempotency_keys`").
+		WillReturnRows(sqlmock.NewRows(idempotencyColumns).AddRow(
+			7, "abc", hashParts(http.MethodPost, "/items", ""), hashParts(`{"name":"other"}`), "completed",
+			http.StatusCreated, "application/json", nil, []byte(`{}`), time.Now(), time.Now().Add(time.Hour)))
+
+	w := postWithKey(router, "abc", `{"name":"a"}`)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, 0, calls)
+}
+
+func TestIdempotency_InFlightConflicts(t *testing.T) {
+	mock := setupAuthTestDB(t)
+	calls := 0
+	router := newIdempotencyRouter(&calls)
+	body := `{"name":"a"}`
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `idempotency_keys`").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT \\* FROM `idempotency_keys`").
+		WillReturnRows(sqlmock.NewRows(idempotencyColumns).AddRow(
+			7, "abc", hashParts(http.MethodPost, "/items", ""), hashParts(body), "processing",
+			0, "", nil, nil, time.Now(), time.Now().Add(time.Hour)))
+
+	w := postWithKey(router, "abc", body)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Equal(t, 0, calls)
+}
+
+func TestIdempotency_RejectsLargeBody(t *testing.T) {
+	calls := 0
+	router := newIdempotencyRouter(&calls)
+
+	w := postWithKey(router, "abc", strings.Repeat("a", maxIdempotentRequestBody+1))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, 0, calls)
+}
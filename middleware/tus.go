@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TusVersion là phiên bản giao thức tus server hỗ trợ
+const TusVersion = "1.0.0"
+
+// TusResumable gắn header Tus-Resumable vào response và trả 412 nếu request (trừ OPTIONS)
+// không khai báo đúng phiên bản tus
+func TusResumable() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+		c.Header("Tus-Resumable", TusVersion)
+		if c.GetHeader("Tus-Resumable") != TusVersion {
+			c.Header("Tus-Version", TusVersion)
+			c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Unsupported tus version, expected Tus-Resumable: " + TusVersion})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTusRouter() *gin.Engine {
+	router := gin.New()
+	router.Use(TusResumable())
+	handler := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	router.OPTIONS("/uploads", handler)
+	router.HEAD("/uploads/:id", handler)
+	return router
+}
+
+func TestTusResumable_RejectsMissingVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("HEAD", "/uploads/abc", nil)
+	newTusRouter().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Equal(t, TusVersion, w.Header().Get("Tus-Version"))
+}
+
+func TestTusResumable_PassesSupportedVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("HEAD", "/uploads/abc", nil)
+	req.Header.Set("Tus-Resumable", TusVersion)
+	newTusRouter().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, TusVersion, w.Header().Get("Tus-Resumable"))
+
+	// OPTIONS không cần header phiên bản
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("OPTIONS", "/uploads", nil)
+	newTusRouter().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
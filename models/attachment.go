@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Attachment model tương ứng với bảng `attachments`: file đính kèm của post
+type Attachment struct {
+	ID           uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	PostID       uint      `json:"post_id" gorm:"not null"`
+	UploaderID   uint      `json:"uploader_id" gorm:"not null"`
+	StorageKey   string    `json:"-" gorm:"not null"`
+	ThumbnailKey *string   `json:"-"`
+	Filename     string    `json:"filename" gorm:"not null"`
+	ContentType  string    `json:"content_type" gorm:"not null"`
+	Size         int64     `json:"size" gorm:"not null"`
+	Width        *int      `json:"width"`
+	Height       *int      `json:"height"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// AttachmentView là attachment trả về cho client kèm link tải đã ký
+type AttachmentView struct {
+	Attachment
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+}
+
+// Keys trả về mọi key trong storage của attachment (file và thumbnail)
+func (a *Attachment) Keys() []string {
+	keys := []string{a.StorageKey}
+	if a.ThumbnailKey != nil {
+		keys = append(keys, *a.ThumbnailKey)
+	}
+	return keys
+}
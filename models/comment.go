@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// Comment model tương ứng với bảng `comments`
+type Comment struct {
+	ID        uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	PostID    uint       `json:"post_id" gorm:"not null"`
+	ParentID  *uint      `json:"parent_id"`
+	RootID    *uint      `json:"-"`
+	Depth     uint       `json:"depth" gorm:"not null;default:0"`
+	AuthorID  uint       `json:"author_id"` // 0 khi tác giả đã bị xóa (comment thành tombstone)
+	Content   string     `json:"content" gorm:"not null"`
+	DeletedAt *time.Time `json:"-"`
+	HiddenAt  *time.Time `json:"-" gorm:"->"` // chỉ đổi qua moderation
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// CommentView là dữ liệu comment trả về cho client.
+// Comment đã xóa hoặc bị moderator ẩn chỉ còn lại vị trí trong cây, không lộ nội dung và tác giả.
+type CommentView struct {
+	ID        uint          `json:"id"`
+	PostID    uint          `json:"post_id"`
+	ParentID  *uint         `json:"parent_id"`
+	Depth     uint          `json:"depth"`
+	AuthorID  *uint         `json:"author_id"`
+	Content   *string       `json:"content"`
+	Deleted   bool          `json:"deleted"`
+	Hidden    bool          `json:"hidden"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	Replies   []CommentView `json:"replies,omitempty"`
+}
+
+// IsDeleted cho biết comment đã bị xóa (tombstone) hay chưa
+func (c *Comment) IsDeleted() bool {
+	return c.DeletedAt != nil
+}
+
+// IsHidden cho biết comment có đang bị moderator ẩn hay không
+func (c *Comment) IsHidden() bool {
+	return c.HiddenAt != nil
+}
+
+// ThreadID trả về id comment gốc của thread chứa comment này
+func (c *Comment) ThreadID() uint {
+	if c.RootID != nil {
+		return *c.RootID
+	}
+	return c.ID
+}
+
+// View trả về dữ liệu công khai của comment
+func (c *Comment) View() CommentView {
+	view := CommentView{
+		ID:        c.ID,
+		PostID:    c.PostID,
+		ParentID:  c.ParentID,
+		Depth:     c.Depth,
+		Deleted:   c.IsDeleted(),
+		Hidden:    c.IsHidden(),
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+	if !view.Deleted && !view.Hidden {
+		authorID, content := c.AuthorID, c.Content
+		view.AuthorID, view.Content = &authorID, &content
+	}
+	return view
+}
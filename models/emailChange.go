@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// EmailChange model tương ứng với bảng `email_changes`
+type EmailChange struct {
+	ID          uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID      uint       `json:"user_id" gorm:"not null"`
+	OldEmail    string     `json:"old_email" gorm:"not null"`
+	NewEmail    string     `json:"new_email" gorm:"not null"`
+	Token       string     `json:"-" gorm:"unique;not null"`
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"not null"`
+	ConfirmedAt *time.Time `json:"confirmed_at"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// IsPending cho biết yêu cầu còn hiệu lực để xác nhận hay không
+func (e *EmailChange) IsPending(now time.Time) bool {
+	return e.ConfirmedAt == nil && now.Before(e.ExpiresAt)
+}
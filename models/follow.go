@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// Follow model tương ứng với bảng `follows`: FollowerID theo dõi FolloweeID
+type Follow struct {
+	FollowerID uint `gorm:"primaryKey"`
+	FolloweeID uint `gorm:"primaryKey"`
+	CreatedAt  time.Time
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Trạng thái của một Idempotency-Key
+const (
+	IdempotencyProcessing = "processing"
+	IdempotencyCompleted  = "completed"
+)
+
+// IdempotencyKey model tương ứng với bảng `idempotency_keys`
+type IdempotencyKey struct {
+	ID                  uint      `gorm:"primaryKey;autoIncrement"`
+	Key                 string    `gorm:"column:idem_key;not null"`
+	Scope               string    `gorm:"not null"`
+	Fingerprint         string    `gorm:"not null"`
+	Status              string    `gorm:"not null"`
+	ResponseStatus      int       `gorm:"default:null"`
+	ResponseContentType string    `gorm:"default:null"`
+	ResponseHeaders     string    `gorm:"default:null"` // JSON của http.Header
+	ResponseBody        []byte    `gorm:"default:null"`
+	CreatedAt           time.Time `gorm:"autoCreateTime"`
+	ExpiresAt           time.Time `gorm:"not null"`
+}
+
+// IsExpired cho biết key đã hết hạn hay chưa
+func (k *IdempotencyKey) IsExpired(now time.Time) bool {
+	return !now.Before(k.ExpiresAt)
+}
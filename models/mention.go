@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Các loại nội dung có thể chứa mention
+const (
+	MentionTargetPost    = "post"
+	MentionTargetComment = "comment"
+)
+
+// Mention model tương ứng với bảng `mentions`: user UserID được nhắc (@handle) trong nội dung
+type Mention struct {
+	ID         uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID     uint       `json:"user_id" gorm:"not null"`
+	AuthorID   uint       `json:"author_id" gorm:"not null"`
+	Author     *User      `json:"-" gorm:"foreignKey:AuthorID"`
+	TargetType string     `json:"target_type" gorm:"not null"`
+	TargetID   uint       `json:"target_id" gorm:"not null"`
+	PostID     uint       `json:"post_id" gorm:"not null"`
+	NotifiedAt *time.Time `json:"-"` // nil = chưa gửi thông báo
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// MentionAuthorView là tác giả của nội dung chứa mention
+type MentionAuthorView struct {
+	ID     uint   `json:"id"`
+	Name   string `json:"name"`
+	Handle string `json:"handle"`
+}
+
+// MentionView là dữ liệu mention trả về cho người được nhắc
+type MentionView struct {
+	ID         uint               `json:"id"`
+	TargetType string             `json:"target_type"`
+	TargetID   uint               `json:"target_id"`
+	PostID     uint               `json:"post_id"`
+	Author     *MentionAuthorView `json:"author"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+// View trả về dữ liệu mention; Author chỉ có khi đã preload
+func (m *Mention) View() MentionView {
+	view := MentionView{
+		ID:         m.ID,
+		TargetType: m.TargetType,
+		TargetID:   m.TargetID,
+		PostID:     m.PostID,
+		CreatedAt:  m.CreatedAt,
+	}
+	if m.Author != nil {
+		view.Author = &MentionAuthorView{ID: m.Author.ID, Name: m.Author.Name, Handle: m.Author.Handle}
+	}
+	return view
+}
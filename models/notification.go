@@ -0,0 +1,131 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Các loại thông báo
+const (
+	NotificationComment  = "comment"
+	NotificationFollow   = "follow"
+	NotificationMention  = "mention"
+	NotificationReaction = "reaction"
+)
+
+// NotificationTypes là các loại thông báo user có thể bật/tắt
+var NotificationTypes = []string{NotificationComment, NotificationFollow, NotificationMention, NotificationReaction}
+
+// Các loại đối tượng của thông báo; đối tượng của thông báo follow là chính người nhận
+const (
+	NotificationTargetPost    = "post"
+	NotificationTargetComment = "comment"
+	NotificationTargetUser    = "user"
+)
+
+// Notification model tương ứng với bảng `notifications`
+type Notification struct {
+	ID         uint    `gorm:"primaryKey;autoIncrement"`
+	UserID     uint    `gorm:"not null"`
+	Type       string  `gorm:"not null"`
+	ActorID    uint    // 0 khi người gây ra sự kiện gần nhất đã bị xóa
+	Actor      *User   `gorm:"foreignKey:ActorID"`
+	ActorCount uint    `gorm:"not null;default:1"`
+	TargetType string  `gorm:"not null"`
+	TargetID   uint    `gorm:"not null"`
+	PostID     *uint   // post liên quan, nil với thông báo follow
+	GroupKey   *string // khóa gộp, nil khi đã đọc
+	ReadAt     *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// NotificationActor là bảng `notification_actors`: những người đã được gộp vào một thông báo
+type NotificationActor struct {
+	NotificationID uint `gorm:"primaryKey"`
+	ActorID        uint `gorm:"primaryKey"`
+}
+
+// NotificationPreference là bảng `notification_preferences`
+type NotificationPreference struct {
+	UserID  uint   `gorm:"primaryKey"`
+	Type    string `gorm:"primaryKey"`
+	Enabled bool   `gorm:"not null"`
+}
+
+// NotificationActorView là người gây ra sự kiện gần nhất
+type NotificationActorView struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// NotificationView là dữ liệu thông báo trả về cho client
+type NotificationView struct {
+	ID         uint                  `json:"id"`
+	Type       string                `json:"type"`
+	Message    string                `json:"message"`
+	Actor      NotificationActorView `json:"actor"`
+	ActorCount uint                  `json:"actor_count"`
+	TargetType string                `json:"target_type"`
+	TargetID   uint                  `json:"target_id"`
+	PostID     *uint                 `json:"post_id"`
+	Read       bool                  `json:"read"`
+	CreatedAt  time.Time             `json:"created_at"`
+	UpdatedAt  time.Time             `json:"updated_at"`
+}
+
+// IsNotificationType kiểm tra loại thông báo có được hỗ trợ hay không
+func IsNotificationType(notificationType string) bool {
+	for _, t := range NotificationTypes {
+		if t == notificationType {
+			return true
+		}
+	}
+	return false
+}
+
+// View trả về thông báo kèm câu mô tả, ví dụ "Alice and 4 others reacted to your post"
+func (n *Notification) View() NotificationView {
+	view := NotificationView{
+		ID:         n.ID,
+		Type:       n.Type,
+		Actor:      NotificationActorView{ID: n.ActorID},
+		ActorCount: n.ActorCount,
+		TargetType: n.TargetType,
+		TargetID:   n.TargetID,
+		PostID:     n.PostID,
+		Read:       n.ReadAt != nil,
+		CreatedAt:  n.CreatedAt,
+		UpdatedAt:  n.UpdatedAt,
+	}
+	if n.Actor != nil {
+		view.Actor.Name = n.Actor.Name
+	}
+	view.Message = n.message(view.Actor.Name)
+	return view
+}
+
+func (n *Notification) message(actor string) string {
+	if actor == "" {
+		actor = "Someone"
+	}
+	switch n.ActorCount {
+	case 0, 1:
+	case 2:
+		actor += " and 1 other"
+	default:
+		actor += fmt.Sprintf(" and %d others", n.ActorCount-1)
+	}
+
+	switch n.Type {
+	case NotificationComment:
+		return actor + " commented on your post"
+	case NotificationFollow:
+		return actor + " started following you"
+	case NotificationMention:
+		return actor + " mentioned you in a " + n.TargetType
+	case NotificationReaction:
+		return actor + " reacted to your " + n.TargetType
+	}
+	return actor
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotification_ViewMessage(t *testing.T) {
+	cases := []struct {
+		notification Notification
+		want         string
+	}{
+		{Notification{Type: NotificationComment, ActorCount: 1, Actor: &User{Name: "Alice"}}, "Alice commented on your post"},
+		{Notification{Type: NotificationFollow, ActorCount: 2, Actor: &User{Name: "Bob"}}, "Bob and 1 other started following you"},
+		{Notification{Type: NotificationReaction, ActorCount: 5, TargetType: NotificationTargetComment, Actor: &User{Name: "Alice"}}, "Alice and 4 others reacted to your comment"},
+		{Notification{Type: NotificationMention, ActorCount: 1, TargetType: NotificationTargetPost}, "Someone mentioned you in a post"},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, tc.notification.View().Message)
+	}
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Post model tương ứng với bảng `posts`
+type Post struct {
+	ID           uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Title        string     `json:"title" gorm:"not null"`
+	Slug         string     `json:"slug" gorm:"default:null"` // sinh từ title, duy nhất
+	Content      string     `json:"content"`
+	ContentHTML  string     `json:"-" gorm:"column:content_html"` // render từ Content khi ghi, trả về qua ?format=html
+	AuthorID     uint       `json:"author_id" gorm:"not null"`
+	Author       *User      `json:"-" gorm:"foreignKey:AuthorID"`
+	Status       string     `json:"status" gorm:"not null;default:draft"`
+	PublishedAt  *time.Time `json:"published_at"`
+	ScheduledFor *time.Time `json:"scheduled_for"`
+	CommentCount uint       `json:"comment_count" gorm:"->"` // chỉ cập nhật cùng transaction với bảng comments
+	ViewCount    uint64     `json:"view_count" gorm:"->"`    // cộng dồn theo lô bởi services.ViewAggregator
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// Các trạng thái của post
+const (
+	PostDraft     = "draft"
+	PostScheduled = "scheduled"
+	PostPublished = "published"
+	PostArchived  = "archived"
+	PostHidden    = "hidden" // bị moderator ẩn, chỉ moderator đổi lại được
+)
+
+// postTransitions là các bước chuyển trạng thái hợp lệ
+var postTransitions = map[string][]string{
+	PostDraft:     {PostScheduled, PostPublished, PostArchived},
+	PostScheduled: {PostScheduled, PostDraft, PostPublished},
+	PostPublished: {PostArchived},
+	PostArchived:  {PostDraft, PostPublished},
+}
+
+// CanTransition cho biết post có được chuyển sang trạng thái status hay không
+// (scheduled -> scheduled là đổi lịch)
+func (p *Post) CanTransition(status string) bool {
+	for _, next := range postTransitions[p.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPublished cho biết post đã xuất bản hay chưa
+func (p *Post) IsPublished() bool {
+	return p.Status == PostPublished
+}
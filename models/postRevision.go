@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// PostRevision model tương ứng với bảng `post_revisions`
+type PostRevision struct {
+	ID           uint      `json:"-" gorm:"primaryKey;autoIncrement"`
+	PostID       uint      `json:"post_id" gorm:"not null"`
+	Revision     uint      `json:"revision" gorm:"not null"`
+	Title        string    `json:"title" gorm:"not null"`
+	Content      string    `json:"content,omitempty"`
+	EditorID     uint      `json:"editor_id" gorm:"not null"`
+	RestoredFrom *uint     `json:"restored_from,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
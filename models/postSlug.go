@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// PostSlug model tương ứng với bảng `post_slugs`: slug cũ của post, dùng để chuyển hướng
+type PostSlug struct {
+	Slug      string `gorm:"primaryKey"`
+	PostID    uint   `gorm:"not null"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// PostViewStat model tương ứng với bảng `post_view_stats`: lượt xem của post trong một giờ
+type PostViewStat struct {
+	PostID      uint      `gorm:"primaryKey"`
+	BucketStart time.Time `gorm:"primaryKey"`
+	Views       uint      `gorm:"not null;default:0"`
+}
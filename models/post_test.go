@@ -0,0 +1,28 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPost_CanTransition(t *testing.T) {
+	cases := []struct {
+		from, to string
+		allowed  bool
+	}{
+		{PostDraft, PostPublished, true},
+		{PostDraft, PostScheduled, true},
+		{PostScheduled, PostScheduled, true},
+		{PostScheduled, PostDraft, true},
+		{PostPublished, PostArchived, true},
+		{PostPublished, PostDraft, false},
+		{PostPublished, PostScheduled, false},
+		{PostArchived, PostPublished, true},
+		{PostDraft, PostDraft, false},
+	}
+	for _, tc := range cases {
+		post := Post{Status: tc.from}
+		assert.Equal(t, tc.allowed, post.CanTransition(tc.to), "%s -> %s", tc.from, tc.to)
+	}
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Các đối tượng có thể react
+const (
+	ReactionTargetPost    = "post"
+	ReactionTargetComment = "comment"
+)
+
+// ReactionTypes là tập reaction cố định được hỗ trợ
+var ReactionTypes = []string{"like", "love", "haha", "wow", "sad", "angry"}
+
+// Reaction model tương ứng với bảng `reactions`
+type Reaction struct {
+	ID         uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	TargetType string    `json:"target_type" gorm:"not null"`
+	TargetID   uint      `json:"target_id" gorm:"not null"`
+	UserID     uint      `json:"user_id" gorm:"not null"`
+	Type       string    `json:"type" gorm:"not null"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ReactionCount model tương ứng với bảng `reaction_counts`
+type ReactionCount struct {
+	TargetType string `gorm:"primaryKey"`
+	TargetID   uint   `gorm:"primaryKey"`
+	Type       string `gorm:"primaryKey"`
+	Count      uint   `gorm:"not null;default:0"`
+}
+
+// ReactionSummary là số reaction theo loại của một đối tượng và các reaction của user hiện tại
+type ReactionSummary struct {
+	Counts map[string]uint `json:"counts"`
+	Mine   []string        `json:"mine"`
+}
+
+// IsReactionType kiểm tra loại reaction có được hỗ trợ hay không
+func IsReactionType(reactionType string) bool {
+	for _, t := range ReactionTypes {
+		if t == reactionType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// ReadingList model tương ứng với bảng `reading_lists`: danh sách đọc (bookmark) của user
+type ReadingList struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID      uint      `json:"user_id" gorm:"not null"`
+	Name        string    `json:"name" gorm:"not null"`
+	Description string    `json:"description"`
+	Public      bool      `json:"public" gorm:"column:is_public;not null"`
+	ItemCount   uint      `json:"item_count" gorm:"->"` // chỉ có khi truy vấn select kèm số item
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ReadingListItem là bảng `reading_list_items`: post trong danh sách, sắp theo Position
+type ReadingListItem struct {
+	ListID    uint `gorm:"primaryKey"`
+	PostID    uint `gorm:"primaryKey"`
+	Position  int  `gorm:"not null"`
+	CreatedAt time.Time
+}
+
+// CanView cho biết userID (0 = chưa đăng nhập) có được xem danh sách hay không
+func (l *ReadingList) CanView(userID uint) bool {
+	return l.Public || (userID != 0 && l.UserID == userID)
+}
@@ -0,0 +1,85 @@
+package models
+
+import "time"
+
+// Các đối tượng có thể bị báo cáo
+const (
+	ReportTargetPost    = "post"
+	ReportTargetComment = "comment"
+	ReportTargetUser    = "user"
+)
+
+// ReportReasons là các lý do user được chọn khi báo cáo
+var ReportReasons = []string{"spam", "harassment", "hate_speech", "nudity", "violence", "other"}
+
+// ReportReasonBannedWords là lý do của báo cáo do bộ lọc từ cấm tự tạo
+const ReportReasonBannedWords = "banned_words"
+
+// Các trạng thái của report
+const (
+	ReportOpen      = "open"
+	ReportInReview  = "in_review"
+	ReportResolved  = "resolved"
+	ReportDismissed = "dismissed"
+)
+
+// Các hành động moderation, được ghi vào moderation_log
+const (
+	ModerationFlag      = "flag"
+	ModerationAssign    = "assign"
+	ModerationHide      = "hide"
+	ModerationUnhide    = "unhide"
+	ModerationSuspend   = "suspend"
+	ModerationUnsuspend = "unsuspend"
+	ModerationDismiss   = "dismiss"
+)
+
+// Report model tương ứng với bảng `reports`
+type Report struct {
+	ID             uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	ReporterID     *uint      `json:"reporter_id"` // nil = hệ thống tự gắn cờ
+	TargetType     string     `json:"target_type" gorm:"not null"`
+	TargetID       uint       `json:"target_id" gorm:"not null"`
+	Reason         string     `json:"reason" gorm:"not null"`
+	Details        string     `json:"details"`
+	Status         string     `json:"status" gorm:"not null;default:open"`
+	AssigneeID     *uint      `json:"assignee_id"`
+	Resolution     *string    `json:"resolution"`
+	ResolutionNote string     `json:"resolution_note"`
+	ResolvedBy     *uint      `json:"resolved_by"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// IsClosed cho biết report đã được xử lý xong hay chưa
+func (r *Report) IsClosed() bool {
+	return r.Status == ReportResolved || r.Status == ReportDismissed
+}
+
+// ModerationLogEntry model tương ứng với bảng `moderation_log` (chỉ thêm, không sửa/xóa)
+type ModerationLogEntry struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ModeratorID *uint     `json:"moderator_id"` // nil = hệ thống
+	Action      string    `json:"action" gorm:"not null"`
+	TargetType  string    `json:"target_type" gorm:"not null"`
+	TargetID    uint      `json:"target_id" gorm:"not null"`
+	ReportID    *uint     `json:"report_id"`
+	Note        string    `json:"note"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName — bảng nhật ký đặt tên số ít
+func (ModerationLogEntry) TableName() string {
+	return "moderation_log"
+}
+
+// IsReportReason kiểm tra lý do báo cáo có được hỗ trợ hay không
+func IsReportReason(reason string) bool {
+	for _, r := range ReportReasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
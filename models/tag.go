@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Tag model tương ứng với bảng `tags`
+type Tag struct {
+	ID         uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name       string    `json:"name" gorm:"not null"`
+	Slug       string    `json:"slug" gorm:"unique;not null"`
+	UsageCount uint      `json:"usage_count" gorm:"not null;default:0"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// PostTag là bảng nối `post_tags`
+type PostTag struct {
+	PostID uint `gorm:"primaryKey"`
+	TagID  uint `gorm:"primaryKey"`
+}
+
+// Category model tương ứng với bảng `categories`
+type Category struct {
+	ID        uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name      string     `json:"name" gorm:"not null"`
+	Slug      string     `json:"slug" gorm:"unique;not null"`
+	ParentID  *uint      `json:"parent_id"`
+	Children  []Category `json:"children,omitempty" gorm:"-"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// PostCategory là bảng nối `post_categories`
+type PostCategory struct {
+	PostID     uint `gorm:"primaryKey"`
+	CategoryID uint `gorm:"primaryKey"`
+}
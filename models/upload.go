@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Upload model tương ứng với bảng `uploads`: upload resumable (tus) của một user,
+// khi nhận đủ UploadLength byte sẽ thành attachment của post PostID
+type Upload struct {
+	ID           string    `json:"id" gorm:"primaryKey"`
+	OwnerID      uint      `json:"owner_id" gorm:"not null"`
+	PostID       uint      `json:"post_id" gorm:"not null"`
+	Filename     string    `json:"filename" gorm:"not null"`
+	Metadata     *string   `json:"-"`
+	UploadLength int64     `json:"upload_length" gorm:"not null"`
+	UploadOffset int64     `json:"upload_offset" gorm:"not null"`
+	AttachmentID *uint     `json:"attachment_id"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"not null"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Complete cho biết đã nhận đủ dữ liệu của upload
+func (u *Upload) Complete() bool {
+	return u.UploadOffset == u.UploadLength
+}
+
+// UploadPart là một đoạn dữ liệu của upload, lưu thành một file riêng trong storage
+type UploadPart struct {
+	UploadID    string `gorm:"primaryKey"`
+	StartOffset int64  `gorm:"primaryKey"`
+	StorageKey  string `gorm:"not null"`
+	Size        int64  `gorm:"not null"`
+}
+
+// UploadView là trạng thái upload trả về cho client, kèm attachment khi đã hoàn tất
+type UploadView struct {
+	Upload
+	Attachment *AttachmentView `json:"attachment,omitempty"`
+}
@@ -1,22 +1,112 @@
 package models
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 // User model tương ứng với bảng `users`
 type User struct {
-	ID       uint   `json:"id" gorm:"primaryKey;autoIncrement"`
-	Email    string `json:"email" gorm:"unique;not null"`
-	Name     string `json:"name" gorm:"not null"`
-	Password string `json:"password" gorm:"not null"`
+	ID             uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Email          string     `json:"email" gorm:"unique;not null"`
+	Name           string     `json:"name" gorm:"not null"`
+	Handle         string     `json:"handle" gorm:"default:null"` // dùng để nhắc (@handle), duy nhất
+	Role           string     `json:"role" gorm:"not null;default:user"`
+	Password       string     `json:"password" gorm:"not null"`
+	TokenVersion   uint       `json:"-" gorm:"not null;default:0"`
+	Version        uint       `json:"version" gorm:"not null;default:1"`
+	FollowerCount  uint       `json:"follower_count" gorm:"->"` // chỉ cập nhật cùng transaction với bảng follows
+	FollowingCount uint       `json:"following_count" gorm:"->"`
+	SuspendedAt    *time.Time `json:"-" gorm:"->"` // chỉ đổi qua moderation
+	SuspendedUntil *time.Time `json:"-" gorm:"->"` // nil = khóa vô thời hạn
+	AvatarKey      *string    `json:"-" gorm:"->"` // key trong storage, chỉ đổi qua /users/me/avatar
+	AvatarThumbKey *string    `json:"-" gorm:"->"`
+	Posts          []Post     `json:"posts,omitempty" gorm:"foreignKey:AuthorID"`
 	// CreatedAt time.Time      `json:"created_at" gorm:"autoCreateTime"`
 	// UpdatedAt time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
 	// DeletedAt gorm.DeletedAt `json:"-" gorm:"index"` // Tùy chọn: cho soft delete
 }
 
-// BeforeCreate hook — có thể dùng để hash password hoặc validate
+// Các role của user
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// UserProfile là dữ liệu user trả về cho chính user đó (không bao giờ chứa password)
+type UserProfile struct {
+	ID      uint   `json:"id"`
+	Email   string `json:"email"`
+	Name    string `json:"name"`
+	Handle  string `json:"handle"`
+	Role    string `json:"role"`
+	Version uint   `json:"version"`
+}
+
+// BeforeCreate hook — hash password trước khi lưu
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
-	// Ví dụ: validate email hoặc hash password
+	if u.Password != "" && !isPasswordHash(u.Password) {
+		u.Password, err = HashPassword(u.Password)
+	}
+	return err
+}
+
+// BeforeUpdate hook — mọi lần cập nhật đều tăng version để ETag thay đổi
+func (u *User) BeforeUpdate(tx *gorm.DB) (err error) {
+	tx.Statement.SetColumn("version", gorm.Expr("version + 1"))
+	return nil
+}
+
+// AfterUpdate hook — đồng bộ version trong bộ nhớ với giá trị vừa ghi
+func (u *User) AfterUpdate(tx *gorm.DB) (err error) {
+	if tx.Statement.RowsAffected > 0 {
+		u.Version++
+	}
 	return nil
 }
+
+// ETag trả về strong ETag của user, sinh từ id và version
+func (u *User) ETag() string {
+	return fmt.Sprintf(`"user-%d-v%d"`, u.ID, u.Version)
+}
+
+// Profile trả về dữ liệu công khai của user
+func (u *User) Profile() UserProfile {
+	return UserProfile{ID: u.ID, Email: u.Email, Name: u.Name, Handle: u.Handle, Role: u.Role, Version: u.Version}
+}
+
+// IsAdmin cho biết user có quyền admin hay không
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+// IsSuspended cho biết tài khoản có đang bị khóa tại thời điểm now hay không
+func (u *User) IsSuspended(now time.Time) bool {
+	return u.SuspendedAt != nil && (u.SuspendedUntil == nil || now.Before(*u.SuspendedUntil))
+}
+
+// CheckPassword so sánh mật khẩu plaintext với mật khẩu đã lưu.
+// Các bản ghi cũ còn lưu plaintext vẫn được so sánh trực tiếp.
+func (u *User) CheckPassword(password string) bool {
+	if !isPasswordHash(u.Password) {
+		return u.Password != "" && u.Password == password
+	}
+	return bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password)) == nil
+}
+
+// HashPassword hash mật khẩu bằng bcrypt
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func isPasswordHash(s string) bool {
+	return strings.HasPrefix(s, "$2a$") || strings.HasPrefix(s, "$2b$") || strings.HasPrefix(s, "$2y$")
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
@@ -195,3 +196,35 @@ func TestUserModel_AutoIncrement(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestUserModel_PasswordHashing(t *testing.T) {
+	hash, err := HashPassword("password")
+	assert.NoError(t, err)
+	assert.NotEqual(t, "password", hash)
+
+	user := User{Password: hash}
+	assert.True(t, user.CheckPassword("password"))
+	assert.False(t, user.CheckPassword("wrong"))
+
+	// Bản ghi cũ còn lưu plaintext
+	legacy := User{Password: "password"}
+	assert.True(t, legacy.CheckPassword("password"))
+	assert.False(t, legacy.CheckPassword(""))
+}
+
+func TestUserModel_ProfileHidesPassword(t *testing.T) {
+	user := User{ID: 1, Name: "John Doe", Email: "john@example.com", Role: RoleUser, Password: "password"}
+
+	profile := user.Profile()
+	assert.Equal(t, UserProfile{ID: 1, Name: "John Doe", Email: "john@example.com", Role: RoleUser}, profile)
+}
+
+func TestUser_IsSuspended(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	assert.False(t, (&User{}).IsSuspended(now))
+	assert.True(t, (&User{SuspendedAt: &past}).IsSuspended(now), "no end means indefinite")
+	assert.True(t, (&User{SuspendedAt: &past, SuspendedUntil: &future}).IsSuspended(now))
+	assert.False(t, (&User{SuspendedAt: &past, SuspendedUntil: &past}).IsSuspended(now), "suspension has expired")
+}
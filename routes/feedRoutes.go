@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"myapp/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterFeedRoutes đăng ký feed công khai (không cần đăng nhập, chỉ có post đã xuất bản).
+// Mỗi feed có ba định dạng theo phần mở rộng: .rss, .atom, .json (JSON Feed)
+func RegisterFeedRoutes(r *gin.Engine) {
+	feedGroup := r.Group("/feeds")
+	for _, ext := range []string{".rss", ".atom", ".json"} {
+		feedGroup.GET("/posts"+ext, controllers.GetPostsFeed)
+		feedGroup.GET("/users/:id/posts"+ext, controllers.GetUserPostsFeed)
+		feedGroup.GET("/tags/:slug/posts"+ext, controllers.GetTagPostsFeed)
+	}
+
+	// Link của từng bài trong feed: post đã xuất bản xem được không cần đăng nhập
+	feedGroup.GET("/posts/:slug", controllers.GetPostBySlug)
+	feedGroup.GET("/items/:id", controllers.GetPost)
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"myapp/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterFileRoutes(r *gin.Engine) {
+	fileGroup := NewBaseRoute(r, "/files").Group()
+	{
+		// Tải file của storage local; link đã được ký nên không cần token
+		fileGroup.GET("/*key", controllers.DownloadFile)
+	}
+}
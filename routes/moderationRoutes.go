@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"myapp/controllers"
+	"myapp/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterModerationRoutes(r *gin.Engine) {
+	// User báo cáo nội dung vi phạm
+	reportGroup := NewBaseRoute(r, "/reports").Group()
+	reportGroup.Use(middleware.AuthRequired())
+	{
+		reportGroup.POST("", middleware.Idempotency(), controllers.CreateReport)
+	}
+
+	// Hàng đợi và hành động moderation, chỉ dành cho admin
+	moderationGroup := NewBaseRoute(r, "/moderation").Group()
+	moderationGroup.Use(middleware.AuthRequired(), middleware.AdminRequired())
+	{
+		moderationGroup.GET("/reports", controllers.GetReports)
+		moderationGroup.GET("/reports/:id", controllers.GetReport)
+		moderationGroup.POST("/reports/:id/assign", middleware.Idempotency(), controllers.AssignReport)
+		moderationGroup.DELETE("/reports/:id/assign", controllers.UnassignReport)
+		moderationGroup.POST("/reports/:id/resolve", middleware.Idempotency(), controllers.ResolveReport)
+		moderationGroup.POST("/actions", middleware.Idempotency(), controllers.ModerateContent)
+		moderationGroup.GET("/log", controllers.GetModerationLog)
+		moderationGroup.GET("/banned-words", controllers.GetBannedWords)
+	}
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"myapp/controllers"
+	"myapp/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterNotificationRoutes(r *gin.Engine) {
+	notificationGroup := NewBaseRoute(r, "/notifications").Group()
+	notificationGroup.Use(middleware.AuthRequired())
+	{
+		notificationGroup.GET("", controllers.GetNotifications)
+		notificationGroup.GET("/unread-count", controllers.GetUnreadNotificationCount)
+		notificationGroup.POST("/read-all", middleware.Idempotency(), controllers.MarkAllNotificationsRead)
+		notificationGroup.POST("/:id/read", middleware.Idempotency(), controllers.MarkNotificationRead)
+		notificationGroup.GET("/preferences", controllers.GetNotificationPreferences)
+		notificationGroup.PUT("/preferences", controllers.UpdateNotificationPreferences)
+	}
+}
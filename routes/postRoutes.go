@@ -0,0 +1,61 @@
+package routes
+
+import (
+	"myapp/controllers"
+	"myapp/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterPostRoutes(r *gin.Engine) {
+	postGroup := NewBaseRoute(r, "/posts").Group()
+	postGroup.Use(middleware.AuthRequired())
+	{
+		postGroup.GET("", controllers.GetPosts)
+		postGroup.POST("", middleware.Idempotency(), controllers.CreatePost)
+		postGroup.GET("/:id", controllers.GetPost)
+		postGroup.GET("/by-slug/:slug", controllers.GetPostBySlug)
+		postGroup.GET("/trending", controllers.GetTrendingPosts)
+
+		// Chỉ tác giả hoặc admin được sửa/xóa (kiểm tra trong controller)
+		postGroup.PUT("/:id", controllers.UpdatePost)
+		postGroup.PATCH("/:id", controllers.PatchPost)
+		postGroup.DELETE("/:id", controllers.DeletePost)
+		postGroup.PUT("/:id/status", controllers.SetPostStatus)
+
+		// Lịch sử sửa đổi (chỉ tác giả hoặc admin)
+		revisions := postGroup.Group("/:id/revisions")
+		{
+			revisions.GET("", controllers.GetPostRevisions)
+			revisions.GET("/diff", controllers.DiffPostRevisions)
+			revisions.GET("/:rev", controllers.GetPostRevision)
+			revisions.POST("/:rev/restore", middleware.Idempotency(), controllers.RestorePostRevision)
+		}
+
+		// Reaction, idempotent: PUT để thêm, DELETE để bỏ
+		postGroup.PUT("/:id/reactions/:type", controllers.AddPostReaction)
+		postGroup.DELETE("/:id/reactions/:type", controllers.RemovePostReaction)
+
+		// Tag và danh mục của post
+		postGroup.GET("/:id/tags", controllers.GetPostTags)
+		postGroup.PUT("/:id/tags", controllers.SetPostTags)
+		postGroup.GET("/:id/categories", controllers.GetPostCategories)
+		postGroup.PUT("/:id/categories", controllers.SetPostCategories)
+
+		// File đính kèm; chỉ tác giả hoặc admin được thêm/xóa
+		postGroup.GET("/:id/attachments", controllers.GetPostAttachments)
+		postGroup.POST("/:id/attachments", controllers.UploadPostAttachment)
+		postGroup.DELETE("/:id/attachments/:attachment_id", controllers.DeletePostAttachment)
+
+		// Comment dạng cây; tác giả post và admin được xóa comment của người khác
+		comments := postGroup.Group("/:id/comments")
+		{
+			comments.GET("", controllers.GetComments)
+			comments.POST("", middleware.Idempotency(), controllers.CreateComment)
+			comments.PATCH("/:comment_id", controllers.UpdateComment)
+			comments.DELETE("/:comment_id", controllers.DeleteComment)
+			comments.PUT("/:comment_id/reactions/:type", controllers.AddCommentReaction)
+			comments.DELETE("/:comment_id/reactions/:type", controllers.RemoveCommentReaction)
+		}
+	}
+}
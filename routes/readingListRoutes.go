@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"myapp/controllers"
+	"myapp/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterReadingListRoutes(r *gin.Engine) {
+	listGroup := NewBaseRoute(r, "/lists").Group()
+	listGroup.Use(middleware.AuthRequired())
+	{
+		listGroup.GET("", controllers.GetMyLists)
+		listGroup.POST("", middleware.Idempotency(), controllers.CreateList)
+		listGroup.GET("/:id", controllers.GetList)
+		listGroup.PATCH("/:id", controllers.UpdateList)
+		listGroup.DELETE("/:id", controllers.DeleteList)
+		listGroup.GET("/:id/items", controllers.GetListItems)
+		listGroup.PUT("/:id/items/:post_id", controllers.AddListItem)
+		listGroup.DELETE("/:id/items/:post_id", controllers.RemoveListItem)
+		listGroup.PUT("/:id/order", controllers.ReorderList)
+	}
+}
@@ -10,6 +10,7 @@ func SetupRouter() *gin.Engine {
 
 	// middleware chung
 	r.Use(middleware.RequestLogger())
+	// Idempotency không dùng chung: chỉ gắn vào các route POST nhận JSON, sau AuthRequired
 
 	// Health check endpoint cho Docker healthcheck
 	r.GET("/health", func(c *gin.Context) {
@@ -21,7 +22,17 @@ func SetupRouter() *gin.Engine {
 
 	// Load từng group routes
 	RegisterUserRoutes(r)
+	RegisterPostRoutes(r)
+	RegisterTagRoutes(r)
 	RegisterAuthRoutes(r)
+	RegisterFeedRoutes(r)
+	RegisterSearchRoutes(r)
+	RegisterTimelineRoutes(r)
+	RegisterNotificationRoutes(r)
+	RegisterModerationRoutes(r)
+	RegisterReadingListRoutes(r)
+	RegisterFileRoutes(r)
+	RegisterUploadRoutes(r)
 
 	return r
 }
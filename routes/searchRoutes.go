@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"myapp/controllers"
+	"myapp/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterSearchRoutes(r *gin.Engine) {
+	searchGroup := NewBaseRoute(r, "/search").Group()
+	searchGroup.Use(middleware.AuthRequired())
+	{
+		searchGroup.GET("", controllers.Search)
+	}
+}
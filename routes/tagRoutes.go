@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"myapp/controllers"
+	"myapp/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterTagRoutes(r *gin.Engine) {
+	tagGroup := NewBaseRoute(r, "/tags").Group()
+	tagGroup.Use(middleware.AuthRequired())
+	{
+		tagGroup.GET("", controllers.GetPopularTags)
+		tagGroup.GET("/autocomplete", controllers.AutocompleteTags)
+	}
+
+	// Ai cũng xem được cây danh mục, chỉ admin được sửa
+	categoryGroup := NewBaseRoute(r, "/categories").Group()
+	categoryGroup.Use(middleware.AuthRequired())
+	{
+		categoryGroup.GET("", controllers.GetCategories)
+		categoryGroup.POST("", middleware.AdminRequired(), middleware.Idempotency(), controllers.CreateCategory)
+		categoryGroup.PATCH("/:id", middleware.AdminRequired(), controllers.UpdateCategory)
+		categoryGroup.DELETE("/:id", middleware.AdminRequired(), controllers.DeleteCategory)
+	}
+}
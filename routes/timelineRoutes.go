@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"myapp/controllers"
+	"myapp/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterTimelineRoutes(r *gin.Engine) {
+	timelineGroup := NewBaseRoute(r, "/timeline").Group()
+	timelineGroup.Use(middleware.AuthRequired())
+	{
+		timelineGroup.GET("", controllers.GetTimeline)
+	}
+}
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"myapp/controllers"
+	"myapp/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterUploadRoutes(r *gin.Engine) {
+	// Upload resumable theo giao thức tus 1.0
+	uploadGroup := NewBaseRoute(r, "/uploads").Group()
+	uploadGroup.Use(middleware.TusResumable())
+	{
+		// Client hỏi khả năng của server trước khi đăng nhập (và CORS preflight)
+		uploadGroup.OPTIONS("", controllers.TusOptions)
+		uploadGroup.OPTIONS("/:id", controllers.TusOptions)
+	}
+
+	authed := uploadGroup.Group("")
+	authed.Use(middleware.AuthRequired())
+	{
+		authed.POST("", controllers.CreateUpload)
+		authed.HEAD("/:id", controllers.HeadUpload)
+		authed.GET("/:id", controllers.GetUpload)
+		authed.PATCH("/:id", controllers.PatchUpload)
+		authed.DELETE("/:id", controllers.TerminateUpload)
+		authed.POST("/:id", controllers.OverrideUploadMethod)
+	}
+}
@@ -10,7 +10,54 @@ import (
 func RegisterUserRoutes(r *gin.Engine) {
 	userGroup := NewBaseRoute(r, "/users").Group()
 	{
-		userGroup.POST("", controllers.CreateUser)
+		userGroup.POST("", middleware.Idempotency(), controllers.CreateUser)
 		userGroup.GET("", middleware.AuthRequired(), controllers.GetUsers)
+		userGroup.GET("/:id", middleware.AuthRequired(), controllers.GetUser)
+		userGroup.GET("/:id/posts", middleware.AuthRequired(), controllers.GetUserPosts)
+		userGroup.GET("/:id/avatar", middleware.AuthRequired(), controllers.GetAvatar)
+
+		// Theo dõi user khác
+		userGroup.PUT("/:id/follow", middleware.AuthRequired(), controllers.FollowUser)
+		userGroup.DELETE("/:id/follow", middleware.AuthRequired(), controllers.UnfollowUser)
+		userGroup.GET("/:id/followers", middleware.AuthRequired(), controllers.GetFollowers)
+		userGroup.GET("/:id/following", middleware.AuthRequired(), controllers.GetFollowing)
+
+		// Danh sách đọc công khai của user
+		userGroup.GET("/:id/lists", middleware.AuthRequired(), controllers.GetUserLists)
+
+		// Sửa/xóa user khác chỉ dành cho admin và bắt buộc If-Match
+		userGroup.PUT("/:id", middleware.AuthRequired(), middleware.AdminRequired(), controllers.UpdateUser)
+		userGroup.PATCH("/:id", middleware.AuthRequired(), middleware.AdminRequired(), controllers.PatchUser)
+		userGroup.DELETE("/:id", middleware.AuthRequired(), middleware.AdminRequired(), controllers.DeleteUser)
+
+		// Self-service cho user đang đăng nhập
+		me := userGroup.Group("/me", middleware.AuthRequired())
+		{
+			me.GET("", controllers.GetMe)
+			me.PATCH("", controllers.UpdateMe)
+			// Không lưu idempotency vì response chứa token mới; retry an toàn nhờ kiểm tra mật khẩu hiện tại
+			me.POST("/password", controllers.ChangePassword)
+			me.POST("/email", middleware.Idempotency(), controllers.RequestEmailChange)
+			me.POST("/email/confirm", middleware.Idempotency(), controllers.ConfirmEmailChange)
+			me.GET("/mentions", controllers.GetMyMentions)
+			me.PUT("/avatar", controllers.UploadAvatar)
+			me.DELETE("/avatar", controllers.DeleteAvatar)
+		}
+
+		// Import hàng loạt, chỉ dành cho admin
+		imports := userGroup.Group("/import", middleware.AuthRequired(), middleware.AdminRequired())
+		{
+			imports.POST("", controllers.ImportUsers)
+			imports.GET("/:job_id", controllers.GetImportJob)
+			imports.GET("/:job_id/report", controllers.DownloadImportReport)
+		}
+
+		// Export, chỉ dành cho admin; link tải file đã được ký nên không cần token
+		exports := userGroup.Group("/export")
+		{
+			exports.GET("", middleware.AuthRequired(), middleware.AdminRequired(), controllers.ExportUsers)
+			exports.GET("/jobs/:job_id", middleware.AuthRequired(), middleware.AdminRequired(), controllers.GetExportJob)
+			exports.GET("/files/:file", controllers.DownloadExport)
+		}
 	}
 }
@@ -0,0 +1,101 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// Ký tự nối khi snippet bị cắt
+const ellipsis = "…"
+
+// Snippet cắt đoạn khoảng width ký tự quanh từ khớp đầu tiên trong text, escape HTML và bọc
+// các từ khớp trong <mark>. Một từ khớp khi bắt đầu bằng một trong các terms (như toán tử *
+// của MySQL), không phân biệt hoa thường và dấu. matched = false nếu không có từ nào khớp,
+// khi đó snippet là đoạn đầu của text.
+func Snippet(text string, terms []string, width int) (snippet string, matched bool) {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	folded := make([]string, len(terms))
+	for i, term := range terms {
+		folded[i] = fold(term)
+	}
+
+	// Vị trí [start, end) của các từ khớp
+	var marks [][2]int
+	for start := 0; start < len(runes); {
+		if isSeparator(runes[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(runes) && !isSeparator(runes[end]) {
+			end++
+		}
+		word := fold(string(runes[start:end]))
+		for _, term := range folded {
+			if term != "" && strings.HasPrefix(word, term) {
+				marks = append(marks, [2]int{start, end})
+				break
+			}
+		}
+		start = end
+	}
+
+	from, to := window(runes, marks, width)
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString(ellipsis)
+	}
+	pos := from
+	for _, mark := range marks {
+		if mark[0] < from || mark[1] > to {
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[pos:mark[0]])))
+		b.WriteString("<mark>" + html.EscapeString(string(runes[mark[0]:mark[1]])) + "</mark>")
+		pos = mark[1]
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:to])))
+	if to < len(runes) {
+		b.WriteString(ellipsis)
+	}
+	return b.String(), len(marks) > 0
+}
+
+// window chọn đoạn [from, to) dài tối đa width, bắt đầu trước từ khớp đầu tiên khoảng
+// một phần ba width và không cắt giữa từ
+func window(runes []rune, marks [][2]int, width int) (int, int) {
+	if width <= 0 || len(runes) <= width {
+		return 0, len(runes)
+	}
+	from := 0
+	if len(marks) > 0 {
+		from = marks[0][0] - width/3
+	}
+	if from > len(runes)-width {
+		from = len(runes) - width
+	}
+	if from < 0 {
+		from = 0
+	}
+	if from > 0 {
+		// Bỏ phần từ bị cắt ở đầu, nhưng không vượt qua từ khớp
+		for i := from; i < len(runes) && (len(marks) == 0 || i < marks[0][0]); i++ {
+			if unicode.IsSpace(runes[i-1]) {
+				from = i
+				break
+			}
+		}
+	}
+
+	to := from + width
+	if to >= len(runes) {
+		return from, len(runes)
+	}
+	for i := to; i > from; i-- {
+		if unicode.IsSpace(runes[i]) {
+			return from, i
+		}
+	}
+	return from, to
+}
@@ -0,0 +1,117 @@
+package search
+
+import (
+	"context"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"myapp/models"
+)
+
+// snippetWidth là độ dài (ký tự) tối đa của snippet
+const snippetWidth = 160
+
+// MySQL tìm bằng chỉ mục FULLTEXT (migration 000015) với MATCH ... AGAINST ở BOOLEAN MODE.
+// Điểm liên quan là điểm MATCH của InnoDB; kết quả post và user được gộp bằng UNION ALL.
+type MySQL struct {
+	db *gorm.DB
+}
+
+func NewMySQL(db *gorm.DB) *MySQL {
+	return &MySQL{db: db}
+}
+
+// hitRow là một dòng của truy vấn UNION, body là văn bản để cắt snippet
+type hitRow struct {
+	Type  string
+	ID    uint
+	Title string
+	Body  string
+	Score float64
+}
+
+func (m *MySQL) Search(ctx context.Context, q Query) (*Result, error) {
+	against := booleanQuery(q.Terms)
+	var parts []string
+	var args []interface{}
+
+	if q.Wants(TypePost) {
+		sql := "SELECT 'post' AS `type`, id, title, content AS body, " +
+			"MATCH(title, content) AGAINST (? IN BOOLEAN MODE) AS score FROM posts " +
+			"WHERE MATCH(title, content) AGAINST (? IN BOOLEAN MODE)"
+		args = append(args, against, against)
+		switch {
+		case q.Viewer.Admin:
+		case q.Viewer.UserID != 0:
+			sql += " AND (status = ? OR author_id = ?)"
+			args = append(args, models.PostPublished, q.Viewer.UserID)
+		default:
+			sql += " AND status = ?"
+			args = append(args, models.PostPublished)
+		}
+		parts = append(parts, sql)
+	}
+	if q.Wants(TypeUser) {
+		// Chỉ admin tìm được theo email, tránh dò email của người khác
+		sql := "SELECT 'user' AS `type`, id, name AS title, '' AS body, " +
+			"MATCH(name) AGAINST (? IN BOOLEAN MODE) AS score FROM users " +
+			"WHERE MATCH(name) AGAINST (? IN BOOLEAN MODE)"
+		if q.Viewer.Admin {
+			sql = "SELECT 'user' AS `type`, id, name AS title, email AS body, " +
+				"MATCH(name, email) AGAINST (? IN BOOLEAN MODE) AS score FROM users " +
+				"WHERE MATCH(name, email) AGAINST (? IN BOOLEAN MODE)"
+		}
+		args = append(args, against, against)
+		parts = append(parts, sql)
+	}
+
+	union := strings.Join(parts, " UNION ALL ")
+	db := m.db.WithContext(ctx)
+
+	var total int64
+	if err := db.Raw("SELECT COUNT(*) FROM ("+union+") AS hits", args...).Scan(&total).Error; err != nil {
+		return nil, err
+	}
+	result := &Result{Hits: []Hit{}, Total: total}
+	if total == 0 {
+		return result, nil
+	}
+
+	var rows []hitRow
+	err := db.Raw(union+" ORDER BY score DESC, `type`, id DESC LIMIT ? OFFSET ?",
+		append(args, q.Limit, q.Offset)...).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		result.Hits = append(result.Hits, Hit{
+			Type:    row.Type,
+			ID:      row.ID,
+			Title:   row.Title,
+			Snippet: snippetOf(row, q.Terms),
+			Score:   row.Score,
+		})
+	}
+	return result, nil
+}
+
+// snippetOf lấy snippet từ body nếu có từ khớp, ngược lại từ title
+func snippetOf(row hitRow, terms []string) string {
+	if row.Body != "" {
+		if snippet, ok := Snippet(row.Body, terms, snippetWidth); ok {
+			return snippet
+		}
+	}
+	snippet, _ := Snippet(row.Title, terms, snippetWidth)
+	return snippet
+}
+
+// booleanQuery yêu cầu mọi từ khóa cùng xuất hiện, mỗi từ khớp theo tiền tố: "+go* +web*"
+func booleanQuery(terms []string) string {
+	words := make([]string, len(terms))
+	for i, term := range terms {
+		words[i] = "+" + term + "*"
+	}
+	return strings.Join(words, " ")
+}
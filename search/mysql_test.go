@@ -0,0 +1,98 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"myapp/models"
+)
+
+func setupTestDB(t *testing.T) (sqlmock.Sqlmock, *gorm.DB) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	assert.NoError(t, err)
+
+	return mock, gormDB
+}
+
+var hitColumns = []string{"type", "id", "title", "body", "score"}
+
+func TestMySQLSearch_PostsAndUsersForUser(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM \\(SELECT 'post' .* AND \\(status = \\? OR author_id = \\?\\) "+
+		"UNION ALL SELECT 'user' .* MATCH\\(name\\) AGAINST").
+		WithArgs("+go* +web*", "+go* +web*", models.PostPublished, 7, "+go* +web*", "+go* +web*").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery("ORDER BY score DESC, `type`, id DESC LIMIT \\? OFFSET \\?").
+		WithArgs("+go* +web*", "+go* +web*", models.PostPublished, 7, "+go* +web*", "+go* +web*", 20, 0).
+		WillReturnRows(sqlmock.NewRows(hitColumns).
+			AddRow("post", 3, "Go for the web", "Building web apps in Go", 2.5).
+			AddRow("user", 9, "Webster Gore", "", 0.8))
+
+	result, err := NewMySQL(gormDB).Search(context.Background(), Query{
+		Terms:  []string{"go", "web"},
+		Viewer: Viewer{UserID: 7},
+		Limit:  20,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), result.Total)
+	assert.Equal(t, []Hit{
+		{Type: TypePost, ID: 3, Title: "Go for the web", Snippet: "Building <mark>web</mark> apps in <mark>Go</mark>", Score: 2.5},
+		{Type: TypeUser, ID: 9, Title: "Webster Gore", Snippet: "<mark>Webster</mark> <mark>Gore</mark>", Score: 0.8},
+	}, result.Hits)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMySQLSearch_AnonymousSeesPublishedPostsOnly(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	mock.ExpectQuery("FROM posts WHERE MATCH\\(title, content\\) AGAINST \\(\\? IN BOOLEAN MODE\\) AND status = \\?\\) AS hits").
+		WithArgs("+go*", "+go*", models.PostPublished).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	result, err := NewMySQL(gormDB).Search(context.Background(), Query{
+		Terms: []string{"go"},
+		Types: []string{TypePost},
+		Limit: 20,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), result.Total)
+	assert.Empty(t, result.Hits)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMySQLSearch_AdminMatchesEmail(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM \\(SELECT 'user' .* email AS body, MATCH\\(name, email\\)").
+		WithArgs("+john*", "+john*").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("ORDER BY score DESC").
+		WithArgs("+john*", "+john*", 10, 10).
+		WillReturnRows(sqlmock.NewRows(hitColumns).AddRow("user", 1, "Admin", "john@example.com", 1.2))
+
+	result, err := NewMySQL(gormDB).Search(context.Background(), Query{
+		Terms:  []string{"john"},
+		Types:  []string{TypeUser},
+		Viewer: Viewer{UserID: 1, Admin: true},
+		Offset: 10,
+		Limit:  10,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "<mark>john</mark>@example.com", result.Hits[0].Snippet)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,142 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+	"gorm.io/gorm"
+)
+
+// Loại tài liệu có thể tìm
+const (
+	TypePost = "post"
+	TypeUser = "user"
+)
+
+// maxTerms giới hạn số từ khóa của một truy vấn
+const maxTerms = 10
+
+// Viewer là người đang tìm kiếm, backend dùng để lọc các bản ghi được phép thấy:
+// post đã xuất bản (kèm post của chính viewer, admin thấy tất cả), email user chỉ admin tìm được
+type Viewer struct {
+	UserID uint // 0 = chưa đăng nhập
+	Admin  bool
+}
+
+// Query là một truy vấn tìm kiếm đã được kiểm tra
+type Query struct {
+	Terms  []string // từ khóa đã chuẩn hóa, xem Terms()
+	Types  []string // TypePost / TypeUser, rỗng = tất cả
+	Viewer Viewer
+	Offset int
+	Limit  int
+}
+
+// Wants cho biết truy vấn có tìm loại tài liệu docType hay không
+func (q Query) Wants(docType string) bool {
+	if len(q.Types) == 0 {
+		return true
+	}
+	for _, t := range q.Types {
+		if t == docType {
+			return true
+		}
+	}
+	return false
+}
+
+// Hit là một kết quả, xếp theo Score giảm dần
+type Hit struct {
+	Type    string  `json:"type"`
+	ID      uint    `json:"id"`
+	Title   string  `json:"title"`   // title của post, name của user
+	Snippet string  `json:"snippet"` // HTML đã escape, từ khớp bọc trong <mark>
+	Score   float64 `json:"score"`
+}
+
+// Result là một trang kết quả cùng tổng số kết quả
+type Result struct {
+	Hits  []Hit
+	Total int64
+}
+
+// Searcher là backend tìm kiếm. Backend có index riêng (ví dụ Bleve cho database không hỗ trợ
+// full-text) tự đồng bộ index khi được tạo, chẳng hạn đăng ký callback GORM trên db,
+// và phải áp dụng Viewer như backend MySQL.
+type Searcher interface {
+	Search(ctx context.Context, q Query) (*Result, error)
+}
+
+// Factory tạo backend từ kết nối database
+type Factory func(db *gorm.DB) (Searcher, error)
+
+var backends = map[string]Factory{
+	"mysql": func(db *gorm.DB) (Searcher, error) { return NewMySQL(db), nil },
+}
+
+// Register thêm một backend, chọn qua SEARCH_BACKEND
+func Register(name string, factory Factory) {
+	backends[name] = factory
+}
+
+// New tạo backend đã đăng ký với tên name
+func New(name string, db *gorm.DB) (Searcher, error) {
+	factory, ok := backends[name]
+	if !ok {
+		names := make([]string, 0, len(backends))
+		for n := range backends {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown search backend %q (available: %s)", name, strings.Join(names, ", "))
+	}
+	return factory(db)
+}
+
+// Terms tách chuỗi tìm kiếm thành các từ khóa (chữ thường, không trùng, tối đa maxTerms).
+// Ký tự không phải chữ/số bị bỏ nên toán tử của MySQL (+ - * " ...) không lọt vào truy vấn.
+func Terms(text string) []string {
+	var terms []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), isSeparator) {
+		if !containsString(terms, word) {
+			terms = append(terms, word)
+		}
+		if len(terms) == maxTerms {
+			break
+		}
+	}
+	return terms
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsNumber(r) && !unicode.Is(unicode.Mn, r)
+}
+
+// fold bỏ dấu và đưa về chữ thường ("Việt" -> "viet", "Đà" -> "da"), giống collation
+// utf8mb4_unicode_ci của MySQL khi so khớp
+func fold(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(s)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case r == 'đ':
+			b.WriteByte('d')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTerms_DropsOperatorsAndDuplicates(t *testing.T) {
+	assert.Equal(t, []string{"go", "web", "việt"}, Terms(`+Go -"web" go* Việt`))
+	assert.Empty(t, Terms(`+-*"()~<>`))
+}
+
+func TestTerms_Limit(t *testing.T) {
+	assert.Len(t, Terms("a b c d e f g h i j k l"), maxTerms)
+}
+
+func TestFold(t *testing.T) {
+	assert.Equal(t, "tieng viet da nang", fold("Tiếng Việt Đà Nẵng"))
+}
+
+func TestNew_UnknownBackend(t *testing.T) {
+	_, err := New("bleve", nil)
+	assert.EqualError(t, err, `unknown search backend "bleve" (available: mysql)`)
+}
+
+func TestSnippet_HighlightsPrefixMatches(t *testing.T) {
+	snippet, matched := Snippet("Learning Go: goroutines & <channels>", []string{"go"}, 0)
+
+	assert.True(t, matched)
+	assert.Equal(t, "Learning <mark>Go</mark>: <mark>goroutines</mark> &amp; &lt;channels&gt;", snippet)
+}
+
+func TestSnippet_IgnoresCaseAndDiacritics(t *testing.T) {
+	snippet, matched := Snippet("Du lịch Đà Nẵng", []string{"da", "nang"}, 0)
+
+	assert.True(t, matched)
+	assert.Equal(t, "Du lịch <mark>Đà</mark> <mark>Nẵng</mark>", snippet)
+}
+
+func TestSnippet_WindowAroundFirstMatch(t *testing.T) {
+	text := "one two three four five six seven eight nine ten eleven twelve target thirteen fourteen fifteen sixteen"
+	snippet, matched := Snippet(text, []string{"target"}, 40)
+
+	assert.True(t, matched)
+	assert.Equal(t, "…twelve <mark>target</mark> thirteen fourteen fifteen…", snippet)
+}
+
+func TestSnippet_NoMatch(t *testing.T) {
+	snippet, matched := Snippet("alpha beta\n\ngamma delta", []string{"zeta"}, 12)
+
+	assert.False(t, matched)
+	assert.Equal(t, "alpha beta…", snippet)
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"path"
+
+	"myapp/blobstore"
+)
+
+// StoreUpload ghi file đã xử lý (và thumbnail nếu có) vào store, trong thư mục prefix.
+// Ghi thumbnail lỗi thì file chính bị xóa lại. Trả về key của file và thumbnail.
+func StoreUpload(ctx context.Context, store blobstore.Storage, upload *PreparedUpload, prefix string) (string, *string, error) {
+	key := blobstore.NewKey(prefix, upload.Ext)
+	if err := store.Put(ctx, key, upload.Body, upload.Size, upload.ContentType); err != nil {
+		return "", nil, err
+	}
+	if upload.Thumbnail == nil {
+		return key, nil, nil
+	}
+
+	thumbKey := blobstore.NewKey(path.Join(prefix, "thumbs"), upload.ThumbnailExt)
+	if err := store.Put(ctx, thumbKey, bytes.NewReader(upload.Thumbnail), int64(len(upload.Thumbnail)), upload.ThumbnailType); err != nil {
+		DeleteFiles(ctx, store, key)
+		return "", nil, err
+	}
+	return key, &thumbKey, nil
+}
+
+// DeleteFiles xóa các file khỏi store; lỗi chỉ ghi log vì dữ liệu trong DB đã được xóa
+// (file sót lại không ảnh hưởng tới người dùng)
+func DeleteFiles(ctx context.Context, store blobstore.Storage, keys ...string) {
+	for _, key := range keys {
+		if err := store.Delete(ctx, key); err != nil {
+			log.Printf("❌ Xóa file %s thất bại: %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"myapp/models"
+)
+
+// Follow cho followerID theo dõi followeeID, không làm gì nếu đã theo dõi.
+// Bộ đếm của hai user chỉ tăng khi thực sự thêm dòng mới, trong cùng transaction.
+// Trả về true nếu vừa theo dõi.
+func Follow(db *gorm.DB, followerID, followeeID uint) (bool, error) {
+	added := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		follow := models.Follow{FollowerID: followerID, FolloweeID: followeeID}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&follow)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		added = true
+		return updateFollowCounts(tx, followerID, followeeID, 1)
+	})
+	return added, err
+}
+
+// Unfollow bỏ theo dõi, không làm gì nếu chưa theo dõi. Trả về true nếu vừa bỏ theo dõi.
+func Unfollow(db *gorm.DB, followerID, followeeID uint) (bool, error) {
+	removed := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("follower_id = ? AND followee_id = ?", followerID, followeeID).Delete(&models.Follow{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		removed = true
+		return updateFollowCounts(tx, followerID, followeeID, -1)
+	})
+	return removed, err
+}
+
+// updateFollowCounts cộng delta (1 hoặc -1) vào bộ đếm của hai user, không xuống dưới 0.
+// Các cột bộ đếm chỉ đọc với GORM nên cập nhật bằng SQL trực tiếp (cũng không tăng version của user).
+func updateFollowCounts(tx *gorm.DB, followerID, followeeID uint, delta int) error {
+	following := "UPDATE users SET following_count = following_count + 1 WHERE id = ?"
+	followers := "UPDATE users SET follower_count = follower_count + 1 WHERE id = ?"
+	if delta < 0 {
+		following = "UPDATE users SET following_count = following_count - 1 WHERE id = ? AND following_count > 0"
+		followers = "UPDATE users SET follower_count = follower_count - 1 WHERE id = ? AND follower_count > 0"
+	}
+	if err := tx.Exec(following, followerID).Error; err != nil {
+		return err
+	}
+	return tx.Exec(followers, followeeID).Error
+}
+
+// IsFollowing cho biết followerID có đang theo dõi followeeID hay không
+func IsFollowing(db *gorm.DB, followerID, followeeID uint) (bool, error) {
+	var count int64
+	err := db.Model(&models.Follow{}).Where("follower_id = ? AND followee_id = ?", followerID, followeeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// RepairFollowCounts tính lại bộ đếm follow của mọi user từ bảng follows.
+// Trả về số user có bộ đếm thay đổi.
+func RepairFollowCounts(db *gorm.DB) (int64, error) {
+	result := db.Exec(`UPDATE users SET
+		follower_count = (SELECT COUNT(*) FROM follows WHERE follows.followee_id = users.id),
+		following_count = (SELECT COUNT(*) FROM follows WHERE follows.follower_id = users.id)`)
+	return result.RowsAffected, result.Error
+}
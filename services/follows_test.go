@@ -0,0 +1,82 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFollow_IncrementsCountsOnlyWhenInserted(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `follows` .* ON DUPLICATE KEY UPDATE").
+		WithArgs(7, 9, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET following_count = following_count \\+ 1 WHERE id = \\?").
+		WithArgs(7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET follower_count = follower_count \\+ 1 WHERE id = \\?").
+		WithArgs(9).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	added, err := Follow(gormDB, 7, 9)
+
+	assert.NoError(t, err)
+	assert.True(t, added)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFollow_AlreadyFollowing(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `follows`").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	added, err := Follow(gormDB, 7, 9)
+
+	assert.NoError(t, err)
+	assert.False(t, added)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUnfollow_DecrementsCounts(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `follows` WHERE follower_id = \\? AND followee_id = \\?").
+		WithArgs(7, 9).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET following_count = following_count - 1 WHERE id = \\? AND following_count > 0").
+		WithArgs(7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users SET follower_count = follower_count - 1 WHERE id = \\? AND follower_count > 0").
+		WithArgs(9).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	removed, err := Unfollow(gormDB, 7, 9)
+
+	assert.NoError(t, err)
+	assert.True(t, removed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUnfollow_NotFollowing(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `follows`").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	removed, err := Unfollow(gormDB, 7, 9)
+
+	assert.NoError(t, err)
+	assert.False(t, removed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
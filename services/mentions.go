@@ -0,0 +1,117 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"myapp/markdown"
+	"myapp/models"
+)
+
+// MentionLink là đường dẫn tới user được nhắc trong HTML đã render; dùng id nên link
+// vẫn đúng khi user đổi handle
+func MentionLink(userID uint) string {
+	return fmt.Sprintf("/api/users/%d", userID)
+}
+
+// ResolveMentions trả về các user có handle được nhắc trong content (chỉ id và handle).
+// Handle không thuộc về ai bị bỏ qua.
+func ResolveMentions(db *gorm.DB, content string) ([]models.User, error) {
+	handles := markdown.Mentions(content)
+	if len(handles) == 0 {
+		return nil, nil
+	}
+	var users []models.User
+	err := db.Select("id", "handle").Where("handle IN ?", handles).Order("id").Find(&users).Error
+	return users, err
+}
+
+// RenderContent render Markdown, các @handle của user có thật thành link tới user đó.
+// Trả về kèm danh sách user được nhắc.
+func RenderContent(db *gorm.DB, content string) (string, []models.User, error) {
+	users, err := ResolveMentions(db, content)
+	if err != nil || len(users) == 0 {
+		return markdown.Render(content), nil, err
+	}
+	links := make(map[string]string, len(users))
+	for _, user := range users {
+		links[strings.ToLower(user.Handle)] = MentionLink(user.ID)
+	}
+	return markdown.RenderWithMentions(content, links), users, nil
+}
+
+// SaveMentions thay danh sách mention của nội dung target (AuthorID, TargetType, TargetID, PostID)
+// bằng users, gọi trong transaction ghi nội dung. Tác giả tự nhắc mình không được lưu.
+// Mention đã có được giữ nguyên nên không bị thông báo lại.
+func SaveMentions(tx *gorm.DB, target models.Mention, users []models.User) error {
+	var ids []uint
+	for _, user := range users {
+		if user.ID != target.AuthorID {
+			ids = append(ids, user.ID)
+		}
+	}
+
+	stale := tx.Where("target_type = ? AND target_id = ?", target.TargetType, target.TargetID)
+	if len(ids) > 0 {
+		stale = stale.Where("user_id NOT IN ?", ids)
+	}
+	if err := stale.Delete(&models.Mention{}).Error; err != nil || len(ids) == 0 {
+		return err
+	}
+
+	mentions := make([]models.Mention, len(ids))
+	for i, id := range ids {
+		mentions[i] = models.Mention{
+			UserID:     id,
+			AuthorID:   target.AuthorID,
+			TargetType: target.TargetType,
+			TargetID:   target.TargetID,
+			PostID:     target.PostID,
+		}
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&mentions).Error
+}
+
+// NotifyMentions gửi thông báo cho các mention chưa thông báo trong post postID và comment của nó.
+// Chỉ gửi khi post đã xuất bản (mention trong draft chờ tới lúc xuất bản) và bỏ qua comment
+// đã xóa hoặc bị ẩn. Mỗi mention chỉ được thông báo một lần. Trả về số thông báo đã gửi.
+func NotifyMentions(db *gorm.DB, postID uint, now time.Time) (int, error) {
+	var pending []models.Mention
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("mentions.*").
+			Joins("JOIN posts ON posts.id = mentions.post_id").
+			Joins("LEFT JOIN comments ON mentions.target_type = ? AND comments.id = mentions.target_id", models.MentionTargetComment).
+			Where("mentions.post_id = ? AND mentions.notified_at IS NULL AND posts.status = ?", postID, models.PostPublished).
+			Where("comments.deleted_at IS NULL AND comments.hidden_at IS NULL").
+			Order("mentions.id").
+			Find(&pending).Error; err != nil || len(pending) == 0 {
+			return err
+		}
+		ids := make([]uint, len(pending))
+		for i, mention := range pending {
+			ids[i] = mention.ID
+		}
+		return tx.Model(&models.Mention{}).Where("id IN ?", ids).UpdateColumn("notified_at", now).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, mention := range pending {
+		postID := mention.PostID
+		Notify(NotificationEvent{
+			Type:        models.NotificationMention,
+			RecipientID: mention.UserID,
+			ActorID:     mention.AuthorID,
+			TargetType:  mention.TargetType,
+			TargetID:    mention.TargetID,
+			PostID:      &postID,
+		})
+	}
+	return len(pending), nil
+}
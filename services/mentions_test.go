@@ -0,0 +1,122 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"myapp/models"
+)
+
+func TestRenderContent_LinksKnownHandles(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	mock.ExpectQuery("SELECT `id`,`handle` FROM `users` WHERE handle IN \\(\\?,\\?\\) ORDER BY id").
+		WithArgs("alice", "ghost").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "handle"}).AddRow(4, "alice"))
+
+	html, users, err := RenderContent(gormDB, "Hi @Alice and @ghost")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []models.User{{ID: 4, Handle: "alice"}}, users)
+	assert.Contains(t, html, `<a href="/api/users/4"`)
+	assert.Contains(t, html, "@ghost</p>")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRenderContent_NoMentionsSkipsQuery(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	html, users, err := RenderContent(gormDB, "mail me@example.com")
+
+	assert.NoError(t, err)
+	assert.Empty(t, users)
+	assert.Equal(t, "<p>mail me@example.com</p>\n", html)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSaveMentions_ReplacesAndSkipsAuthor(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	target := models.Mention{AuthorID: 1, TargetType: models.MentionTargetComment, TargetID: 9, PostID: 2}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `mentions` WHERE \\(target_type = \\? AND target_id = \\?\\) AND user_id NOT IN \\(\\?\\)").
+		WithArgs("comment", 9, 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO `mentions` .* ON DUPLICATE KEY UPDATE `id`=`id`").
+		WithArgs(4, 1, "comment", 9, 2, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(5, 1))
+	mock.ExpectCommit()
+
+	err := gormDB.Transaction(func(tx *gorm.DB) error {
+		return SaveMentions(tx, target, []models.User{{ID: 1}, {ID: 4}})
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSaveMentions_NoMentionsDeletesAll(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	target := models.Mention{AuthorID: 1, TargetType: models.MentionTargetPost, TargetID: 2, PostID: 2}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `mentions` WHERE target_type = \\? AND target_id = \\?$").
+		WithArgs("post", 2).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	err := gormDB.Transaction(func(tx *gorm.DB) error {
+		return SaveMentions(tx, target, []models.User{{ID: 1}})
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotifyMentions_MarksAndQueues(t *testing.T) {
+	defer func(queue chan NotificationEvent) { notificationQueue = queue }(notificationQueue)
+	notificationQueue = make(chan NotificationEvent, 2)
+
+	mock, gormDB := setupTestDB(t)
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT mentions.\\* FROM `mentions` JOIN posts .* LEFT JOIN comments .* WHERE \\(mentions.post_id = \\? AND mentions.notified_at IS NULL AND posts.status = \\?\\) .* FOR UPDATE").
+		WithArgs("comment", 2, models.PostPublished).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "author_id", "target_type", "target_id", "post_id"}).
+			AddRow(5, 4, 1, "post", 2, 2).
+			AddRow(6, 3, 7, "comment", 9, 2))
+	mock.ExpectExec("UPDATE `mentions` SET `notified_at`=\\? WHERE id IN \\(\\?,\\?\\)").
+		WithArgs(now, 5, 6).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	sent, err := NotifyMentions(gormDB, 2, now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	first, second := <-notificationQueue, <-notificationQueue
+	assert.Equal(t, NotificationEvent{Type: models.NotificationMention, RecipientID: 4, ActorID: 1, TargetType: "post", TargetID: 2, PostID: first.PostID}, first)
+	assert.Equal(t, uint(2), *first.PostID)
+	assert.Equal(t, uint(3), second.RecipientID)
+	assert.Equal(t, "comment", second.TargetType)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotifyMentions_NothingPending(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT mentions.\\* FROM `mentions`").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectCommit()
+
+	sent, err := NotifyMentions(gormDB, 2, time.Now())
+
+	assert.NoError(t, err)
+	assert.Zero(t, sent)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"myapp/models"
+)
+
+// ErrInvalidModerationTarget là lỗi khi hành động không áp dụng được cho loại đối tượng
+var ErrInvalidModerationTarget = errors.New("action does not apply to this target")
+
+// ModerationAction là một hành động của moderator trên một đối tượng
+type ModerationAction struct {
+	ModeratorID uint
+	Action      string
+	TargetType  string
+	TargetID    uint
+	ReportID    *uint
+	Note        string
+	// SuspendFor là thời hạn khóa tài khoản, 0 = vô thời hạn (chỉ dùng với suspend)
+	SuspendFor time.Duration
+}
+
+// ApplyModeration thực hiện hành động rồi ghi vào moderation_log, trong cùng transaction tx.
+// hide/unhide áp dụng cho post và comment, suspend/unsuspend cho user (đối tượng khác thì
+// controller tự đổi sang tác giả), dismiss chỉ ghi log. Thực hiện lại hành động đã có hiệu lực
+// không lỗi.
+func ApplyModeration(tx *gorm.DB, action ModerationAction, now time.Time) error {
+	var err error
+	switch action.Action + ":" + action.TargetType {
+	case models.ModerationHide + ":" + models.ReportTargetPost:
+		err = tx.Exec("UPDATE posts SET status = ?, scheduled_for = NULL WHERE id = ? AND status <> ?",
+			models.PostHidden, action.TargetID, models.PostHidden).Error
+	case models.ModerationUnhide + ":" + models.ReportTargetPost:
+		// Post bị ẩn trở lại published nếu đã từng xuất bản, không thì về draft
+		err = tx.Exec("UPDATE posts SET status = IF(published_at IS NULL, ?, ?) WHERE id = ? AND status = ?",
+			models.PostDraft, models.PostPublished, action.TargetID, models.PostHidden).Error
+	case models.ModerationHide + ":" + models.ReportTargetComment:
+		// Cột hidden_at chỉ đọc với GORM nên cập nhật bằng SQL trực tiếp
+		err = tx.Exec("UPDATE comments SET hidden_at = ? WHERE id = ? AND hidden_at IS NULL", now, action.TargetID).Error
+	case models.ModerationUnhide + ":" + models.ReportTargetComment:
+		err = tx.Exec("UPDATE comments SET hidden_at = NULL WHERE id = ?", action.TargetID).Error
+	case models.ModerationSuspend + ":" + models.ReportTargetUser:
+		var until *time.Time
+		if action.SuspendFor > 0 {
+			t := now.Add(action.SuspendFor)
+			until = &t
+		}
+		err = tx.Exec("UPDATE users SET suspended_at = ?, suspended_until = ? WHERE id = ?", now, until, action.TargetID).Error
+	case models.ModerationUnsuspend + ":" + models.ReportTargetUser:
+		err = tx.Exec("UPDATE users SET suspended_at = NULL, suspended_until = NULL WHERE id = ?", action.TargetID).Error
+	default:
+		if action.Action != models.ModerationDismiss {
+			return ErrInvalidModerationTarget
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Create(&models.ModerationLogEntry{
+		ModeratorID: &action.ModeratorID,
+		Action:      action.Action,
+		TargetType:  action.TargetType,
+		TargetID:    action.TargetID,
+		ReportID:    action.ReportID,
+		Note:        action.Note,
+	}).Error
+}
+
+// ResolveReports đóng mọi report còn mở trên đối tượng của report (kể cả report của người khác),
+// ghi lại hành động đã áp dụng. dismiss đóng report ở trạng thái dismissed. Trả về số report đã đóng.
+func ResolveReports(tx *gorm.DB, report *models.Report, action string, moderatorID uint, note string, now time.Time) (int64, error) {
+	status := models.ReportResolved
+	if action == models.ModerationDismiss {
+		status = models.ReportDismissed
+	}
+	result := tx.Model(&models.Report{}).
+		Where("target_type = ? AND target_id = ? AND status IN ?", report.TargetType, report.TargetID,
+			[]string{models.ReportOpen, models.ReportInReview}).
+		Updates(map[string]interface{}{
+			"status":          status,
+			"resolution":      action,
+			"resolution_note": note,
+			"resolved_by":     moderatorID,
+			"resolved_at":     now,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// FlagContent tạo report hệ thống cho nội dung chứa từ cấm, trừ khi đối tượng đã có
+// report hệ thống đang mở. Trả về report vừa tạo (nil nếu bỏ qua).
+func FlagContent(db *gorm.DB, targetType string, targetID uint, matched []string) (*models.Report, error) {
+	var report *models.Report
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var open int64
+		if err := tx.Model(&models.Report{}).
+			Where("reporter_id IS NULL AND target_type = ? AND target_id = ? AND status IN ?", targetType, targetID,
+				[]string{models.ReportOpen, models.ReportInReview}).
+			Count(&open).Error; err != nil || open > 0 {
+			return err
+		}
+
+		details := "Matched banned words: " + strings.Join(matched, ", ")
+		report = &models.Report{
+			TargetType: targetType,
+			TargetID:   targetID,
+			Reason:     models.ReportReasonBannedWords,
+			Details:    details,
+			Status:     models.ReportOpen,
+		}
+		if err := tx.Create(report).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.ModerationLogEntry{
+			Action:     models.ModerationFlag,
+			TargetType: targetType,
+			TargetID:   targetID,
+			ReportID:   &report.ID,
+			Note:       details,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"myapp/models"
+)
+
+func TestApplyModeration_HidePost(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	reportID := uint(3)
+
+	mock.ExpectExec("UPDATE posts SET status = \\?, scheduled_for = NULL WHERE id = \\? AND status <> \\?").
+		WithArgs(models.PostHidden, 5, models.PostHidden).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `moderation_log`").
+		WithArgs(1, models.ModerationHide, models.ReportTargetPost, 5, reportID, "spam", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := ApplyModeration(gormDB, ModerationAction{
+		ModeratorID: 1, Action: models.ModerationHide, TargetType: models.ReportTargetPost, TargetID: 5,
+		ReportID: &reportID, Note: "spam",
+	}, time.Now())
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestApplyModeration_SuspendUser(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec("UPDATE users SET suspended_at = \\?, suspended_until = \\? WHERE id = \\?").
+		WithArgs(now, now.Add(7*24*time.Hour), 9).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `moderation_log`").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := ApplyModeration(gormDB, ModerationAction{
+		ModeratorID: 1, Action: models.ModerationSuspend, TargetType: models.ReportTargetUser, TargetID: 9,
+		SuspendFor: 7 * 24 * time.Hour,
+	}, now)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestApplyModeration_InvalidTarget(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	err := ApplyModeration(gormDB, ModerationAction{
+		ModeratorID: 1, Action: models.ModerationHide, TargetType: models.ReportTargetUser, TargetID: 9,
+	}, time.Now())
+
+	assert.ErrorIs(t, err, ErrInvalidModerationTarget)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResolveReports_ClosesAllOpenReportsOnTarget(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `reports` SET .* WHERE target_type = \\? AND target_id = \\? AND status IN \\(\\?,\\?\\)").
+		WithArgs(models.ModerationDismiss, "not spam", now, 1, models.ReportDismissed, sqlmock.AnyArg(),
+			models.ReportTargetComment, 8, models.ReportOpen, models.ReportInReview).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	closed, err := ResolveReports(gormDB, &models.Report{TargetType: models.ReportTargetComment, TargetID: 8},
+		models.ModerationDismiss, 1, "not spam", now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), closed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFlagContent(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `reports` WHERE reporter_id IS NULL AND target_type = \\? AND target_id = \\?").
+		WithArgs(models.ReportTargetPost, 5, models.ReportOpen, models.ReportInReview).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectExec("INSERT INTO `reports`").
+		WithArgs(nil, models.ReportTargetPost, 5, models.ReportReasonBannedWords, "Matched banned words: spam, scam",
+			models.ReportOpen, nil, nil, "", nil, nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(12, 1))
+	mock.ExpectExec("INSERT INTO `moderation_log`").
+		WithArgs(nil, models.ModerationFlag, models.ReportTargetPost, 5, 12, "Matched banned words: spam, scam", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	report, err := FlagContent(gormDB, models.ReportTargetPost, 5, []string{"spam", "scam"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(12), report.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFlagContent_AlreadyFlagged(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `reports`").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectCommit()
+
+	report, err := FlagContent(gormDB, models.ReportTargetPost, 5, []string{"spam"})
+
+	assert.NoError(t, err)
+	assert.Nil(t, report)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
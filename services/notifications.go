@@ -0,0 +1,198 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"myapp/models"
+)
+
+// NotificationEvent là một sự kiện cần thông báo cho RecipientID
+type NotificationEvent struct {
+	Type        string
+	RecipientID uint
+	ActorID     uint
+	TargetType  string
+	TargetID    uint
+	PostID      *uint
+}
+
+// groupKey là khóa gộp: các sự kiện cùng loại trên cùng đối tượng được gộp vào một thông báo chưa đọc
+// (mọi người theo dõi mới được gộp chung một thông báo)
+func (ev NotificationEvent) groupKey() string {
+	if ev.Type == models.NotificationFollow {
+		return ev.Type
+	}
+	return fmt.Sprintf("%s:%s:%d", ev.Type, ev.TargetType, ev.TargetID)
+}
+
+var (
+	// notificationQueue nhận sự kiện từ Notify; nil khi chưa gọi StartNotificationDelivery
+	// hoặc đã gọi StopNotificationDelivery
+	notificationQueue chan NotificationEvent
+	// notificationMu giữ để Notify không gửi vào hàng đợi đã đóng
+	notificationMu sync.RWMutex
+	// notificationDone được đóng khi goroutine ghi thông báo đã xử lý hết hàng đợi
+	notificationDone chan struct{}
+)
+
+// Notify đưa sự kiện vào hàng đợi rồi trả về ngay, không chờ ghi DB, nên lỗi hay chậm khi ghi
+// thông báo không ảnh hưởng tới request gốc. Hàng đợi đầy thì sự kiện bị bỏ (có ghi log).
+func Notify(ev NotificationEvent) {
+	notificationMu.RLock()
+	defer notificationMu.RUnlock()
+	if notificationQueue == nil {
+		return
+	}
+	select {
+	case notificationQueue <- ev:
+	default:
+		log.Printf("⚠️ Hàng đợi thông báo đầy, bỏ thông báo %s cho user %d", ev.Type, ev.RecipientID)
+	}
+}
+
+// StartNotificationDelivery chạy nền việc ghi thông báo từ hàng đợi (tối đa size sự kiện chờ).
+// Chỉ một goroutine ghi nên các sự kiện cùng nhóm không tranh nhau tạo thông báo.
+func StartNotificationDelivery(db *gorm.DB, size int) {
+	notificationMu.Lock()
+	defer notificationMu.Unlock()
+	notificationQueue = make(chan NotificationEvent, size)
+	notificationDone = make(chan struct{})
+	go func(queue <-chan NotificationEvent, done chan<- struct{}) {
+		defer close(done)
+		for ev := range queue {
+			if err := DeliverNotification(db, ev); err != nil {
+				log.Printf("❌ Ghi thông báo %s cho user %d thất bại: %v", ev.Type, ev.RecipientID, err)
+			}
+		}
+	}(notificationQueue, notificationDone)
+}
+
+// StopNotificationDelivery ngừng nhận sự kiện mới (Notify sau đó bị bỏ qua) và chờ ghi hết
+// các sự kiện còn trong hàng đợi. Gọi khi tắt server, sau khi các request đã xong.
+func StopNotificationDelivery() {
+	notificationMu.Lock()
+	if notificationQueue == nil {
+		notificationMu.Unlock()
+		return
+	}
+	close(notificationQueue)
+	notificationQueue = nil
+	done := notificationDone
+	notificationMu.Unlock()
+	<-done
+}
+
+// DeliverNotification ghi sự kiện thành thông báo. Bỏ qua sự kiện user tự gây ra cho mình
+// và loại thông báo user đã tắt. Nếu đã có thông báo chưa đọc cùng nhóm thì gộp vào đó:
+// actor_count chỉ tăng khi người gây ra sự kiện chưa có trong nhóm.
+func DeliverNotification(db *gorm.DB, ev NotificationEvent) error {
+	if ev.RecipientID == 0 || ev.RecipientID == ev.ActorID {
+		return nil
+	}
+	enabled, err := NotificationEnabled(db, ev.RecipientID, ev.Type)
+	if err != nil || !enabled {
+		return err
+	}
+
+	key := ev.groupKey()
+	return db.Transaction(func(tx *gorm.DB) error {
+		var open models.Notification
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+			Where("user_id = ? AND group_key = ?", ev.RecipientID, key).First(&open).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			notification := models.Notification{
+				UserID:     ev.RecipientID,
+				Type:       ev.Type,
+				ActorID:    ev.ActorID,
+				ActorCount: 1,
+				TargetType: ev.TargetType,
+				TargetID:   ev.TargetID,
+				PostID:     ev.PostID,
+				GroupKey:   &key,
+			}
+			if err := tx.Create(&notification).Error; err != nil {
+				return err
+			}
+			return tx.Create(&models.NotificationActor{NotificationID: notification.ID, ActorID: ev.ActorID}).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		actor := models.NotificationActor{NotificationID: open.ID, ActorID: ev.ActorID}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&actor)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Exec("UPDATE notifications SET actor_id = ?, actor_count = actor_count + 1, updated_at = ? WHERE id = ?",
+			ev.ActorID, time.Now(), open.ID).Error
+	})
+}
+
+// NotificationEnabled cho biết user có nhận loại thông báo này không (mặc định là có)
+func NotificationEnabled(db *gorm.DB, userID uint, notificationType string) (bool, error) {
+	var disabled int64
+	err := db.Model(&models.NotificationPreference{}).
+		Where("user_id = ? AND type = ? AND enabled = ?", userID, notificationType, false).
+		Count(&disabled).Error
+	return disabled == 0, err
+}
+
+// NotificationPreferences trả về trạng thái bật/tắt của mọi loại thông báo của user
+func NotificationPreferences(db *gorm.DB, userID uint) (map[string]bool, error) {
+	prefs := make(map[string]bool, len(models.NotificationTypes))
+	for _, t := range models.NotificationTypes {
+		prefs[t] = true
+	}
+	var rows []models.NotificationPreference
+	if err := db.Where("user_id = ?", userID).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if _, ok := prefs[row.Type]; ok {
+			prefs[row.Type] = row.Enabled
+		}
+	}
+	return prefs, nil
+}
+
+// SetNotificationPreferences lưu trạng thái bật/tắt của các loại thông báo được gửi lên
+func SetNotificationPreferences(db *gorm.DB, userID uint, prefs map[string]bool) error {
+	if len(prefs) == 0 {
+		return nil
+	}
+	rows := make([]models.NotificationPreference, 0, len(prefs))
+	for _, t := range models.NotificationTypes {
+		if enabled, ok := prefs[t]; ok {
+			rows = append(rows, models.NotificationPreference{UserID: userID, Type: t, Enabled: enabled})
+		}
+	}
+	return db.Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns([]string{"enabled"})}).Create(&rows).Error
+}
+
+// MarkNotificationsRead đánh dấu đã đọc các thông báo chưa đọc của user (ids rỗng = tất cả).
+// group_key được xóa để sự kiện mới tạo thông báo mới thay vì gộp vào thông báo đã đọc.
+// Trả về số thông báo vừa được đánh dấu.
+func MarkNotificationsRead(db *gorm.DB, userID uint, ids ...uint) (int64, error) {
+	query := db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	// UpdateColumns để không đổi updated_at (thứ tự danh sách)
+	result := query.UpdateColumns(map[string]interface{}{"read_at": time.Now(), "group_key": nil})
+	return result.RowsAffected, result.Error
+}
+
+// UnreadNotificationCount đếm thông báo chưa đọc của user
+func UnreadNotificationCount(db *gorm.DB, userID uint) (int64, error) {
+	var count int64
+	err := db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"myapp/models"
+)
+
+func reactionEvent(actorID uint) NotificationEvent {
+	postID := uint(5)
+	return NotificationEvent{
+		Type:        models.NotificationReaction,
+		RecipientID: 9,
+		ActorID:     actorID,
+		TargetType:  models.NotificationTargetPost,
+		TargetID:    5,
+		PostID:      &postID,
+	}
+}
+
+func expectNotificationEnabled(mock sqlmock.Sqlmock, userID uint, notificationType string, disabled int) {
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM `notification_preferences` WHERE user_id = \\? AND type = \\? AND enabled = \\?").
+		WithArgs(userID, notificationType, false).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(disabled))
+}
+
+func TestDeliverNotification_CreatesGroup(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	expectNotificationEnabled(mock, 9, models.NotificationReaction, 0)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT `id` FROM `notifications` WHERE user_id = \\? AND group_key = \\? .* FOR UPDATE").
+		WithArgs(9, "reaction:post:5", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec("INSERT INTO `notifications`").
+		WithArgs(9, models.NotificationReaction, 7, 1, models.NotificationTargetPost, 5, 5, "reaction:post:5",
+			nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(40, 1))
+	mock.ExpectExec("INSERT INTO `notification_actors`").
+		WithArgs(40, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, DeliverNotification(gormDB, reactionEvent(7)))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeliverNotification_AggregatesNewActor(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	expectNotificationEnabled(mock, 9, models.NotificationReaction, 0)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT `id` FROM `notifications`").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(40))
+	mock.ExpectExec("INSERT INTO `notification_actors` .* ON DUPLICATE KEY UPDATE").
+		WithArgs(40, 8).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE notifications SET actor_id = \\?, actor_count = actor_count \\+ 1, updated_at = \\? WHERE id = \\?").
+		WithArgs(8, sqlmock.AnyArg(), 40).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, DeliverNotification(gormDB, reactionEvent(8)))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeliverNotification_SameActorNotCountedTwice(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	expectNotificationEnabled(mock, 9, models.NotificationReaction, 0)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT `id` FROM `notifications`").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(40))
+	mock.ExpectExec("INSERT INTO `notification_actors`").
+		WithArgs(40, 7).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	assert.NoError(t, DeliverNotification(gormDB, reactionEvent(7)))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeliverNotification_SkipsSelfAndDisabled(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	self := reactionEvent(9)
+	assert.NoError(t, DeliverNotification(gormDB, self))
+
+	expectNotificationEnabled(mock, 9, models.NotificationReaction, 1)
+	assert.NoError(t, DeliverNotification(gormDB, reactionEvent(7)))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotify_DropsWhenQueueFull(t *testing.T) {
+	defer func(queue chan NotificationEvent) { notificationQueue = queue }(notificationQueue)
+
+	notificationQueue = nil
+	Notify(reactionEvent(7))
+
+	notificationQueue = make(chan NotificationEvent, 1)
+	done := make(chan struct{})
+	go func() {
+		Notify(reactionEvent(7))
+		Notify(reactionEvent(8))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Notify blocked on a full queue")
+	}
+	assert.Len(t, notificationQueue, 1)
+	assert.Equal(t, uint(7), (<-notificationQueue).ActorID)
+}
+
+func TestStopNotificationDelivery_DrainsQueue(t *testing.T) {
+	defer func(queue chan NotificationEvent) { notificationQueue = queue }(notificationQueue)
+
+	mock, gormDB := setupTestDB(t)
+	expectNotificationEnabled(mock, 9, models.NotificationReaction, 1)
+	expectNotificationEnabled(mock, 9, models.NotificationReaction, 1)
+
+	StartNotificationDelivery(gormDB, 10)
+	Notify(reactionEvent(7))
+	Notify(reactionEvent(8))
+	StopNotificationDelivery()
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+	// Đã dừng thì Notify không gửi nữa và gọi Stop lần nữa không làm gì
+	Notify(reactionEvent(7))
+	StopNotificationDelivery()
+}
+
+func TestNotificationPreferences_DefaultsToEnabled(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	mock.ExpectQuery("SELECT \\* FROM `notification_preferences` WHERE user_id = \\?").
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "type", "enabled"}).
+			AddRow(9, models.NotificationFollow, false))
+
+	prefs, err := NotificationPreferences(gormDB, 9)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"comment": true, "follow": false, "mention": true, "reaction": true}, prefs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMarkNotificationsRead_ClearsGroupKey(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `notifications` SET `group_key`=\\?,`read_at`=\\? WHERE \\(user_id = \\? AND read_at IS NULL\\) AND id IN \\(\\?\\)").
+		WithArgs(nil, sqlmock.AnyArg(), 9, 40).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	marked, err := MarkNotificationsRead(gormDB, 9, 40)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), marked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"gorm.io/gorm"
+
+	"myapp/models"
+)
+
+// renderBatchSize là số post được render lại mỗi lô
+const renderBatchSize = 100
+
+// RenderPostContent render lại content_html từ Markdown trong content.
+// all = false chỉ xử lý các post chưa có content_html (tạo trước khi có cột này);
+// all = true dùng khi đổi quy tắc render/sanitize. Trả về số post đã render.
+func RenderPostContent(db *gorm.DB, all bool) (int64, error) {
+	query := db.Model(&models.Post{}).Select("id", "content")
+	if !all {
+		query = query.Where("content_html IS NULL")
+	}
+
+	var rendered int64
+	var posts []models.Post
+	result := query.FindInBatches(&posts, renderBatchSize, func(tx *gorm.DB, batch int) error {
+		for _, post := range posts {
+			html, _, err := RenderContent(db, post.Content)
+			if err != nil {
+				return err
+			}
+			// UpdateColumn để không đổi updated_at
+			if err := db.Model(&models.Post{}).Where("id = ?", post.ID).
+				UpdateColumn("content_html", html).Error; err != nil {
+				return err
+			}
+			rendered++
+		}
+		return nil
+	})
+	return rendered, result.Error
+}
@@ -0,0 +1,27 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderPostContent_OnlyMissing(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	mock.ExpectQuery("SELECT `id`,`content` FROM `posts` WHERE content_html IS NULL ORDER BY `posts`.`id` LIMIT \\?").
+		WithArgs(renderBatchSize).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "content"}).AddRow(1, "# Hi\n\n<script>x</script>"))
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `posts` SET `content_html`=\\? WHERE id = \\?").
+		WithArgs(`<h1 id="hi">Hi</h1>`+"\n\n", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	rendered, err := RenderPostContent(gormDB, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), rendered)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
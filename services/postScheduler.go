@@ -0,0 +1,73 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"myapp/models"
+)
+
+// Số post tối đa được xuất bản trong một lượt quét
+const publishBatchSize = 100
+
+// PublishDuePosts xuất bản các post đã đến lịch. Các dòng được khóa bằng
+// FOR UPDATE SKIP LOCKED nên khi nhiều instance cùng chạy, mỗi post chỉ
+// được một instance xuất bản đúng một lần. Trả về id các post vừa xuất bản.
+func PublishDuePosts(db *gorm.DB, now time.Time) ([]uint, error) {
+	var ids []uint
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var due []models.Post
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Select("id", "scheduled_for").
+			Where("status = ? AND scheduled_for <= ?", models.PostScheduled, now).
+			Order("scheduled_for").Limit(publishBatchSize).
+			Find(&due).Error; err != nil {
+			return err
+		}
+		for _, post := range due {
+			result := tx.Model(&models.Post{}).
+				Where("id = ? AND status = ?", post.ID, models.PostScheduled).
+				Updates(map[string]interface{}{
+					"status":        models.PostPublished,
+					"published_at":  post.ScheduledFor,
+					"scheduled_for": nil,
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 1 {
+				ids = append(ids, post.ID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// StartPostScheduler chạy nền việc xuất bản post đến hạn theo chu kỳ
+func StartPostScheduler(db *gorm.DB, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			ids, err := PublishDuePosts(db, time.Now())
+			if err != nil {
+				log.Printf("❌ Xuất bản post theo lịch thất bại: %v", err)
+			} else if len(ids) > 0 {
+				log.Printf("📰 Đã xuất bản %d post theo lịch", len(ids))
+			}
+			// Mention trong post vừa xuất bản giờ mới được thông báo
+			for _, id := range ids {
+				if _, err := NotifyMentions(db, id, time.Now()); err != nil {
+					log.Printf("❌ Gửi thông báo mention trong post %d thất bại: %v", id, err)
+				}
+			}
+		}
+	}()
+}
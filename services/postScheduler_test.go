@@ -0,0 +1,34 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishDuePosts(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	now := time.Now()
+	due := now.Add(-time.Minute)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT `id`,`scheduled_for` FROM `posts` WHERE status = \\? AND scheduled_for <= \\? ORDER BY scheduled_for LIMIT \\? FOR UPDATE SKIP LOCKED").
+		WithArgs("scheduled", now, publishBatchSize).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "scheduled_for"}).AddRow(1, due).AddRow(2, due))
+	mock.ExpectExec("UPDATE `posts` SET `published_at`=\\?,`scheduled_for`=\\?,`status`=\\?,`updated_at`=\\? WHERE id = \\? AND status = \\?").
+		WithArgs(due, nil, "published", sqlmock.AnyArg(), 1, "scheduled").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Post 2 đã được chuyển trạng thái ở nơi khác nên không tính
+	mock.ExpectExec("UPDATE `posts` SET").
+		WithArgs(due, nil, "published", sqlmock.AnyArg(), 2, "scheduled").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	ids, err := PublishDuePosts(gormDB, now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{1}, ids)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
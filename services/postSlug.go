@@ -0,0 +1,178 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-sql-driver/mysql"
+	"golang.org/x/text/unicode/norm"
+	"gorm.io/gorm"
+
+	"myapp/models"
+)
+
+// maxPostSlugLength chừa chỗ cho hậu tố "-n" trong cột VARCHAR(255)
+const maxPostSlugLength = 200
+
+// fallbackPostSlug dùng khi title không có chữ/số nào
+const fallbackPostSlug = "post"
+
+// maxPostSlugAttempts là số lần thử slug khi bị post khác chiếm cùng lúc
+const maxPostSlugAttempts = 5
+
+// Transliterate bỏ dấu tiếng Việt (và các dấu kết hợp khác): "Đà Nẵng" -> "Da Nang"
+func Transliterate(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case r == 'đ':
+			b.WriteByte('d')
+		case r == 'Đ':
+			b.WriteByte('D')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return norm.NFC.String(b.String())
+}
+
+// PostSlug sinh slug từ title: "Xin chào Việt Nam!" -> "xin-chao-viet-nam".
+// Slug dài bị cắt ở ranh giới từ (không bao giờ cắt giữa một ký tự UTF-8).
+func PostSlug(title string) string {
+	slug := Slugify(Transliterate(title))
+	if len(slug) > maxPostSlugLength {
+		cut := maxPostSlugLength
+		for cut > 0 && !utf8.RuneStart(slug[cut]) {
+			cut--
+		}
+		slug = slug[:cut]
+		if i := strings.LastIndexByte(slug, '-'); i > 0 {
+			slug = slug[:i]
+		}
+		slug = strings.TrimRight(slug, "-")
+	}
+	if slug == "" {
+		return fallbackPostSlug
+	}
+	return slug
+}
+
+// UniquePostSlug trả về slug cho post postID (0 khi tạo mới) chưa bị post khác dùng,
+// kể cả slug cũ trong post_slugs: base, base-2, base-3...
+func UniquePostSlug(tx *gorm.DB, title string, postID uint) (string, error) {
+	return uniquePostSlug(tx, title, postID, nil)
+}
+
+// uniquePostSlug như UniquePostSlug nhưng bỏ qua thêm các slug trong skip
+func uniquePostSlug(tx *gorm.DB, title string, postID uint, skip []string) (string, error) {
+	base := PostSlug(title)
+	// base chỉ gồm [a-z0-9-] (hoặc chữ cái Unicode) nên không cần escape cho LIKE
+	pattern := base + "-%"
+
+	var current, old []string
+	if err := tx.Model(&models.Post{}).Where("id <> ? AND (slug = ? OR slug LIKE ?)", postID, base, pattern).
+		Pluck("slug", &current).Error; err != nil {
+		return "", err
+	}
+	if err := tx.Model(&models.PostSlug{}).Where("post_id <> ? AND (slug = ? OR slug LIKE ?)", postID, base, pattern).
+		Pluck("slug", &old).Error; err != nil {
+		return "", err
+	}
+
+	taken := map[string]bool{}
+	for _, slug := range append(append(current, old...), skip...) {
+		taken[slug] = true
+	}
+	slug := base
+	for n := 2; taken[slug]; n++ {
+		slug = base + "-" + strconv.Itoa(n)
+	}
+	return slug, nil
+}
+
+// SavePostSlug chọn slug cho post postID theo title rồi gọi save(slug) để ghi post (gọi trong transaction).
+// Slug được kiểm tra trước khi ghi nên request song song có thể chọn trùng; khi save lỗi trùng
+// uq_posts_slug thì slug đó bị bỏ qua và thử hậu tố tiếp theo (MySQL chỉ hủy câu lệnh lỗi,
+// transaction vẫn dùng được). Trả về slug đã ghi.
+func SavePostSlug(tx *gorm.DB, title string, postID uint, save func(slug string) error) (string, error) {
+	var skip []string
+	for attempt := 1; ; attempt++ {
+		slug, err := uniquePostSlug(tx, title, postID, skip)
+		if err != nil {
+			return "", err
+		}
+		err = save(slug)
+		if err == nil {
+			return slug, nil
+		}
+		if attempt == maxPostSlugAttempts || !isPostSlugConflict(err) {
+			return "", err
+		}
+		skip = append(skip, slug)
+	}
+}
+
+// isPostSlugConflict cho biết err là lỗi trùng khóa trên cột slug của posts
+func isPostSlugConflict(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 && strings.Contains(mysqlErr.Message, "uq_posts_slug")
+}
+
+// ChangePostSlug tính slug mới của post theo title và gọi save(slug) để ghi post như SavePostSlug
+// (gọi trong transaction); nếu slug đổi, slug cũ được lưu vào post_slugs để chuyển hướng.
+func ChangePostSlug(tx *gorm.DB, post *models.Post, title string, save func(slug string) error) (string, error) {
+	previous := post.Slug
+	slug, err := SavePostSlug(tx, title, post.ID, save)
+	if err != nil || slug == previous {
+		return slug, err
+	}
+
+	// Slug mới có thể là slug cũ của chính post này (đổi title rồi đổi lại)
+	if err := tx.Where("slug = ?", slug).Delete(&models.PostSlug{}).Error; err != nil {
+		return "", err
+	}
+	if previous != "" {
+		if err := tx.Create(&models.PostSlug{Slug: previous, PostID: post.ID}).Error; err != nil {
+			return "", err
+		}
+	}
+	return slug, nil
+}
+
+// ResolvePostSlug trả về id của post từng dùng slug old, 0 nếu không có
+func ResolvePostSlug(db *gorm.DB, old string) (uint, error) {
+	var history models.PostSlug
+	err := db.Where("slug = ?", old).Limit(1).Find(&history).Error
+	return history.PostID, err
+}
+
+// SlugPosts sinh slug cho các post chưa có (post tạo trước khi có cột slug), trả về số post đã cập nhật
+func SlugPosts(db *gorm.DB) (int64, error) {
+	var done int64
+	for {
+		var posts []models.Post
+		if err := db.Select("id", "title").Where("slug IS NULL").Order("id").Limit(renderBatchSize).Find(&posts).Error; err != nil {
+			return done, err
+		}
+		if len(posts) == 0 {
+			return done, nil
+		}
+		for i := range posts {
+			err := db.Transaction(func(tx *gorm.DB) error {
+				// UpdateColumn để không đổi updated_at
+				_, err := SavePostSlug(tx, posts[i].Title, posts[i].ID, func(slug string) error {
+					return tx.Model(&posts[i]).UpdateColumn("slug", slug).Error
+				})
+				return err
+			})
+			if err != nil {
+				return done, err
+			}
+			done++
+		}
+	}
+}
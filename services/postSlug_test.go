@@ -0,0 +1,172 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"myapp/models"
+)
+
+func TestTransliterate(t *testing.T) {
+	assert.Equal(t, "Du lich Da Nang mua he", Transliterate("Du lịch Đà Nẵng mùa hè"))
+	assert.Equal(t, "Tieng Viet co dau: a a a e o o u", Transliterate("Tiếng Việt có dấu: ă â á ê ơ ô ư"))
+}
+
+func TestPostSlug(t *testing.T) {
+	cases := map[string]string{
+		"Xin chào Việt Nam!":         "xin-chao-viet-nam",
+		"Học Go trong 24 giờ":        "hoc-go-trong-24-gio",
+		"  ĐƯỜNG ĐẾN   thành công  ": "duong-den-thanh-cong",
+		"!!!":                        "post",
+		"Go 1.25: có gì mới?":        "go-1-25-co-gi-moi",
+	}
+	for title, want := range cases {
+		assert.Equal(t, want, PostSlug(title), title)
+	}
+}
+
+func TestPostSlug_TruncatesAtWordBoundary(t *testing.T) {
+	slug := PostSlug(strings.Repeat("abcdefghi ", 30))
+
+	assert.LessOrEqual(t, len(slug), maxPostSlugLength)
+	assert.True(t, strings.HasSuffix(slug, "-abcdefghi"))
+}
+
+func TestPostSlug_TruncatesOnRuneBoundary(t *testing.T) {
+	slug := PostSlug("a" + strings.Repeat("漢", 100))
+
+	assert.True(t, utf8.ValidString(slug))
+	assert.LessOrEqual(t, len(slug), maxPostSlugLength)
+	assert.True(t, strings.HasPrefix(slug, "a漢"))
+}
+
+func expectTakenSlugs(mock sqlmock.Sqlmock, postID uint, base string, current, old []string) {
+	rows := sqlmock.NewRows([]string{"slug"})
+	for _, slug := range current {
+		rows.AddRow(slug)
+	}
+	mock.ExpectQuery("SELECT `slug` FROM `posts` WHERE id <> \\? AND \\(slug = \\? OR slug LIKE \\?\\)").
+		WithArgs(postID, base, base+"-%").
+		WillReturnRows(rows)
+
+	rows = sqlmock.NewRows([]string{"slug"})
+	for _, slug := range old {
+		rows.AddRow(slug)
+	}
+	mock.ExpectQuery("SELECT `slug` FROM `post_slugs` WHERE post_id <> \\? AND \\(slug = \\? OR slug LIKE \\?\\)").
+		WithArgs(postID, base, base+"-%").
+		WillReturnRows(rows)
+}
+
+func TestUniquePostSlug_AddsSuffixOnCollision(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	// hello-2 là slug cũ của post khác nên cũng không dùng được
+	expectTakenSlugs(mock, 0, "hello", []string{"hello", "hello-world"}, []string{"hello-2"})
+
+	slug, err := UniquePostSlug(gormDB, "Hello", 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "hello-3", slug)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSavePostSlug_RetriesOnConflict(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	// Request khác vừa ghi "hello" sau lúc kiểm tra; lần thử sau bỏ qua slug đó
+	expectTakenSlugs(mock, 0, "hello", nil, nil)
+	expectTakenSlugs(mock, 0, "hello", nil, nil)
+
+	var tried []string
+	slug, err := SavePostSlug(gormDB, "Hello", 0, func(slug string) error {
+		tried = append(tried, slug)
+		if len(tried) == 1 {
+			return &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'hello' for key 'posts.uq_posts_slug'"}
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "hello-2", slug)
+	assert.Equal(t, []string{"hello", "hello-2"}, tried)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSavePostSlug_OtherErrorNotRetried(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	expectTakenSlugs(mock, 0, "hello", nil, nil)
+
+	conflict := &mysql.MySQLError{Number: 1062, Message: "Duplicate entry '1-7' for key 'uq_other'"}
+	_, err := SavePostSlug(gormDB, "Hello", 0, func(string) error { return conflict })
+
+	assert.ErrorIs(t, err, conflict)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestChangePostSlug_KeepsOldSlug(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	post := &models.Post{ID: 1, Title: "Hello", Slug: "hello"}
+
+	mock.ExpectBegin()
+	expectTakenSlugs(mock, 1, "xin-chao", nil, nil)
+	mock.ExpectExec("UPDATE `posts` SET `slug`=\\? WHERE `id` = \\?").
+		WithArgs("xin-chao", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM `post_slugs` WHERE slug = \\?").
+		WithArgs("xin-chao").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO `post_slugs`").
+		WithArgs("hello", 1, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	var slug string
+	err := gormDB.Transaction(func(tx *gorm.DB) (err error) {
+		slug, err = ChangePostSlug(tx, post, "Xin chào", func(slug string) error {
+			return tx.Model(post).UpdateColumn("slug", slug).Error
+		})
+		return err
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "xin-chao", slug)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestChangePostSlug_SameSlug(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	post := &models.Post{ID: 1, Title: "Hello", Slug: "hello"}
+	expectTakenSlugs(mock, 1, "hello", nil, nil)
+
+	slug, err := ChangePostSlug(gormDB, post, "Hello!", func(string) error { return nil })
+
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", slug)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSlugPosts(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	mock.ExpectQuery("SELECT `id`,`title` FROM `posts` WHERE slug IS NULL ORDER BY id LIMIT \\?").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(3, "Chào bạn"))
+	mock.ExpectBegin()
+	expectTakenSlugs(mock, 3, "chao-ban", nil, nil)
+	mock.ExpectExec("UPDATE `posts` SET `slug`=\\? WHERE `id` = \\?").
+		WithArgs("chao-ban", 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT `id`,`title` FROM `posts` WHERE slug IS NULL").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title"}))
+
+	updated, err := SlugPosts(gormDB)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), updated)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
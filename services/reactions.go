@@ -0,0 +1,104 @@
+package services
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"myapp/models"
+)
+
+// AddReaction thêm reaction của user, không làm gì nếu đã tồn tại.
+// Bộ đếm được tăng trong cùng transaction và chỉ khi thực sự thêm dòng mới,
+// nên các request song song không làm lệch số đếm. Trả về true nếu vừa thêm.
+func AddReaction(db *gorm.DB, targetType string, targetID, userID uint, reactionType string) (bool, error) {
+	added := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		reaction := models.Reaction{TargetType: targetType, TargetID: targetID, UserID: userID, Type: reactionType}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&reaction)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		added = true
+		return tx.Exec(`INSERT INTO reaction_counts (target_type, target_id, type, count) VALUES (?, ?, ?, 1)
+			ON DUPLICATE KEY UPDATE count = count + 1`, targetType, targetID, reactionType).Error
+	})
+	return added, err
+}
+
+// RemoveReaction xóa reaction của user, không làm gì nếu chưa có. Trả về true nếu vừa xóa.
+func RemoveReaction(db *gorm.DB, targetType string, targetID, userID uint, reactionType string) (bool, error) {
+	removed := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("target_type = ? AND target_id = ? AND user_id = ? AND type = ?",
+			targetType, targetID, userID, reactionType).Delete(&models.Reaction{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		removed = true
+		return tx.Exec(`UPDATE reaction_counts SET count = count - 1
+			WHERE target_type = ? AND target_id = ? AND type = ? AND count > 0`, targetType, targetID, reactionType).Error
+	})
+	return removed, err
+}
+
+// ReactionSummaries trả về tổng hợp reaction của nhiều đối tượng cùng loại.
+// userID = 0 nghĩa là không cần biết reaction của user hiện tại.
+func ReactionSummaries(db *gorm.DB, targetType string, targetIDs []uint, userID uint) (map[uint]models.ReactionSummary, error) {
+	summaries := make(map[uint]models.ReactionSummary, len(targetIDs))
+	for _, id := range targetIDs {
+		summaries[id] = models.ReactionSummary{Counts: map[string]uint{}, Mine: []string{}}
+	}
+	if len(targetIDs) == 0 {
+		return summaries, nil
+	}
+
+	var counts []models.ReactionCount
+	if err := db.Where("target_type = ? AND target_id IN ? AND count > 0", targetType, targetIDs).
+		Find(&counts).Error; err != nil {
+		return nil, err
+	}
+	for _, count := range counts {
+		summaries[count.TargetID].Counts[count.Type] = count.Count
+	}
+
+	if userID != 0 {
+		var mine []models.Reaction
+		if err := db.Select("target_id", "type").
+			Where("target_type = ? AND target_id IN ? AND user_id = ?", targetType, targetIDs, userID).
+			Order("id").Find(&mine).Error; err != nil {
+			return nil, err
+		}
+		for _, reaction := range mine {
+			summary := summaries[reaction.TargetID]
+			summary.Mine = append(summary.Mine, reaction.Type)
+			summaries[reaction.TargetID] = summary
+		}
+	}
+	return summaries, nil
+}
+
+// DeleteReactions xóa reaction và bộ đếm của các đối tượng sắp bị xóa
+// (reactions không có khóa ngoại tới post/comment nên không tự xóa theo)
+func DeleteReactions(tx *gorm.DB, targetType string, targetIDs interface{}) error {
+	if err := tx.Where("target_type = ? AND target_id IN (?)", targetType, targetIDs).Delete(&models.Reaction{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("target_type = ? AND target_id IN (?)", targetType, targetIDs).Delete(&models.ReactionCount{}).Error
+}
+
+// RepairReactionCounts tính lại toàn bộ bộ đếm từ bảng reactions.
+// Trả về số dòng bộ đếm sau khi tính lại.
+func RepairReactionCounts(db *gorm.DB) (int64, error) {
+	var rows int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM reaction_counts").Error; err != nil {
+			return err
+		}
+		result := tx.Exec(`INSERT INTO reaction_counts (target_type, target_id, type, count)
+			SELECT target_type, target_id, type, COUNT(*) FROM reactions
+			GROUP BY target_type, target_id, type`)
+		rows = result.RowsAffected
+		return result.Error
+	})
+	return rows, err
+}
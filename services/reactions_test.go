@@ -0,0 +1,102 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"myapp/models"
+)
+
+func TestAddReaction_IncrementsOnlyWhenInserted(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `reactions` .* ON DUPLICATE KEY UPDATE `id`=`id`").
+		WithArgs(models.ReactionTargetPost, 1, 7, "like", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(10, 1))
+	mock.ExpectExec("INSERT INTO reaction_counts .* ON DUPLICATE KEY UPDATE count = count \\+ 1").
+		WithArgs(models.ReactionTargetPost, 1, "like").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	added, err := AddReaction(gormDB, models.ReactionTargetPost, 1, 7, "like")
+
+	assert.NoError(t, err)
+	assert.True(t, added)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddReaction_AlreadyExists(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `reactions`").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	added, err := AddReaction(gormDB, models.ReactionTargetPost, 1, 7, "like")
+
+	assert.NoError(t, err)
+	assert.False(t, added)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRemoveReaction_DecrementsOnlyWhenDeleted(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `reactions` WHERE target_type = \\? AND target_id = \\? AND user_id = \\? AND type = \\?").
+		WithArgs(models.ReactionTargetComment, 3, 7, "wow").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE reaction_counts SET count = count - 1 .* AND count > 0").
+		WithArgs(models.ReactionTargetComment, 3, "wow").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	removed, err := RemoveReaction(gormDB, models.ReactionTargetComment, 3, 7, "wow")
+
+	assert.NoError(t, err)
+	assert.True(t, removed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReactionSummaries(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	mock.ExpectQuery("SELECT \\* FROM `reaction_counts` WHERE target_type = \\? AND target_id IN \\(\\?,\\?\\) AND count > 0").
+		WithArgs(models.ReactionTargetPost, 1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"target_type", "target_id", "type", "count"}).
+			AddRow(models.ReactionTargetPost, 1, "like", 3).
+			AddRow(models.ReactionTargetPost, 1, "love", 1))
+	mock.ExpectQuery("SELECT `target_id`,`type` FROM `reactions` WHERE target_type = \\? AND target_id IN \\(\\?,\\?\\) AND user_id = \\?").
+		WithArgs(models.ReactionTargetPost, 1, 2, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"target_id", "type"}).AddRow(1, "like"))
+
+	summaries, err := ReactionSummaries(gormDB, models.ReactionTargetPost, []uint{1, 2}, 7)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]uint{"like": 3, "love": 1}, summaries[1].Counts)
+	assert.Equal(t, []string{"like"}, summaries[1].Mine)
+	assert.Empty(t, summaries[2].Counts)
+	assert.Empty(t, summaries[2].Mine)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepairReactionCounts(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM reaction_counts").
+		WillReturnResult(sqlmock.NewResult(0, 5))
+	mock.ExpectExec("INSERT INTO reaction_counts .* SELECT target_type, target_id, type, COUNT\\(\\*\\) FROM reactions").
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectCommit()
+
+	rows, err := RepairReactionCounts(gormDB)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), rows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"myapp/models"
+)
+
+// ErrListOrderMismatch là lỗi khi thứ tự mới không gồm đúng các post đang có trong danh sách
+var ErrListOrderMismatch = errors.New("post_ids must contain every post in the list exactly once")
+
+// AddListItem thêm post vào cuối danh sách, không làm gì nếu đã có. Danh sách được khóa
+// trong transaction nên các request song song không nhận trùng position. Trả về true nếu vừa thêm.
+func AddListItem(db *gorm.DB, listID, postID uint) (bool, error) {
+	added := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := lockReadingList(tx, listID); err != nil {
+			return err
+		}
+		var last int
+		if err := tx.Model(&models.ReadingListItem{}).Where("list_id = ?", listID).
+			Select("COALESCE(MAX(position), 0)").Scan(&last).Error; err != nil {
+			return err
+		}
+		item := models.ReadingListItem{ListID: listID, PostID: postID, Position: last + 1}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&item)
+		added = result.RowsAffected > 0
+		return result.Error
+	})
+	return added, err
+}
+
+// RemoveListItem bỏ post khỏi danh sách, không làm gì nếu chưa có. Trả về true nếu vừa xóa.
+// Các item còn lại giữ nguyên position (có thể không liên tục).
+func RemoveListItem(db *gorm.DB, listID, postID uint) (bool, error) {
+	result := db.Where("list_id = ? AND post_id = ?", listID, postID).Delete(&models.ReadingListItem{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ReorderListItems sắp lại các post user nhìn thấy trong danh sách theo đúng thứ tự postIDs.
+// visible là điều kiện trên bảng posts cho biết post nào user nhìn thấy (rỗng là thấy tất cả);
+// postIDs phải gồm mọi post nhìn thấy, mỗi post một lần (ErrListOrderMismatch nếu không).
+// Post bị ẩn (ví dụ tác giả chuyển về nháp) giữ nguyên chỗ của nó trong danh sách.
+func ReorderListItems(db *gorm.DB, listID uint, postIDs []uint, visible string, args ...interface{}) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := lockReadingList(tx, listID); err != nil {
+			return err
+		}
+		var current []uint
+		if err := tx.Model(&models.ReadingListItem{}).Where("list_id = ?", listID).
+			Order("position, post_id").Pluck("post_id", &current).Error; err != nil {
+			return err
+		}
+		shown := current
+		if visible != "" {
+			shown = nil
+			if err := tx.Model(&models.ReadingListItem{}).
+				Joins("JOIN posts ON posts.id = reading_list_items.post_id").
+				Where("reading_list_items.list_id = ?", listID).Where(visible, args...).
+				Pluck("reading_list_items.post_id", &shown).Error; err != nil {
+				return err
+			}
+		}
+		if !samePostSet(shown, postIDs) {
+			return ErrListOrderMismatch
+		}
+
+		isShown := make(map[uint]bool, len(shown))
+		for _, id := range shown {
+			isShown[id] = true
+		}
+		next := postIDs
+		for i, postID := range current {
+			if isShown[postID] {
+				postID, next = next[0], next[1:]
+			}
+			if err := tx.Model(&models.ReadingListItem{}).Where("list_id = ? AND post_id = ?", listID, postID).
+				UpdateColumn("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// BookmarkedPosts cho biết post nào trong postIDs đã nằm trong ít nhất một danh sách của userID
+func BookmarkedPosts(db *gorm.DB, userID uint, postIDs []uint) (map[uint]bool, error) {
+	bookmarked := make(map[uint]bool, len(postIDs))
+	if userID == 0 || len(postIDs) == 0 {
+		return bookmarked, nil
+	}
+	var ids []uint
+	if err := db.Model(&models.ReadingListItem{}).
+		Joins("JOIN reading_lists ON reading_lists.id = reading_list_items.list_id").
+		Where("reading_lists.user_id = ? AND reading_list_items.post_id IN ?", userID, postIDs).
+		Distinct().Pluck("reading_list_items.post_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		bookmarked[id] = true
+	}
+	return bookmarked, nil
+}
+
+// lockReadingList khóa dòng của danh sách tới hết transaction
+func lockReadingList(tx *gorm.DB, listID uint) error {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.ReadingList{}, listID).Error
+}
+
+// samePostSet kiểm tra b là một hoán vị của a
+func samePostSet(a, b []uint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	remaining := make(map[uint]bool, len(a))
+	for _, id := range a {
+		remaining[id] = true
+	}
+	for _, id := range b {
+		if !remaining[id] {
+			return false
+		}
+		delete(remaining, id)
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"myapp/models"
+)
+
+func TestAddListItem_AppendsAtEnd(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT `id` FROM `reading_lists` WHERE `reading_lists`.`id` = \\? .* FOR UPDATE").
+		WithArgs(3, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(position\\), 0\\) FROM `reading_list_items` WHERE list_id = \\?").
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(2))
+	mock.ExpectExec("INSERT INTO `reading_list_items` .* ON DUPLICATE KEY UPDATE").
+		WithArgs(3, 8, 3, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	added, err := AddListItem(gormDB, 3, 8)
+
+	assert.NoError(t, err)
+	assert.True(t, added)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReorderListItems_Mismatch(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT `id` FROM `reading_lists`").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectQuery("SELECT `post_id` FROM `reading_list_items` WHERE list_id = \\?").
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id"}).AddRow(1).AddRow(2))
+	mock.ExpectRollback()
+
+	err := ReorderListItems(gormDB, 3, []uint{2, 2}, "")
+
+	assert.ErrorIs(t, err, ErrListOrderMismatch)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReorderListItems(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT `id` FROM `reading_lists`").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectQuery("SELECT `post_id` FROM `reading_list_items` WHERE list_id = \\?").
+		WillReturnRows(sqlmock.NewRows([]string{"post_id"}).AddRow(1).AddRow(2))
+	mock.ExpectExec("UPDATE `reading_list_items` SET `position`=\\? WHERE list_id = \\? AND post_id = \\?").
+		WithArgs(1, 3, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE `reading_list_items` SET `position`=\\? WHERE list_id = \\? AND post_id = \\?").
+		WithArgs(2, 3, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, ReorderListItems(gormDB, 3, []uint{2, 1}, ""))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReorderListItems_KeepsHiddenInPlace(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT `id` FROM `reading_lists`").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectQuery("SELECT `post_id` FROM `reading_list_items` WHERE list_id = \\? ORDER BY position, post_id").
+		WillReturnRows(sqlmock.NewRows([]string{"post_id"}).AddRow(1).AddRow(2).AddRow(3))
+	mock.ExpectQuery("SELECT `reading_list_items`.`post_id` FROM `reading_list_items` JOIN posts .* AND `posts`.status = \\?").
+		WithArgs(3, models.PostPublished).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id"}).AddRow(1).AddRow(3))
+	// Post 2 đang bị ẩn nên giữ position 2, hai post còn lại đổi chỗ cho nhau
+	for i, postID := range []uint{3, 2, 1} {
+		mock.ExpectExec("UPDATE `reading_list_items` SET `position`=\\? WHERE list_id = \\? AND post_id = \\?").
+			WithArgs(i+1, 3, postID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+
+	assert.NoError(t, ReorderListItems(gormDB, 3, []uint{3, 1}, "`posts`.status = ?", models.PostPublished))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBookmarkedPosts_AnonymousSkipsQuery(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	bookmarked, err := BookmarkedPosts(gormDB, 0, []uint{1, 2})
+
+	assert.NoError(t, err)
+	assert.Empty(t, bookmarked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"path"
+	"time"
+
+	"gorm.io/gorm"
+
+	"myapp/blobstore"
+	"myapp/models"
+)
+
+// Số upload hết hạn tối đa được xóa trong một lượt quét
+const expireUploadBatchSize = 100
+
+// ErrUploadConflict là lỗi khi offset của upload đã đổi (request khác ghi trước)
+var ErrUploadConflict = errors.New("upload offset has changed")
+
+// NewUploadID sinh id ngẫu nhiên cho upload resumable
+func NewUploadID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(buf)
+}
+
+// AppendUploadPart ghi size byte từ r thành đoạn tiếp theo của upload (bắt đầu ở UploadOffset),
+// tăng offset và gia hạn upload tới expiresAt. Offset chỉ được tăng nếu trong DB vẫn là
+// UploadOffset; nếu không, đoạn vừa ghi bị xóa và trả về ErrUploadConflict.
+func AppendUploadPart(ctx context.Context, db *gorm.DB, store blobstore.Storage, upload *models.Upload, r io.Reader, size int64, expiresAt time.Time) error {
+	key := blobstore.NewKey(path.Join("uploads", upload.ID), "")
+	if err := store.Put(ctx, key, r, size, "application/octet-stream"); err != nil {
+		return err
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Upload{}).
+			Where("id = ? AND upload_offset = ?", upload.ID, upload.UploadOffset).
+			Updates(map[string]interface{}{
+				"upload_offset": upload.UploadOffset + size,
+				"expires_at":    expiresAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrUploadConflict
+		}
+		return tx.Create(&models.UploadPart{
+			UploadID:    upload.ID,
+			StartOffset: upload.UploadOffset,
+			StorageKey:  key,
+			Size:        size,
+		}).Error
+	})
+	if err != nil {
+		DeleteFiles(ctx, store, key)
+		return err
+	}
+	upload.UploadOffset += size
+	upload.ExpiresAt = expiresAt
+	return nil
+}
+
+// OpenUpload trả về reader đọc nối tiếp các đoạn của upload theo offset.
+// Mỗi đoạn chỉ được mở khi đọc tới nên không giữ nhiều file cùng lúc.
+func OpenUpload(ctx context.Context, db *gorm.DB, store blobstore.Storage, id string) (io.ReadCloser, error) {
+	var parts []models.UploadPart
+	if err := db.Where("upload_id = ?", id).Order("start_offset").Find(&parts).Error; err != nil {
+		return nil, err
+	}
+	return &uploadReader{ctx: ctx, store: store, parts: parts}, nil
+}
+
+type uploadReader struct {
+	ctx     context.Context
+	store   blobstore.Storage
+	parts   []models.UploadPart
+	current io.ReadCloser
+}
+
+func (r *uploadReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.parts) == 0 {
+				return 0, io.EOF
+			}
+			file, _, err := r.store.Open(r.ctx, r.parts[0].StorageKey)
+			if err != nil {
+				return 0, err
+			}
+			r.current, r.parts = file, r.parts[1:]
+		}
+		n, err := r.current.Read(p)
+		if errors.Is(err, io.EOF) {
+			r.current.Close()
+			r.current = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (r *uploadReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	return r.current.Close()
+}
+
+// UploadPartKeys trả về key trong storage của các đoạn thuộc những upload khớp điều kiện
+// (điều kiện viết trên bảng uploads, ví dụ "uploads.post_id = ?")
+func UploadPartKeys(db *gorm.DB, query interface{}, args ...interface{}) ([]string, error) {
+	var keys []string
+	err := db.Model(&models.UploadPart{}).
+		Joins("JOIN uploads ON uploads.id = upload_parts.upload_id").
+		Where(query, args...).
+		Pluck("upload_parts.storage_key", &keys).Error
+	return keys, err
+}
+
+// DeleteUpload xóa upload (các đoạn bị xóa theo khóa ngoại) rồi xóa file của các đoạn
+func DeleteUpload(ctx context.Context, db *gorm.DB, store blobstore.Storage, id string) error {
+	keys, err := UploadPartKeys(db, "uploads.id = ?", id)
+	if err != nil {
+		return err
+	}
+	if err := db.Delete(&models.Upload{}, "id = ?", id).Error; err != nil {
+		return err
+	}
+	DeleteFiles(ctx, store, keys...)
+	return nil
+}
+
+// DeleteUploadParts xóa các đoạn của upload đã hoàn tất, dòng upload được giữ để client
+// tra cứu attachment cho tới khi hết hạn
+func DeleteUploadParts(ctx context.Context, db *gorm.DB, store blobstore.Storage, id string) error {
+	var keys []string
+	if err := db.Model(&models.UploadPart{}).Where("upload_id = ?", id).Pluck("storage_key", &keys).Error; err != nil {
+		return err
+	}
+	if err := db.Where("upload_id = ?", id).Delete(&models.UploadPart{}).Error; err != nil {
+		return err
+	}
+	DeleteFiles(ctx, store, keys...)
+	return nil
+}
+
+// ExpireUploads xóa các upload đã quá expires_at cùng dữ liệu đã nhận, trả về số upload đã xóa.
+// Upload đã thành attachment chỉ mất dòng tra cứu, attachment vẫn còn.
+func ExpireUploads(ctx context.Context, db *gorm.DB, store blobstore.Storage, now time.Time) (int, error) {
+	var ids []string
+	if err := db.Model(&models.Upload{}).Where("expires_at <= ?", now).
+		Order("expires_at").Limit(expireUploadBatchSize).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	for i, id := range ids {
+		if err := DeleteUpload(ctx, db, store, id); err != nil {
+			return i, err
+		}
+	}
+	return len(ids), nil
+}
+
+// StartUploadExpiry chạy nền việc xóa upload hết hạn theo chu kỳ
+func StartUploadExpiry(db *gorm.DB, store blobstore.Storage, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			n, err := ExpireUploads(context.Background(), db, store, time.Now())
+			if err != nil {
+				log.Printf("❌ Xóa upload hết hạn thất bại: %v", err)
+			} else if n > 0 {
+				log.Printf("🧹 Đã xóa %d upload hết hạn", n)
+			}
+		}
+	}()
+}
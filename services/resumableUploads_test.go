@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"myapp/blobstore"
+	"myapp/models"
+)
+
+func TestAppendUploadPart_AdvancesOffset(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	store := blobstore.NewLocal(t.TempDir(), "/api/files", SignPath)
+	upload := &models.Upload{ID: "abc", UploadLength: 10, UploadOffset: 4}
+	expires := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `uploads` SET `expires_at`=\\?,`upload_offset`=\\?,`updated_at`=\\? WHERE id = \\? AND upload_offset = \\?").
+		WithArgs(expires, 7, sqlmock.AnyArg(), "abc", 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO `upload_parts`").
+		WithArgs("abc", 4, sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := AppendUploadPart(context.Background(), gormDB, store, upload, strings.NewReader("xyz"), 3, expires)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), upload.UploadOffset)
+	assert.Equal(t, expires, upload.ExpiresAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAppendUploadPart_ConflictRemovesPart(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	dir := t.TempDir()
+	store := blobstore.NewLocal(dir, "/api/files", SignPath)
+	upload := &models.Upload{ID: "abc", UploadLength: 10, UploadOffset: 4}
+
+	// Request khác đã ghi tiếp từ offset 4
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `uploads`").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := AppendUploadPart(context.Background(), gormDB, store, upload, strings.NewReader("xyz"), 3, time.Now())
+
+	assert.ErrorIs(t, err, ErrUploadConflict)
+	assert.Equal(t, int64(4), upload.UploadOffset)
+	entries, _ := filepath.Glob(filepath.Join(dir, "uploads", "abc", "*"))
+	assert.Empty(t, entries)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOpenUpload_ConcatenatesParts(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	ctx := context.Background()
+	store := blobstore.NewLocal(t.TempDir(), "/api/files", SignPath)
+	require.NoError(t, store.Put(ctx, "uploads/abc/1", strings.NewReader("hello "), 6, ""))
+	require.NoError(t, store.Put(ctx, "uploads/abc/2", strings.NewReader(""), 0, ""))
+	require.NoError(t, store.Put(ctx, "uploads/abc/3", strings.NewReader("world"), 5, ""))
+
+	mock.ExpectQuery("SELECT \\* FROM `upload_parts` WHERE upload_id = \\? ORDER BY start_offset").
+		WithArgs("abc").
+		WillReturnRows(sqlmock.NewRows([]string{"upload_id", "start_offset", "storage_key", "size"}).
+			AddRow("abc", 0, "uploads/abc/1", 6).
+			AddRow("abc", 6, "uploads/abc/2", 0).
+			AddRow("abc", 6, "uploads/abc/3", 5))
+
+	reader, err := OpenUpload(ctx, gormDB, store, "abc")
+	require.NoError(t, err)
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(data))
+}
+
+func TestExpireUploads_DeletesRowsAndParts(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	ctx := context.Background()
+	store := blobstore.NewLocal(t.TempDir(), "/api/files", SignPath)
+	require.NoError(t, store.Put(ctx, "uploads/abc/1", strings.NewReader("hi"), 2, ""))
+	now := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT `id` FROM `uploads` WHERE expires_at <= \\? ORDER BY expires_at LIMIT \\?").
+		WithArgs(now, expireUploadBatchSize).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("abc"))
+	mock.ExpectQuery("SELECT `upload_parts`.`storage_key` FROM `upload_parts` JOIN uploads ON uploads.id = upload_parts.upload_id WHERE uploads.id = \\?").
+		WithArgs("abc").
+		WillReturnRows(sqlmock.NewRows([]string{"storage_key"}).AddRow("uploads/abc/1"))
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM `uploads` WHERE id = \\?").
+		WithArgs("abc").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	n, err := ExpireUploads(ctx, gormDB, store, now)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	_, _, err = store.Open(ctx, "uploads/abc/1")
+	assert.ErrorIs(t, err, blobstore.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
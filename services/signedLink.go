@@ -0,0 +1,38 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"myapp/config"
+)
+
+// signingKey lấy khóa ký link, mặc định dùng chung JWT_SECRET
+func signingKey() []byte {
+	return []byte(config.GetEnv("SIGNING_SECRET", config.GetEnv("JWT_SECRET", "my_secret_key")))
+}
+
+func sign(path string, expires int64) string {
+	mac := hmac.New(sha256.New, signingKey())
+	fmt.Fprintf(mac, "%s\n%d", path, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignPath trả về path kèm query expires & signature, hết hạn sau ttl
+func SignPath(path string, ttl time.Duration) string {
+	expires := time.Now().Add(ttl).Unix()
+	return fmt.Sprintf("%s?expires=%d&signature=%s", path, expires, sign(path, expires))
+}
+
+// VerifySignedPath kiểm tra chữ ký và thời hạn của một link đã ký
+func VerifySignedPath(path, expires, signature string) bool {
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return false
+	}
+	return hmac.Equal([]byte(sign(path, exp)), []byte(signature))
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignPath_Verify(t *testing.T) {
+	signed := SignPath("/api/files/report.csv", time.Minute)
+	parts := strings.SplitN(signed, "?", 2)
+	query, err := url.ParseQuery(parts[1])
+	assert.NoError(t, err)
+
+	assert.Equal(t, "/api/files/report.csv", parts[0])
+	assert.True(t, VerifySignedPath(parts[0], query.Get("expires"), query.Get("signature")))
+
+	// Đổi path hoặc chữ ký thì không hợp lệ
+	assert.False(t, VerifySignedPath("/api/files/other.csv", query.Get("expires"), query.Get("signature")))
+	assert.False(t, VerifySignedPath(parts[0], query.Get("expires"), "deadbeef"))
+}
+
+func TestSignPath_Expired(t *testing.T) {
+	signed := SignPath("/api/files/report.csv", -time.Minute)
+	query, _ := url.ParseQuery(strings.SplitN(signed, "?", 2)[1])
+
+	assert.False(t, VerifySignedPath("/api/files/report.csv", query.Get("expires"), query.Get("signature")))
+}
@@ -0,0 +1,24 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Slugify chuẩn hóa chuỗi thành slug: chữ thường, chữ số, các từ nối bằng "-"
+func Slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
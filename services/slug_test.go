@@ -0,0 +1,21 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlugify(t *testing.T) {
+	cases := map[string]string{
+		"Go":               "go",
+		"  Web  Dev  ":     "web-dev",
+		"C++ / Rust!":      "c-rust",
+		"already-a-slug":   "already-a-slug",
+		"---":              "",
+		"Version 2.0 Beta": "version-2-0-beta",
+	}
+	for input, want := range cases {
+		assert.Equal(t, want, Slugify(input), input)
+	}
+}
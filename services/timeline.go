@@ -0,0 +1,100 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"myapp/models"
+)
+
+// ErrInvalidCursor là lỗi khi cursor client gửi lên không đọc được
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// TimelineCursor là vị trí trong timeline (keyset): các post xếp theo published_at rồi id giảm dần,
+// trang sau bắt đầu ngay sau post cuối của trang trước nên không bị trùng/sót khi có post mới
+type TimelineCursor struct {
+	PublishedAt time.Time
+	PostID      uint
+}
+
+// Encode mã hóa cursor thành chuỗi gửi cho client
+func (c TimelineCursor) Encode() string {
+	raw := fmt.Sprintf("%d:%d", c.PublishedAt.UnixNano(), c.PostID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeTimelineCursor đọc cursor do Encode tạo ra
+func DecodeTimelineCursor(s string) (*TimelineCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var nanos int64
+	var id uint
+	if n, err := fmt.Sscanf(string(raw), "%d:%d", &nanos, &id); err != nil || n != 2 || id == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &TimelineCursor{PublishedAt: time.Unix(0, nanos), PostID: id}, nil
+}
+
+// TimelinePage là một trang timeline: id các post theo thứ tự hiển thị và cursor của trang sau
+// (nil nếu đã hết)
+type TimelinePage struct {
+	PostIDs []uint
+	Next    *TimelineCursor
+}
+
+// Timeline trả về home timeline của một user: post đã xuất bản của các tác giả user theo dõi,
+// mới nhất trước. after = nil là trang đầu.
+//
+// FanoutOnRead tính timeline lúc đọc; với user theo dõi rất nhiều tác giả có thể thay bằng
+// cài đặt fan-out-on-write (ghi sẵn vào bảng timeline khi post được xuất bản) mà không đổi API.
+type Timeline interface {
+	Home(userID uint, after *TimelineCursor, limit int) (*TimelinePage, error)
+}
+
+// FanoutOnRead lấy timeline bằng cách join follows với posts mỗi lần đọc
+type FanoutOnRead struct {
+	db *gorm.DB
+}
+
+func NewFanoutOnRead(db *gorm.DB) *FanoutOnRead {
+	return &FanoutOnRead{db: db}
+}
+
+func (t *FanoutOnRead) Home(userID uint, after *TimelineCursor, limit int) (*TimelinePage, error) {
+	query := t.db.Model(&models.Post{}).Select("posts.id", "posts.published_at").
+		Joins("JOIN follows ON follows.followee_id = posts.author_id AND follows.follower_id = ?", userID).
+		Where("posts.status = ?", models.PostPublished)
+	if after != nil {
+		query = query.Where("posts.published_at < ? OR (posts.published_at = ? AND posts.id < ?)",
+			after.PublishedAt, after.PublishedAt, after.PostID)
+	}
+
+	// Lấy dư một dòng để biết còn trang sau hay không
+	var rows []models.Post
+	if err := query.Order("posts.published_at DESC, posts.id DESC").Limit(limit + 1).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return timelinePage(rows, limit), nil
+}
+
+func timelinePage(rows []models.Post, limit int) *TimelinePage {
+	page := &TimelinePage{PostIDs: []uint{}}
+	for i, row := range rows {
+		if i == limit {
+			last := rows[limit-1]
+			page.Next = &TimelineCursor{PostID: last.ID}
+			if last.PublishedAt != nil {
+				page.Next.PublishedAt = *last.PublishedAt
+			}
+			break
+		}
+		page.PostIDs = append(page.PostIDs, row.ID)
+	}
+	return page
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"myapp/models"
+)
+
+func TestTimelineCursor_RoundTrip(t *testing.T) {
+	cursor := TimelineCursor{PublishedAt: time.Date(2025, 3, 1, 8, 30, 0, 0, time.UTC), PostID: 42}
+
+	decoded, err := DecodeTimelineCursor(cursor.Encode())
+
+	assert.NoError(t, err)
+	assert.True(t, cursor.PublishedAt.Equal(decoded.PublishedAt))
+	assert.Equal(t, uint(42), decoded.PostID)
+}
+
+func TestDecodeTimelineCursor_Invalid(t *testing.T) {
+	for _, raw := range []string{"***", "bm90LWEtY3Vyc29y", "MTIzOjA"} {
+		_, err := DecodeTimelineCursor(raw)
+		assert.ErrorIs(t, err, ErrInvalidCursor, raw)
+	}
+}
+
+func TestFanoutOnRead_Home(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	after := &TimelineCursor{PublishedAt: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), PostID: 50}
+	t1 := time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC)
+	t2 := time.Date(2025, 2, 27, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT posts.id,posts.published_at FROM `posts` "+
+		"JOIN follows ON follows.followee_id = posts.author_id AND follows.follower_id = \\? "+
+		"WHERE posts.status = \\? AND \\(posts.published_at < \\? OR \\(posts.published_at = \\? AND posts.id < \\?\\)\\) "+
+		"ORDER BY posts.published_at DESC, posts.id DESC LIMIT \\?").
+		WithArgs(7, models.PostPublished, after.PublishedAt, after.PublishedAt, 50, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "published_at"}).
+			AddRow(49, t1).AddRow(30, t2).AddRow(12, t2))
+
+	page, err := NewFanoutOnRead(gormDB).Home(7, after, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{49, 30}, page.PostIDs)
+	assert.Equal(t, &TimelineCursor{PublishedAt: t2, PostID: 30}, page.Next)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFanoutOnRead_HomeLastPage(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	mock.ExpectQuery("FROM `posts` JOIN follows").
+		WithArgs(7, models.PostPublished, 21).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "published_at"}).AddRow(3, time.Now()))
+
+	page, err := NewFanoutOnRead(gormDB).Home(7, nil, 20)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{3}, page.PostIDs)
+	assert.Nil(t, page.Next)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"myapp/models"
+)
+
+// TrendingReactionWeight là số lượt xem tương đương một reaction khi tính điểm trending
+const TrendingReactionWeight = 5.0
+
+// TrendingPost là một post trong bảng xếp hạng trending
+type TrendingPost struct {
+	PostID uint
+	Score  float64
+}
+
+// TrendingPosts xếp hạng các post đã xuất bản theo lượt xem (post_view_stats) và reaction
+// từ since tới now. Mỗi lượt xem/reaction giảm một nửa giá trị sau mỗi halfLife, nên hoạt động
+// gần đây nặng ký hơn. Lượt xem còn trong bộ nhớ (chưa flush) chưa được tính.
+func TrendingPosts(db *gorm.DB, now, since time.Time, halfLife time.Duration, limit int) ([]TrendingPost, error) {
+	seconds := halfLife.Seconds()
+	var ranked []TrendingPost
+	err := db.Raw(`SELECT activity.post_id, SUM(activity.score) AS score
+FROM (
+  SELECT post_id, views * POW(0.5, TIMESTAMPDIFF(SECOND, bucket_start, ?) / ?) AS score
+  FROM post_view_stats WHERE bucket_start >= ?
+  UNION ALL
+  SELECT target_id, ? * POW(0.5, TIMESTAMPDIFF(SECOND, created_at, ?) / ?)
+  FROM reactions WHERE target_type = ? AND created_at >= ?
+) activity
+JOIN posts ON posts.id = activity.post_id AND posts.status = ?
+GROUP BY activity.post_id
+ORDER BY score DESC, activity.post_id DESC
+LIMIT ?`,
+		now, seconds, since.UTC().Truncate(viewBucket),
+		TrendingReactionWeight, now, seconds, models.ReactionTargetPost, since,
+		models.PostPublished, limit).Scan(&ranked).Error
+	return ranked, err
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+
+	"github.com/gabriel-vasile/mimetype"
+
+	"myapp/imageproc"
+)
+
+// Loại upload, mỗi loại có danh sách định dạng và cách xử lý riêng
+const (
+	UploadAvatar     = "avatar"
+	UploadAttachment = "attachment"
+)
+
+// Giới hạn khi xử lý ảnh
+const (
+	maxImagePixels     = 40_000_000 // ~ ảnh 8000x5000
+	avatarSize         = 512
+	avatarThumbSize    = 128
+	attachmentThumbBox = 320
+	sniffLength        = 3072 // số byte đầu mimetype cần để nhận dạng
+)
+
+// uploadTypes là các định dạng được phép theo loại upload (nhận dạng theo nội dung, không tin
+// Content-Type hay phần mở rộng do client gửi)
+var uploadTypes = map[string][]string{
+	UploadAvatar:     {"image/jpeg", "image/png", "image/gif"},
+	UploadAttachment: {"image/jpeg", "image/png", "image/gif", "image/webp", "application/pdf", "text/plain", "application/zip"},
+}
+
+// ErrUnsupportedUpload là lỗi khi nội dung file không thuộc định dạng được phép
+var ErrUnsupportedUpload = errors.New("unsupported file type")
+
+// PreparedUpload là file đã kiểm tra và xử lý, sẵn sàng ghi vào storage
+type PreparedUpload struct {
+	ContentType string
+	Ext         string // gồm dấu chấm, ví dụ ".jpg"
+	Size        int64
+	Body        io.Reader
+	// Chỉ có với ảnh giải mã được
+	Width, Height int
+	Thumbnail     []byte
+	ThumbnailType string
+	ThumbnailExt  string
+}
+
+// PrepareUpload nhận dạng định dạng của file size byte từ r theo nội dung và xử lý theo loại:
+//   - ảnh JPEG/PNG: xoay theo EXIF, mã hóa lại để bỏ metadata (EXIF, GPS...), tạo thumbnail;
+//     avatar được cắt vuông và thu về avatarSize
+//   - GIF: giữ nguyên file (còn animation), thumbnail từ khung đầu
+//   - WebP: bỏ chunk EXIF/XMP, không có thumbnail
+//   - các định dạng khác: giữ nguyên, đọc dần từ r (không nạp cả file vào bộ nhớ)
+//
+// Ảnh được nạp vào bộ nhớ nên người gọi phải giới hạn size trước.
+func PrepareUpload(r io.Reader, size int64, kind string) (*PreparedUpload, error) {
+	br := bufio.NewReaderSize(r, sniffLength)
+	head, err := br.Peek(sniffLength)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, err
+	}
+	detected := mimetype.Detect(head)
+	contentType := ""
+	for _, allowed := range uploadTypes[kind] {
+		if detected.Is(allowed) {
+			contentType = allowed
+			break
+		}
+	}
+	if contentType == "" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedUpload, detected.String())
+	}
+
+	upload := &PreparedUpload{ContentType: contentType, Ext: detected.Extension(), Size: size, Body: br}
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif":
+		data, err := io.ReadAll(br)
+		if err != nil {
+			return nil, err
+		}
+		if err := processImage(upload, data, kind); err != nil {
+			return nil, err
+		}
+	case "image/webp":
+		data, err := io.ReadAll(br)
+		if err != nil {
+			return nil, err
+		}
+		stripped, err := imageproc.StripWebPMetadata(data)
+		if err != nil {
+			return nil, err
+		}
+		upload.setBody(stripped)
+	}
+	return upload, nil
+}
+
+func (u *PreparedUpload) setBody(data []byte) {
+	u.Body = bytes.NewReader(data)
+	u.Size = int64(len(data))
+}
+
+// processImage giải mã ảnh, ghi lại bản đã bỏ metadata và tạo thumbnail
+func processImage(upload *PreparedUpload, data []byte, kind string) error {
+	img, format, err := imageproc.Decode(data, maxImagePixels)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnsupportedUpload, err)
+	}
+
+	// Ảnh có nền trong suốt giữ PNG, còn lại JPEG cho nhẹ
+	thumbFormat, thumbType, thumbExt := imageproc.FormatJPEG, "image/jpeg", ".jpg"
+	if format != imageproc.FormatJPEG && !imageproc.Opaque(img) {
+		thumbFormat, thumbType, thumbExt = imageproc.FormatPNG, "image/png", ".png"
+	}
+
+	var thumbnail image.Image
+	switch {
+	case kind == UploadAvatar:
+		img = imageproc.Fit(imageproc.Square(img), avatarSize, avatarSize)
+		thumbnail = imageproc.Fit(img, avatarThumbSize, avatarThumbSize)
+		// Avatar luôn là ảnh tĩnh cùng định dạng với thumbnail
+		var buf bytes.Buffer
+		if err := imageproc.Encode(&buf, img, thumbFormat); err != nil {
+			return err
+		}
+		upload.ContentType, upload.Ext = thumbType, thumbExt
+		upload.setBody(buf.Bytes())
+	case format == imageproc.FormatGIF:
+		// GIF không mang EXIF, giữ nguyên để không mất animation
+		thumbnail = imageproc.Fit(img, attachmentThumbBox, attachmentThumbBox)
+		upload.setBody(data)
+	default:
+		thumbnail = imageproc.Fit(img, attachmentThumbBox, attachmentThumbBox)
+		var buf bytes.Buffer
+		if err := imageproc.Encode(&buf, img, format); err != nil {
+			return err
+		}
+		upload.setBody(buf.Bytes())
+	}
+
+	var thumb bytes.Buffer
+	if err := imageproc.Encode(&thumb, thumbnail, thumbFormat); err != nil {
+		return err
+	}
+	bounds := img.Bounds()
+	upload.Width, upload.Height = bounds.Dx(), bounds.Dy()
+	upload.Thumbnail, upload.ThumbnailType, upload.ThumbnailExt = thumb.Bytes(), thumbType, thumbExt
+	return nil
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"myapp/blobstore"
+)
+
+func pngImage(t *testing.T, w, h int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, w, h))))
+	return buf.Bytes()
+}
+
+func TestPrepareUpload_AvatarIsSquareWithThumbnail(t *testing.T) {
+	data := pngImage(t, 1000, 600)
+
+	upload, err := PrepareUpload(bytes.NewReader(data), int64(len(data)), UploadAvatar)
+
+	require.NoError(t, err)
+	assert.Equal(t, "image/jpeg", upload.ContentType)
+	assert.Equal(t, ".jpg", upload.Ext)
+	assert.Equal(t, avatarSize, upload.Width)
+	assert.Equal(t, avatarSize, upload.Height)
+	body, _ := io.ReadAll(upload.Body)
+	assert.Equal(t, upload.Size, int64(len(body)))
+	thumb, _, err := image.DecodeConfig(bytes.NewReader(upload.Thumbnail))
+	require.NoError(t, err)
+	assert.Equal(t, avatarThumbSize, thumb.Width)
+}
+
+func TestPrepareUpload_TextAttachmentPassesThrough(t *testing.T) {
+	upload, err := PrepareUpload(strings.NewReader("plain notes"), 11, UploadAttachment)
+
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain", upload.ContentType)
+	assert.Equal(t, int64(11), upload.Size)
+	assert.Nil(t, upload.Thumbnail)
+	body, _ := io.ReadAll(upload.Body)
+	assert.Equal(t, "plain notes", string(body))
+}
+
+func TestPrepareUpload_RejectsByContentNotName(t *testing.T) {
+	_, err := PrepareUpload(strings.NewReader("plain notes"), 11, UploadAvatar)
+	assert.ErrorIs(t, err, ErrUnsupportedUpload)
+
+	_, err = PrepareUpload(bytes.NewReader([]byte("MZ\x90\x00\x03\x00\x00\x00")), 8, UploadAttachment)
+	assert.ErrorIs(t, err, ErrUnsupportedUpload)
+}
+
+func TestStoreUpload(t *testing.T) {
+	ctx := context.Background()
+	store := blobstore.NewLocal(t.TempDir(), "/api/files", SignPath)
+	data := pngImage(t, 40, 40)
+	upload, err := PrepareUpload(bytes.NewReader(data), int64(len(data)), UploadAttachment)
+	require.NoError(t, err)
+
+	key, thumbKey, err := StoreUpload(ctx, store, upload, "attachments/3")
+
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, "attachments/3/"))
+	require.NotNil(t, thumbKey)
+	assert.True(t, strings.HasPrefix(*thumbKey, "attachments/3/thumbs/"))
+	for _, k := range []string{key, *thumbKey} {
+		r, _, err := store.Open(ctx, k)
+		require.NoError(t, err)
+		r.Close()
+	}
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"myapp/models"
+)
+
+// ErrUserModified là lỗi khi user đã bị sửa (version khác) trước lúc xóa
+var ErrUserModified = errors.New("user has been modified")
+
+// DeleteUser xóa user trong một transaction, chỉ khi version vẫn là user.Version.
+// Post, follow, reaction... của user bị xóa theo khóa ngoại; những gì khóa ngoại
+// không tự làm đúng được xử lý ở đây trước khi xóa:
+//   - comment của user thành tombstone (giữ cây trả lời), comment_count của post giảm theo
+//   - usage_count của tag giảm theo các post của user (post_tags bị xóa theo khóa ngoại)
+//   - reaction trên post của user (và comment trong các post đó) bị xóa cùng bộ đếm như DeletePost;
+//     reaction của user trên nội dung khác được trừ khỏi reaction_counts
+//   - follower_count/following_count của những người user theo dõi và theo dõi user giảm như Unfollow
+func DeleteUser(db *gorm.DB, user *models.User, now time.Time) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tombstoneUserComments(tx, user.ID, now); err != nil {
+			return err
+		}
+		if err := tx.Exec(`UPDATE tags t
+			JOIN (SELECT pt.tag_id, COUNT(*) AS n FROM post_tags pt
+			      JOIN posts p ON p.id = pt.post_id
+			      WHERE p.author_id = ? GROUP BY pt.tag_id) x ON x.tag_id = t.id
+			SET t.usage_count = IF(t.usage_count > x.n, t.usage_count - x.n, 0)`, user.ID).Error; err != nil {
+			return err
+		}
+		if err := deleteUserReactions(tx, user.ID); err != nil {
+			return err
+		}
+		if err := deleteUserFollows(tx, user.ID); err != nil {
+			return err
+		}
+
+		result := tx.Where("version = ?", user.Version).Delete(user)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrUserModified
+		}
+		return nil
+	})
+}
+
+// tombstoneUserComments xóa nội dung các comment chưa xóa của user như DeleteComment
+// và giảm comment_count của từng post tương ứng
+func tombstoneUserComments(tx *gorm.DB, userID uint, now time.Time) error {
+	if err := tx.Exec(`UPDATE posts p
+		JOIN (SELECT post_id, COUNT(*) AS n FROM comments
+		      WHERE author_id = ? AND deleted_at IS NULL GROUP BY post_id) c ON c.post_id = p.id
+		SET p.comment_count = IF(p.comment_count > c.n, p.comment_count - c.n, 0)`, userID).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.Comment{}).
+		Where("author_id = ? AND deleted_at IS NULL", userID).
+		Updates(map[string]interface{}{"deleted_at": now, "content": ""}).Error
+}
+
+// deleteUserReactions dọn reaction liên quan tới user: reactions không có khóa ngoại tới
+// post/comment nên reaction trên nội dung sắp bị xóa phải xóa tay; reaction của chính user
+// bị xóa theo khóa ngoại nên bộ đếm phải giảm trước
+func deleteUserReactions(tx *gorm.DB, userID uint) error {
+	posts := tx.Model(&models.Post{}).Select("id").Where("author_id = ?", userID)
+	comments := tx.Model(&models.Comment{}).Select("id").Where("post_id IN (?)", posts)
+	if err := DeleteReactions(tx, models.ReactionTargetComment, comments); err != nil {
+		return err
+	}
+	if err := DeleteReactions(tx, models.ReactionTargetPost, posts); err != nil {
+		return err
+	}
+	// Mỗi user có tối đa một reaction mỗi loại trên một đối tượng
+	return tx.Exec(`UPDATE reaction_counts rc
+		JOIN reactions r ON r.target_type = rc.target_type AND r.target_id = rc.target_id AND r.type = rc.type
+		SET rc.count = IF(rc.count > 0, rc.count - 1, 0)
+		WHERE r.user_id = ?`, userID).Error
+}
+
+// deleteUserFollows giảm bộ đếm follow của những user còn lại; các dòng follows của user
+// bị xóa theo khóa ngoại (mỗi cặp follower/followee chỉ có một dòng)
+func deleteUserFollows(tx *gorm.DB, userID uint) error {
+	if err := tx.Exec(`UPDATE users u JOIN follows f ON f.followee_id = u.id
+		SET u.follower_count = IF(u.follower_count > 0, u.follower_count - 1, 0)
+		WHERE f.follower_id = ?`, userID).Error; err != nil {
+		return err
+	}
+	return tx.Exec(`UPDATE users u JOIN follows f ON f.follower_id = u.id
+		SET u.following_count = IF(u.following_count > 0, u.following_count - 1, 0)
+		WHERE f.followee_id = ?`, userID).Error
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"archive/zip"
+	"bufio"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Định dạng export (ngoài csv/ndjson dùng chung với import)
+const FormatXLSX = "xlsx"
+
+// UserExportColumns là các cột được phép export — không bao giờ có password hay token_version
+var UserExportColumns = []string{"id", "email", "name", "role"}
+
+// ExportContentTypes là Content-Type tương ứng với từng định dạng export
+var ExportContentTypes = map[string]string{
+	FormatCSV:    "text/csv; charset=utf-8",
+	FormatNDJSON: "application/x-ndjson",
+	FormatXLSX:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+const exportProgressEvery = 1000
+
+// ParseExportColumns đọc danh sách cột dạng "id,email", rỗng nghĩa là tất cả
+func ParseExportColumns(param string) ([]string, error) {
+	if strings.TrimSpace(param) == "" {
+		return UserExportColumns, nil
+	}
+	var columns []string
+	for _, col := range strings.Split(param, ",") {
+		col = strings.ToLower(strings.TrimSpace(col))
+		if !containsString(UserExportColumns, col) {
+			return nil, fmt.Errorf("column %q cannot be exported, allowed columns: %s", col, strings.Join(UserExportColumns, ", "))
+		}
+		if !containsString(columns, col) {
+			columns = append(columns, col)
+		}
+	}
+	return columns, nil
+}
+
+// ExportUsers duyệt từng dòng của query (không load cả bảng vào bộ nhớ)
+// và ghi ra w theo định dạng yêu cầu. Trả về số dòng đã ghi.
+// progress (có thể nil) được gọi sau mỗi exportProgressEvery dòng.
+func ExportUsers(query *gorm.DB, w io.Writer, format string, columns []string, progress func(written int)) (int, error) {
+	writer, err := newExportWriter(w, format, columns)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := query.Select(columns).Order("id").Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	values := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	record := make([]string, len(columns))
+
+	count := 0
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return count, err
+		}
+		for i, v := range values {
+			record[i] = v.String
+		}
+		if err := writer.WriteRow(record); err != nil {
+			return count, err
+		}
+		count++
+		if progress != nil && count%exportProgressEvery == 0 {
+			progress(count)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+	return count, writer.Close()
+}
+
+type exportWriter interface {
+	WriteRow(record []string) error
+	Close() error
+}
+
+func newExportWriter(w io.Writer, format string, columns []string) (exportWriter, error) {
+	switch format {
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(columns); err != nil {
+			return nil, err
+		}
+		return &csvExportWriter{writer: writer}, nil
+	case FormatNDJSON:
+		return &ndjsonExportWriter{encoder: json.NewEncoder(w), columns: columns}, nil
+	case FormatXLSX:
+		return newXLSXExportWriter(w, columns)
+	default:
+		return nil, fmt.Errorf("unsupported format %q, expected csv, ndjson or xlsx", format)
+	}
+}
+
+type csvExportWriter struct {
+	writer *csv.Writer
+}
+
+func (c *csvExportWriter) WriteRow(record []string) error {
+	return c.writer.Write(record)
+}
+
+func (c *csvExportWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+type ndjsonExportWriter struct {
+	encoder *json.Encoder
+	columns []string
+}
+
+func (n *ndjsonExportWriter) WriteRow(record []string) error {
+	row := make(map[string]interface{}, len(record))
+	for i, col := range n.columns {
+		row[col] = exportValue(col, record[i])
+	}
+	return n.encoder.Encode(row)
+}
+
+func (n *ndjsonExportWriter) Close() error {
+	return nil
+}
+
+// exportValue giữ kiểu số cho cột id
+func exportValue(column, value string) interface{} {
+	if column == "id" {
+		if id, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return id
+		}
+	}
+	return value
+}
+
+// xlsxExportWriter ghi file XLSX tối giản (một sheet, inline string) theo kiểu streaming:
+// các file cố định của package được ghi trước, sheet được ghi dần từng dòng.
+type xlsxExportWriter struct {
+	zip     *zip.Writer
+	sheet   *bufio.Writer
+	columns []string
+	row     int
+}
+
+var xlsxStaticParts = []struct{ name, content string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="users" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+func newXLSXExportWriter(w io.Writer, columns []string) (*xlsxExportWriter, error) {
+	zw := zip.NewWriter(w)
+	for _, part := range xlsxStaticParts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	x := &xlsxExportWriter{zip: zw, sheet: bufio.NewWriter(f), columns: columns}
+	x.sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	// Dòng tiêu đề luôn là chuỗi
+	header := make([]string, len(columns))
+	copy(header, columns)
+	if err := x.writeCells(header, false); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+func (x *xlsxExportWriter) WriteRow(record []string) error {
+	return x.writeCells(record, true)
+}
+
+func (x *xlsxExportWriter) writeCells(record []string, typed bool) error {
+	x.row++
+	fmt.Fprintf(x.sheet, `<row r="%d">`, x.row)
+	for i, value := range record {
+		if typed && x.columns[i] == "id" {
+			fmt.Fprintf(x.sheet, `<c t="n"><v>%s</v></c>`, value)
+			continue
+		}
+		x.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		if err := xml.EscapeText(x.sheet, []byte(value)); err != nil {
+			return err
+		}
+		x.sheet.WriteString(`</t></is></c>`)
+	}
+	_, err := x.sheet.WriteString(`</row>`)
+	return err
+}
+
+func (x *xlsxExportWriter) Close() error {
+	x.sheet.WriteString(`</sheetData></worksheet>`)
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.zip.Close()
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"myapp/models"
+)
+
+func TestParseExportColumns(t *testing.T) {
+	columns, err := ParseExportColumns("")
+	assert.NoError(t, err)
+	assert.Equal(t, UserExportColumns, columns)
+
+	columns, err = ParseExportColumns(" Email, id,email")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"email", "id"}, columns)
+
+	_, err = ParseExportColumns("id,password")
+	assert.ErrorContains(t, err, `column "password" cannot be exported`)
+}
+
+func expectExportQuery(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("SELECT `id`,`name` FROM `users` ORDER BY id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+			AddRow(1, "John, Jr.").
+			AddRow(2, "Jane <3"))
+}
+
+func TestExportUsers_CSV(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	expectExportQuery(mock)
+
+	var buf bytes.Buffer
+	count, err := ExportUsers(gormDB.Model(&models.User{}), &buf, FormatCSV, []string{"id", "name"}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, "id,name\n1,\"John, Jr.\"\n2,Jane <3\n", buf.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExportUsers_NDJSON(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	expectExportQuery(mock)
+
+	var buf bytes.Buffer
+	_, err := ExportUsers(gormDB.Model(&models.User{}), &buf, FormatNDJSON, []string{"id", "name"}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"id\":1,\"name\":\"John, Jr.\"}\n{\"id\":2,\"name\":\"Jane \\u003c3\"}\n", buf.String())
+}
+
+func TestExportUsers_XLSX(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	expectExportQuery(mock)
+
+	var buf bytes.Buffer
+	_, err := ExportUsers(gormDB.Model(&models.User{}), &buf, FormatXLSX, []string{"id", "name"}, nil)
+	assert.NoError(t, err)
+
+	// File phải là zip hợp lệ với sheet chứa dữ liệu đã escape
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+
+	var sheet string
+	names := []string{}
+	for _, f := range archive.File {
+		names = append(names, f.Name)
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			rc, _ := f.Open()
+			data, _ := io.ReadAll(rc)
+			rc.Close()
+			sheet = string(data)
+		}
+	}
+	assert.Contains(t, names, "[Content_Types].xml")
+	assert.Contains(t, sheet, `<row r="1">`)
+	assert.Contains(t, sheet, `<c t="n"><v>2</v></c>`)
+	assert.Contains(t, sheet, "Jane &lt;3")
+	assert.Contains(t, sheet, "</sheetData></worksheet>")
+}
+
+func TestExportUsers_UnsupportedFormat(t *testing.T) {
+	_, gormDB := setupTestDB(t)
+
+	_, err := ExportUsers(gormDB.Model(&models.User{}), io.Discard, "pdf", UserExportColumns, nil)
+	assert.ErrorContains(t, err, "unsupported format")
+}
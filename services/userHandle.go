@@ -0,0 +1,139 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"myapp/models"
+)
+
+// Độ dài cho phép của handle (cột VARCHAR(30))
+const (
+	minHandleLength = 3
+	maxHandleLength = 30
+)
+
+// fallbackHandle dùng khi tên không có chữ/số nào dùng được
+const fallbackHandle = "user"
+
+var handleRe = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// reservedHandles không được dùng làm handle: trùng đường dẫn, vai trò hoặc mention nhóm
+var reservedHandles = map[string]bool{
+	"admin": true, "administrator": true, "all": true, "anonymous": true, "api": true,
+	"everyone": true, "help": true, "here": true, "me": true, "mod": true, "moderator": true,
+	"moderators": true, "null": true, "official": true, "root": true, "staff": true,
+	"support": true, "system": true, "undefined": true, "user": true, "users": true,
+}
+
+// Lỗi khi kiểm tra handle do user chọn
+var (
+	ErrInvalidHandle  = errors.New("handle must be 3-30 characters of a-z, 0-9 or _, starting with a letter")
+	ErrReservedHandle = errors.New("handle is reserved")
+)
+
+// NormalizeHandle bỏ "@" ở đầu và viết thường handle
+func NormalizeHandle(handle string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(handle), "@"))
+}
+
+// ValidateHandle kiểm tra handle đã chuẩn hóa có hợp lệ và không thuộc danh sách dành riêng
+func ValidateHandle(handle string) error {
+	if len(handle) < minHandleLength || len(handle) > maxHandleLength || !handleRe.MatchString(handle) {
+		return ErrInvalidHandle
+	}
+	if reservedHandles[handle] {
+		return ErrReservedHandle
+	}
+	return nil
+}
+
+// UserHandle sinh handle từ tên: "Nguyễn Văn An" -> "nguyenvanan".
+// Handle luôn hợp lệ về định dạng nhưng có thể là từ dành riêng (UniqueHandle sẽ thêm hậu tố).
+func UserHandle(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(Transliterate(name)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	handle := b.String()
+	// Chừa chỗ cho hậu tố số
+	if len(handle) > maxHandleLength-4 {
+		handle = handle[:maxHandleLength-4]
+	}
+	if handle == "" || handle[0] < 'a' {
+		handle = fallbackHandle + handle
+	}
+	for len(handle) < minHandleLength {
+		handle += "_"
+	}
+	return handle
+}
+
+// UniqueHandle trả về handle sinh từ name cho user userID (0 khi tạo mới) chưa bị user khác
+// dùng và không phải từ dành riêng: base, base2, base3...
+func UniqueHandle(tx *gorm.DB, name string, userID uint) (string, error) {
+	base := UserHandle(name)
+
+	// "_" trong LIKE khớp mọi ký tự, chỉ làm tập ứng viên rộng hơn
+	var current []string
+	if err := tx.Model(&models.User{}).Where("id <> ? AND handle LIKE ?", userID, base+"%").
+		Pluck("handle", &current).Error; err != nil {
+		return "", err
+	}
+
+	taken := map[string]bool{}
+	for _, handle := range current {
+		taken[strings.ToLower(handle)] = true
+	}
+	handle := base
+	for n := 2; taken[handle] || reservedHandles[handle]; n++ {
+		handle = base + strconv.Itoa(n)
+	}
+	return handle, nil
+}
+
+// HandleInUse kiểm tra handle đã thuộc về user khác hay chưa
+func HandleInUse(db *gorm.DB, handle string, exceptID uint) (bool, error) {
+	query := db.Model(&models.User{}).Where("handle = ?", handle)
+	if exceptID != 0 {
+		query = query.Where("id <> ?", exceptID)
+	}
+	var count int64
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+// AssignHandles sinh handle cho các user chưa có (tạo trước khi có cột handle hoặc qua import),
+// trả về số user đã cập nhật
+func AssignHandles(db *gorm.DB) (int64, error) {
+	var done int64
+	for {
+		var users []models.User
+		if err := db.Select("id", "name").Where("handle IS NULL").Order("id").Limit(renderBatchSize).Find(&users).Error; err != nil {
+			return done, err
+		}
+		if len(users) == 0 {
+			return done, nil
+		}
+		for i := range users {
+			err := db.Transaction(func(tx *gorm.DB) error {
+				handle, err := UniqueHandle(tx, users[i].Name, users[i].ID)
+				if err != nil {
+					return err
+				}
+				// UpdateColumn để không tăng version của user
+				return tx.Model(&users[i]).UpdateColumn("handle", handle).Error
+			})
+			if err != nil {
+				return done, err
+			}
+			done++
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserHandle(t *testing.T) {
+	cases := map[string]string{
+		"Nguyễn Văn An":       "nguyenvanan",
+		"  John O'Neil  ":     "johnoneil",
+		"42 Wallaby Way":      "user42wallabyway",
+		"!!!":                 "user",
+		"Al":                  "al_",
+		"Bartholomew Kuznets": "bartholomewkuznets",
+		"Đặng Thị Hồng Nhung Phương Thảo Vy": "dangthihongnhungphuongthao",
+	}
+	for name, want := range cases {
+		assert.Equal(t, want, UserHandle(name), name)
+		assert.NotEqual(t, ErrInvalidHandle, ValidateHandle(UserHandle(name)), name)
+	}
+}
+
+func TestValidateHandle(t *testing.T) {
+	assert.NoError(t, ValidateHandle("alice_99"))
+	assert.Equal(t, ErrInvalidHandle, ValidateHandle("ab"))
+	assert.Equal(t, ErrInvalidHandle, ValidateHandle("9lives"))
+	assert.Equal(t, ErrInvalidHandle, ValidateHandle("Alice"), "handle must be normalized first")
+	assert.Equal(t, ErrInvalidHandle, ValidateHandle("al-ice"))
+	assert.Equal(t, ErrInvalidHandle, ValidateHandle("abcdefghijabcdefghijabcdefghijx"))
+	assert.Equal(t, ErrReservedHandle, ValidateHandle("admin"))
+	assert.Equal(t, ErrReservedHandle, ValidateHandle(NormalizeHandle(" @Everyone ")))
+}
+
+func TestUniqueHandle_AddsSuffix(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	mock.ExpectQuery("SELECT `handle` FROM `users` WHERE id <> \\? AND handle LIKE \\?").
+		WithArgs(0, "alice%").
+		WillReturnRows(sqlmock.NewRows([]string{"handle"}).AddRow("alice").AddRow("Alice2").AddRow("alice_b"))
+
+	handle, err := UniqueHandle(gormDB, "Alice", 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "alice3", handle)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUniqueHandle_SkipsReservedWords(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	mock.ExpectQuery("SELECT `handle` FROM `users`").
+		WithArgs(5, "admin%").
+		WillReturnRows(sqlmock.NewRows([]string{"handle"}))
+
+	handle, err := UniqueHandle(gormDB, "Admin", 5)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "admin2", handle)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAssignHandles(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	mock.ExpectQuery("SELECT `id`,`name` FROM `users` WHERE handle IS NULL ORDER BY id LIMIT \\?").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3, "Trần Bình"))
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT `handle` FROM `users`").
+		WithArgs(3, "tranbinh%").
+		WillReturnRows(sqlmock.NewRows([]string{"handle"}))
+	mock.ExpectExec("UPDATE `users` SET `handle`=\\? WHERE `id` = \\?").
+		WithArgs("tranbinh", 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT `id`,`name` FROM `users` WHERE handle IS NULL").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+
+	updated, err := AssignHandles(gormDB)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), updated)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
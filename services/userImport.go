@@ -0,0 +1,457 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"myapp/models"
+)
+
+// Định dạng file import
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// Trạng thái của từng dòng trong report
+const (
+	RowCreated = "created"
+	RowUpdated = "updated"
+	RowFailed  = "failed"
+	RowSkipped = "skipped" // không được ghi vì import đã dừng
+)
+
+// Số dòng mỗi transaction nếu không cấu hình
+const DefaultImportBatchSize = 500
+
+// Các cột được phép trong file CSV
+var importColumns = []string{"email", "name", "password"}
+
+// UserImportOptions là các tùy chọn cho một lần import
+type UserImportOptions struct {
+	Format      string // csv hoặc ndjson
+	DryRun      bool   // chỉ kiểm tra, không ghi DB
+	Upsert      bool   // cập nhật user đã tồn tại (theo email) thay vì báo lỗi
+	SkipOnError bool   // bỏ qua dòng lỗi thay vì dừng import
+	BatchSize   int
+}
+
+// UserImportRow là dữ liệu của một user trong file import
+type UserImportRow struct {
+	Email    string `json:"email"`
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+// UserImportRowResult là kết quả xử lý một dòng
+type UserImportRowResult struct {
+	Line   int    `json:"line"`
+	Email  string `json:"email"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// UserImportReport tổng hợp kết quả import
+type UserImportReport struct {
+	DryRun  bool                  `json:"dry_run"`
+	Aborted bool                  `json:"aborted"`
+	Total   int                   `json:"total"`
+	Created int                   `json:"created"`
+	Updated int                   `json:"updated"`
+	Failed  int                   `json:"failed"`
+	Skipped int                   `json:"skipped"`
+	Rows    []UserImportRowResult `json:"rows,omitempty"`
+}
+
+// Summary trả về report không kèm danh sách từng dòng
+func (r *UserImportReport) Summary() UserImportReport {
+	summary := *r
+	summary.Rows = nil
+	return summary
+}
+
+// WriteCSV ghi report từng dòng ra CSV để client tải về
+func (r *UserImportReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"line", "email", "status", "error"}); err != nil {
+		return err
+	}
+	for _, row := range r.Rows {
+		if err := writer.Write([]string{strconv.Itoa(row.Line), row.Email, row.Status, row.Error}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (r *UserImportReport) add(result UserImportRowResult) {
+	r.Total++
+	switch result.Status {
+	case RowCreated:
+		r.Created++
+	case RowUpdated:
+		r.Updated++
+	case RowFailed:
+		r.Failed++
+	case RowSkipped:
+		r.Skipped++
+	}
+	r.Rows = append(r.Rows, result)
+}
+
+// ImportUsers đọc file (CSV/NDJSON) và tạo/cập nhật user theo từng batch transaction.
+// progress (có thể nil) được gọi sau mỗi batch với tổng số dòng đã xử lý.
+// Lỗi trả về chỉ dành cho lỗi không thể tiếp tục (sai định dạng file, lỗi DB...);
+// lỗi của từng dòng nằm trong report.
+func ImportUsers(db *gorm.DB, r io.Reader, opts UserImportOptions, progress func(processed int)) (*UserImportReport, error) {
+	reader, err := newUserRowReader(r, opts.Format)
+	if err != nil {
+		return nil, err
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultImportBatchSize
+	}
+
+	report := &UserImportReport{DryRun: opts.DryRun}
+	seen := map[string]int{} // email -> dòng xuất hiện đầu tiên trong file
+	batch := make([]pendingImportRow, 0, opts.BatchSize)
+
+	flush := func() (bool, error) {
+		if len(batch) == 0 {
+			return true, nil
+		}
+		ok, err := importBatch(db, batch, opts, report)
+		batch = batch[:0]
+		if progress != nil {
+			progress(report.Total)
+		}
+		return ok, err
+	}
+
+	for {
+		row, line, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+
+		pending := pendingImportRow{line: line, row: row}
+		var parseErr *rowParseError
+		switch {
+		case errors.As(err, &parseErr):
+			pending.line = parseErr.line
+			pending.err = parseErr.err
+		case err != nil:
+			return report, err
+		default:
+			pending.err = validateImportRow(&pending.row)
+			if pending.err == nil {
+				key := strings.ToLower(pending.row.Email)
+				if first, dup := seen[key]; dup {
+					pending.err = fmt.Errorf("duplicate email, first seen on line %d", first)
+				} else {
+					seen[key] = line
+				}
+			}
+		}
+
+		batch = append(batch, pending)
+		if len(batch) >= opts.BatchSize {
+			ok, err := flush()
+			if err != nil || !ok {
+				return report, err
+			}
+		}
+	}
+
+	_, err = flush()
+	return report, err
+}
+
+type pendingImportRow struct {
+	line int
+	row  UserImportRow
+	err  error
+}
+
+// errAbortBatch dùng để rollback batch khi gặp dòng lỗi ở chế độ strict
+var errAbortBatch = errors.New("import aborted")
+
+// importBatch xử lý một batch trong một transaction.
+// Trả về false nếu import phải dừng (chế độ strict gặp dòng lỗi).
+func importBatch(db *gorm.DB, batch []pendingImportRow, opts UserImportOptions, report *UserImportReport) (bool, error) {
+	// Tìm các user đã tồn tại trong batch
+	emails := make([]string, 0, len(batch))
+	for _, p := range batch {
+		if p.err == nil {
+			emails = append(emails, p.row.Email)
+		}
+	}
+	existing := map[string]bool{}
+	if len(emails) > 0 {
+		var users []models.User
+		if err := db.Select("email").Where("email IN ?", emails).Find(&users).Error; err != nil {
+			return false, err
+		}
+		for _, u := range users {
+			existing[strings.ToLower(u.Email)] = true
+		}
+	}
+
+	results := make([]UserImportRowResult, len(batch))
+	failedAt := -1
+
+	apply := func(tx *gorm.DB) error {
+		for i, p := range batch {
+			result := UserImportRowResult{Line: p.line, Email: p.row.Email}
+
+			action, err := "", p.err
+			if err == nil {
+				action, err = planImportRow(p.row, existing, opts.Upsert)
+			}
+			if err == nil && !opts.DryRun {
+				err = tx.Transaction(func(rowTx *gorm.DB) error {
+					return writeImportRow(rowTx, p.row, action)
+				})
+			}
+
+			if err != nil {
+				result.Status = RowFailed
+				result.Error = err.Error()
+				results[i] = result
+				if !opts.SkipOnError {
+					failedAt = i
+					return errAbortBatch
+				}
+				continue
+			}
+
+			result.Status = action
+			results[i] = result
+		}
+		return nil
+	}
+
+	var err error
+	if opts.DryRun {
+		err = apply(db)
+	} else {
+		err = db.Transaction(apply)
+	}
+	if err != nil && !errors.Is(err, errAbortBatch) {
+		return false, err
+	}
+
+	// Batch bị rollback: dòng lỗi giữ trạng thái failed, các dòng còn lại là skipped
+	for i := range results {
+		if failedAt >= 0 && i != failedAt {
+			results[i] = UserImportRowResult{
+				Line:   batch[i].line,
+				Email:  batch[i].row.Email,
+				Status: RowSkipped,
+			}
+		}
+		report.add(results[i])
+	}
+	if failedAt >= 0 {
+		report.Aborted = true
+		return false, nil
+	}
+	return true, nil
+}
+
+// planImportRow quyết định tạo mới hay cập nhật user
+func planImportRow(row UserImportRow, existing map[string]bool, upsert bool) (string, error) {
+	if existing[strings.ToLower(row.Email)] {
+		if !upsert {
+			return "", errors.New("email already exists")
+		}
+		return RowUpdated, nil
+	}
+	if row.Password == "" {
+		return "", errors.New("password is required for new users")
+	}
+	return RowCreated, nil
+}
+
+// writeImportRow ghi một dòng vào DB (BeforeCreate sẽ hash password khi tạo mới)
+func writeImportRow(tx *gorm.DB, row UserImportRow, action string) error {
+	if action == RowCreated {
+		handle, err := UniqueHandle(tx, row.Name, 0)
+		if err != nil {
+			return err
+		}
+		user := models.User{Email: row.Email, Name: row.Name, Handle: handle, Password: row.Password, Role: models.RoleUser}
+		return tx.Create(&user).Error
+	}
+
+	updates := map[string]interface{}{"name": row.Name}
+	if row.Password != "" {
+		hash, err := models.HashPassword(row.Password)
+		if err != nil {
+			return err
+		}
+		updates["password"] = hash
+	}
+	return tx.Model(&models.User{}).Where("email = ?", row.Email).Updates(updates).Error
+}
+
+// validateImportRow chuẩn hóa và kiểm tra dữ liệu một dòng
+func validateImportRow(row *UserImportRow) error {
+	row.Email = strings.TrimSpace(row.Email)
+	row.Name = strings.TrimSpace(row.Name)
+
+	if row.Email == "" {
+		return errors.New("email is required")
+	}
+	if addr, err := mail.ParseAddress(row.Email); err != nil || addr.Address != row.Email {
+		return errors.New("email is invalid")
+	}
+	if len(row.Email) > 255 {
+		return errors.New("email is too long")
+	}
+	if row.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(row.Name) > 255 {
+		return errors.New("name is too long")
+	}
+	if row.Password != "" && (len(row.Password) < 8 || len(row.Password) > 72) {
+		return errors.New("password must be between 8 and 72 characters")
+	}
+	return nil
+}
+
+// rowParseError là lỗi của riêng một dòng, import vẫn có thể tiếp tục
+type rowParseError struct {
+	line int
+	err  error
+}
+
+func (e *rowParseError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.line, e.err)
+}
+
+type userRowReader interface {
+	// Next trả về dòng tiếp theo và số dòng trong file, io.EOF khi hết
+	Next() (UserImportRow, int, error)
+}
+
+func newUserRowReader(r io.Reader, format string) (userRowReader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVRowReader(r)
+	case FormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		return &ndjsonRowReader{scanner: scanner}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q, expected csv or ndjson", format)
+	}
+}
+
+type csvRowReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVRowReader(r io.Reader) (*csvRowReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("csv file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv header: %w", err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !containsString(importColumns, name) {
+			return nil, fmt.Errorf("unknown column %q, allowed columns: %s", name, strings.Join(importColumns, ", "))
+		}
+		columns[name] = i
+	}
+	for _, required := range []string{"email", "name"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing required column %q", required)
+		}
+	}
+	return &csvRowReader{reader: reader, columns: columns}, nil
+}
+
+func (c *csvRowReader) Next() (UserImportRow, int, error) {
+	record, err := c.reader.Read()
+	if err == io.EOF {
+		return UserImportRow{}, 0, io.EOF
+	}
+	line, _ := c.reader.FieldPos(0)
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return UserImportRow{}, 0, &rowParseError{line: parseErr.StartLine, err: parseErr.Err}
+		}
+		return UserImportRow{}, 0, err
+	}
+	if len(record) != len(c.columns) {
+		return UserImportRow{}, 0, &rowParseError{line: line, err: fmt.Errorf("expected %d fields, got %d", len(c.columns), len(record))}
+	}
+
+	field := func(name string) string {
+		if i, ok := c.columns[name]; ok {
+			return record[i]
+		}
+		return ""
+	}
+	return UserImportRow{Email: field("email"), Name: field("name"), Password: field("password")}, line, nil
+}
+
+type ndjsonRowReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (n *ndjsonRowReader) Next() (UserImportRow, int, error) {
+	for n.scanner.Scan() {
+		n.line++
+		data := bytes.TrimSpace(n.scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var row UserImportRow
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&row); err != nil {
+			return UserImportRow{}, 0, &rowParseError{line: n.line, err: fmt.Errorf("invalid json: %v", err)}
+		}
+		return row, n.line, nil
+	}
+	if err := n.scanner.Err(); err != nil {
+		return UserImportRow{}, 0, err
+	}
+	return UserImportRow{}, 0, io.EOF
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
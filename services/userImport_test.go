@@ -0,0 +1,151 @@
+package services
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// setupTestDB tạo mock database cho testing
+func setupTestDB(t *testing.T) (sqlmock.Sqlmock, *gorm.DB) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      db,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{})
+	assert.NoError(t, err)
+
+	return mock, gormDB
+}
+
+func TestImportUsers_UnknownCSVColumn(t *testing.T) {
+	_, gormDB := setupTestDB(t)
+
+	input := "email,name,role\njohn@example.com,John,admin\n"
+	report, err := ImportUsers(gormDB, strings.NewReader(input), UserImportOptions{Format: FormatCSV}, nil)
+
+	assert.Nil(t, report)
+	assert.ErrorContains(t, err, `unknown column "role"`)
+}
+
+func TestImportUsers_MissingRequiredColumn(t *testing.T) {
+	_, gormDB := setupTestDB(t)
+
+	report, err := ImportUsers(gormDB, strings.NewReader("email\njohn@example.com\n"), UserImportOptions{Format: FormatCSV}, nil)
+
+	assert.Nil(t, report)
+	assert.ErrorContains(t, err, `missing required column "name"`)
+}
+
+func TestImportUsers_DryRunReportsEveryRow(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	// Chỉ có câu SELECT kiểm tra email tồn tại, không có INSERT nào
+	mock.ExpectQuery("SELECT `email` FROM `users` WHERE email IN \\(\\?,\\?\\)").
+		WithArgs("john@example.com", "jane@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("jane@example.com"))
+
+	input := strings.Join([]string{
+		"email,name,password",
+		"john@example.com,John,password123",
+		"jane@example.com,Jane,",
+		"not-an-email,Bob,password123",
+		"john@example.com,John again,password123",
+	}, "\n")
+	opts := UserImportOptions{Format: FormatCSV, DryRun: true, Upsert: true, SkipOnError: true}
+	report, err := ImportUsers(gormDB, strings.NewReader(input), opts, nil)
+
+	assert.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 4, report.Total)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 1, report.Updated)
+	assert.Equal(t, 2, report.Failed)
+	assert.Equal(t, "email is invalid", report.Rows[2].Error)
+	assert.Equal(t, 4, report.Rows[2].Line)
+	assert.Contains(t, report.Rows[3].Error, "duplicate email")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImportUsers_StrictModeAbortsBatch(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	mock.ExpectQuery("SELECT `email` FROM `users` WHERE email IN").
+		WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("jane@example.com"))
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT `handle` FROM `users`").WillReturnRows(sqlmock.NewRows([]string{"handle"}))
+	mock.ExpectExec("INSERT INTO `users`").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectRollback()
+
+	input := `{"email":"john@example.com","name":"John","password":"password123"}
+{"email":"jane@example.com","name":"Jane","password":"password123"}
+{"email":"bob@example.com","name":"Bob","password":"password123"}
+`
+	report, err := ImportUsers(gormDB, strings.NewReader(input), UserImportOptions{Format: FormatNDJSON}, nil)
+
+	assert.NoError(t, err)
+	assert.True(t, report.Aborted)
+	assert.Equal(t, 0, report.Created)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 2, report.Skipped)
+	assert.Equal(t, "email already exists", report.Rows[1].Error)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImportUsers_InvalidNDJSONLine(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	mock.ExpectQuery("SELECT `email` FROM `users` WHERE email IN").
+		WillReturnRows(sqlmock.NewRows([]string{"email"}))
+
+	input := "{\"email\":\"john@example.com\",\"name\":\"John\",\"password\":\"password123\"}\n\n{\"email\":\n"
+	opts := UserImportOptions{Format: FormatNDJSON, DryRun: true, SkipOnError: true}
+	report, err := ImportUsers(gormDB, strings.NewReader(input), opts, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 3, report.Rows[1].Line)
+	assert.Contains(t, report.Rows[1].Error, "invalid json")
+}
+
+func TestImportUsers_BatchesAndProgress(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+
+	for i := 0; i < 2; i++ {
+		mock.ExpectQuery("SELECT `email` FROM `users` WHERE email IN").
+			WillReturnRows(sqlmock.NewRows([]string{"email"}))
+	}
+
+	input := "email,name,password\na@example.com,A,password123\nb@example.com,B,password123\nc@example.com,C,password123\n"
+	var progress []int
+	opts := UserImportOptions{Format: FormatCSV, DryRun: true, BatchSize: 2}
+	report, err := ImportUsers(gormDB, strings.NewReader(input), opts, func(processed int) {
+		progress = append(progress, processed)
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, report.Created)
+	assert.Equal(t, []int{2, 3}, progress)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserImportReport_WriteCSV(t *testing.T) {
+	report := &UserImportReport{}
+	report.add(UserImportRowResult{Line: 2, Email: "john@example.com", Status: RowCreated})
+	report.add(UserImportRowResult{Line: 3, Email: "bad", Status: RowFailed, Error: "email is invalid"})
+
+	var buf bytes.Buffer
+	assert.NoError(t, report.WriteCSV(&buf))
+	assert.Equal(t, "line,email,status,error\n2,john@example.com,created,\n3,bad,failed,email is invalid\n", buf.String())
+	assert.Equal(t, 2, report.Summary().Total)
+	assert.Nil(t, report.Summary().Rows)
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"myapp/models"
+)
+
+// viewBucket là độ dài một khung thống kê lượt xem (bảng post_view_stats)
+const viewBucket = time.Hour
+
+// viewKey là một người xem trên một post, dùng để bỏ lượt xem lặp lại
+type viewKey struct {
+	postID uint
+	viewer string
+}
+
+// viewCount là số lượt xem của một post trong một khung giờ
+type viewCount struct {
+	postID uint
+	bucket time.Time
+}
+
+// ViewAggregator đếm lượt xem post trong bộ nhớ và ghi vào DB theo lô, nên request đọc post
+// không phải ghi thêm dòng nào. Một người xem chỉ được tính một lần cho mỗi post trong window.
+// Khi chạy nhiều instance, mỗi instance khử trùng riêng nên một người có thể được tính lại
+// nếu request rơi vào instance khác.
+type ViewAggregator struct {
+	db     *gorm.DB
+	window time.Duration
+
+	mu      sync.Mutex
+	seen    map[viewKey]time.Time // lần cuối được tính
+	pending map[viewCount]uint    // chưa ghi vào DB
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewViewAggregator tạo bộ đếm lượt xem, bỏ các lượt xem lặp lại trong window
+func NewViewAggregator(db *gorm.DB, window time.Duration) *ViewAggregator {
+	return &ViewAggregator{
+		db:      db,
+		window:  window,
+		seen:    map[viewKey]time.Time{},
+		pending: map[viewCount]uint{},
+	}
+}
+
+// Record ghi nhận viewer (ví dụ "user:7") xem post lúc now. Trả về false nếu viewer
+// đã được tính cho post này trong window.
+func (a *ViewAggregator) Record(postID uint, viewer string, now time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := viewKey{postID: postID, viewer: viewer}
+	if last, ok := a.seen[key]; ok && now.Sub(last) < a.window {
+		return false
+	}
+	a.seen[key] = now
+	a.pending[viewCount{postID: postID, bucket: now.UTC().Truncate(viewBucket)}]++
+	return true
+}
+
+// Flush ghi các lượt xem đang chờ vào DB: cộng posts.view_count và post_view_stats theo giờ.
+// Lỗi thì các lượt xem được giữ lại cho lần flush sau. Đồng thời dọn các viewer đã hết window.
+func (a *ViewAggregator) Flush(now time.Time) error {
+	a.mu.Lock()
+	pending := a.pending
+	a.pending = map[viewCount]uint{}
+	for key, last := range a.seen {
+		if now.Sub(last) >= a.window {
+			delete(a.seen, key)
+		}
+	}
+	a.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+	if err := writeViews(a.db, pending); err != nil {
+		a.mu.Lock()
+		for key, n := range pending {
+			a.pending[key] += n
+		}
+		a.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Start flush theo chu kỳ interval cho tới khi gọi Stop
+func (a *ViewAggregator) Start(interval time.Duration) {
+	a.stop = make(chan struct{})
+	a.done = make(chan struct{})
+	go func() {
+		defer close(a.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := a.Flush(time.Now()); err != nil {
+					log.Printf("❌ Ghi lượt xem post thất bại: %v", err)
+				}
+			case <-a.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop dừng flush định kỳ và ghi nốt các lượt xem còn lại (gọi khi tắt server)
+func (a *ViewAggregator) Stop() error {
+	if a.stop != nil {
+		close(a.stop)
+		<-a.done
+		a.stop = nil
+	}
+	return a.Flush(time.Now())
+}
+
+// writeViews cộng các lượt xem vào DB trong một transaction. Lượt xem của post đã bị xóa bị bỏ.
+func writeViews(db *gorm.DB, pending map[viewCount]uint) error {
+	totals := map[uint]uint{}
+	ids := make([]uint, 0, len(pending))
+	for key, n := range pending {
+		if _, ok := totals[key.postID]; !ok {
+			ids = append(ids, key.postID)
+		}
+		totals[key.postID] += n
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var existing []uint
+		if err := tx.Model(&models.Post{}).Where("id IN ?", ids).Order("id").Pluck("id", &existing).Error; err != nil {
+			return err
+		}
+		if len(existing) == 0 {
+			return nil
+		}
+		exists := make(map[uint]bool, len(existing))
+		for _, postID := range existing {
+			exists[postID] = true
+			// view_count là cột chỉ đọc với GORM; không đổi updated_at của post
+			if err := tx.Exec("UPDATE posts SET view_count = view_count + ? WHERE id = ?", totals[postID], postID).Error; err != nil {
+				return err
+			}
+		}
+
+		stats := make([]models.PostViewStat, 0, len(pending))
+		for key, n := range pending {
+			if exists[key.postID] {
+				stats = append(stats, models.PostViewStat{PostID: key.postID, BucketStart: key.bucket, Views: n})
+			}
+		}
+		sort.Slice(stats, func(i, j int) bool {
+			if stats[i].PostID != stats[j].PostID {
+				return stats[i].PostID < stats[j].PostID
+			}
+			return stats[i].BucketStart.Before(stats[j].BucketStart)
+		})
+		return tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]interface{}{"views": gorm.Expr("views + VALUES(views)")}),
+		}).Create(&stats).Error
+	})
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestViewAggregator_DeduplicatesWithinWindow(t *testing.T) {
+	a := NewViewAggregator(nil, 30*time.Minute)
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	assert.True(t, a.Record(1, "user:7", now))
+	assert.False(t, a.Record(1, "user:7", now.Add(10*time.Minute)))
+	assert.True(t, a.Record(1, "user:8", now))
+	assert.True(t, a.Record(2, "user:7", now))
+	assert.True(t, a.Record(1, "user:7", now.Add(30*time.Minute)))
+
+	assert.Equal(t, uint(3), a.pending[viewCount{postID: 1, bucket: now}])
+}
+
+func TestViewAggregator_FlushWritesIncrements(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	a := NewViewAggregator(gormDB, time.Minute)
+	now := time.Date(2024, 5, 1, 10, 20, 0, 0, time.UTC)
+	a.Record(1, "user:7", now)
+	a.Record(1, "user:8", now)
+	a.Record(2, "user:7", now)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT `id` FROM `posts` WHERE id IN \\(\\?,\\?\\) ORDER BY id").
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec("UPDATE posts SET view_count = view_count \\+ \\? WHERE id = \\?").
+		WithArgs(2, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO `post_view_stats` .* ON DUPLICATE KEY UPDATE `views`=views \\+ VALUES\\(views\\)").
+		WithArgs(1, now.Truncate(time.Hour), 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, a.Flush(now.Add(2*time.Minute)))
+	assert.Empty(t, a.pending)
+	assert.Empty(t, a.seen)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestViewAggregator_FlushKeepsViewsOnError(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	a := NewViewAggregator(gormDB, time.Minute)
+	now := time.Date(2024, 5, 1, 10, 20, 0, 0, time.UTC)
+	a.Record(1, "user:7", now)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT `id` FROM `posts`").WillReturnError(errors.New("db down"))
+	mock.ExpectRollback()
+
+	assert.Error(t, a.Flush(now))
+	assert.Equal(t, uint(1), a.pending[viewCount{postID: 1, bucket: now.Truncate(time.Hour)}])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTrendingPosts(t *testing.T) {
+	mock, gormDB := setupTestDB(t)
+	now := time.Date(2024, 5, 1, 10, 20, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT activity.post_id, SUM\\(activity.score\\) AS score FROM \\(.* UNION ALL .*\\) activity JOIN posts .* LIMIT \\?").
+		WithArgs(now, 3600.0, time.Date(2024, 4, 30, 10, 0, 0, 0, time.UTC),
+			TrendingReactionWeight, now, 3600.0, "post", now.Add(-24*time.Hour), "published", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "score"}).AddRow(4, 12.5).AddRow(2, 3.0))
+
+	ranked, err := TrendingPosts(gormDB, now, now.Add(-24*time.Hour), time.Hour, 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []TrendingPost{{PostID: 4, Score: 12.5}, {PostID: 2, Score: 3.0}}, ranked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
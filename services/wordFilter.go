@@ -0,0 +1,104 @@
+package services
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// WordFilter tìm các từ/cụm từ cấm trong nội dung. So khớp theo nguyên từ, không phân biệt
+// hoa thường và dấu tiếng Việt: "cấm" khớp "Cam" nhưng không khớp "camera".
+type WordFilter struct {
+	words   []string
+	phrases [][]string
+}
+
+// NewWordFilter tạo bộ lọc từ danh sách từ/cụm từ, bỏ qua dòng trống và trùng lặp
+func NewWordFilter(words []string) *WordFilter {
+	f := &WordFilter{}
+	seen := map[string]bool{}
+	for _, word := range words {
+		tokens := wordTokens(word)
+		key := strings.Join(tokens, " ")
+		if len(tokens) == 0 || seen[key] {
+			continue
+		}
+		seen[key] = true
+		f.words = append(f.words, strings.TrimSpace(word))
+		f.phrases = append(f.phrases, tokens)
+	}
+	return f
+}
+
+// LoadWordFilter đọc danh sách từ cấm từ list (phân tách bằng dấu phẩy) và file (mỗi dòng
+// một từ, dòng bắt đầu bằng # là ghi chú). Trả về nil nếu không có từ nào.
+func LoadWordFilter(list, file string) (*WordFilter, error) {
+	words := strings.Split(list, ",")
+	if file != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); !strings.HasPrefix(line, "#") {
+				words = append(words, line)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+	filter := NewWordFilter(words)
+	if len(filter.words) == 0 {
+		return nil, nil
+	}
+	return filter, nil
+}
+
+// Words trả về danh sách từ/cụm từ cấm
+func (f *WordFilter) Words() []string {
+	return append([]string{}, f.words...)
+}
+
+// Match trả về các từ cấm xuất hiện trong texts, theo thứ tự trong danh sách
+func (f *WordFilter) Match(texts ...string) []string {
+	var tokens []string
+	for _, text := range texts {
+		// Ranh giới giữa các text để cụm từ không khớp nối qua hai phần
+		tokens = append(append(tokens, wordTokens(text)...), "")
+	}
+
+	var matched []string
+	for i, phrase := range f.phrases {
+		if containsPhrase(tokens, phrase) {
+			matched = append(matched, f.words[i])
+		}
+	}
+	return matched
+}
+
+func containsPhrase(tokens, phrase []string) bool {
+	for start := 0; start+len(phrase) <= len(tokens); start++ {
+		found := true
+		for j, word := range phrase {
+			if tokens[start+j] != word {
+				found = false
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// wordTokens tách text thành các từ đã bỏ dấu và viết thường
+func wordTokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(Transliterate(text)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
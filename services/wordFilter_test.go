@@ -0,0 +1,36 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWordFilter_Match(t *testing.T) {
+	filter := NewWordFilter([]string{"spam", "Đồ ngốc", "buy now", "", "SPAM"})
+
+	assert.Equal(t, []string{"spam", "Đồ ngốc", "buy now"}, filter.Words())
+	assert.Equal(t, []string{"spam"}, filter.Match("This is SPAM!"))
+	assert.Equal(t, []string{"Đồ ngốc"}, filter.Match("bạn là đồ   NGỐC"), "diacritics, case and spacing are ignored")
+	assert.Equal(t, []string{"spam", "buy now"}, filter.Match("Buy now", "no spam here"))
+	assert.Empty(t, filter.Match("spammers buying nowhere"), "only whole words match")
+	assert.Empty(t, filter.Match("buy", "now"), "phrases do not span separate texts")
+}
+
+func TestLoadWordFilter(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "banned.txt")
+	assert.NoError(t, os.WriteFile(file, []byte("# ghi chú\nscam\n\n"), 0o600))
+
+	filter, err := LoadWordFilter("spam, eggs", file)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"spam", "eggs", "scam"}, filter.Words())
+
+	filter, err = LoadWordFilter(" , ", "")
+	assert.NoError(t, err)
+	assert.Nil(t, filter)
+
+	_, err = LoadWordFilter("", filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+}
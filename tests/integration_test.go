@@ -87,14 +87,14 @@ func TestUserRegistrationAndLogin(t *testing.T) {
 
 	// Step 2: Login với user vừa tạo
 	t.Run("Login User", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"id", "name", "email", "phone"}).
-			AddRow(1, "Alice Smith", "alice@example.com", "1234567890")
+		rows := sqlmock.NewRows([]string{"id", "name", "email", "phone", "password"}).
+			AddRow(1, "Alice Smith", "alice@example.com", "1234567890", "secret123")
 
 		mock.ExpectQuery("SELECT \\* FROM `users` WHERE email = \\? ORDER BY").
 			WithArgs("alice@example.com").
 			WillReturnRows(rows)
 
-		loginData := map[string]string{"email": "alice@example.com"}
+		loginData := map[string]string{"email": "alice@example.com", "password": "secret123"}
 		body, _ := json.Marshal(loginData)
 		req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
 		req.Header.Set("Content-Type", "application/json")
@@ -210,14 +210,14 @@ func TestCompleteUserFlow(t *testing.T) {
 		emails := []string{"user1@example.com", "user2@example.com", "user3@example.com"}
 
 		for i, email := range emails {
-			rows := sqlmock.NewRows([]string{"id", "name", "email", "phone"}).
-				AddRow(i+1, "User "+string(rune(i+1)), email, string(rune(i+1))+string(rune(i+1))+string(rune(i+1))+string(rune(i+1)))
+			rows := sqlmock.NewRows([]string{"id", "name", "email", "phone", "password"}).
+				AddRow(i+1, "User "+string(rune(i+1)), email, string(rune(i+1))+string(rune(i+1))+string(rune(i+1))+string(rune(i+1)), "secret123")
 
 			mock.ExpectQuery("SELECT \\* FROM `users` WHERE email = \\? ORDER BY").
 				WithArgs(email).
 				WillReturnRows(rows)
 
-			loginData := map[string]string{"email": email}
+			loginData := map[string]string{"email": email, "password": "secret123"}
 			body, _ := json.Marshal(loginData)
 			req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
 			req.Header.Set("Content-Type", "application/json")
@@ -285,7 +285,7 @@ func TestErrorHandling(t *testing.T) {
 			WithArgs("notfound@example.com").
 			WillReturnError(gorm.ErrRecordNotFound)
 
-		loginData := map[string]string{"email": "notfound@example.com"}
+		loginData := map[string]string{"email": "notfound@example.com", "password": "secret123"}
 		body, _ := json.Marshal(loginData)
 		req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
 		req.Header.Set("Content-Type", "application/json")
@@ -0,0 +1,180 @@
+package textdiff
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Các loại thao tác trong kết quả diff
+const (
+	OpEqual  = "equal"
+	OpInsert = "insert"
+	OpDelete = "delete"
+)
+
+// maxEditDistance giới hạn số bước sửa Myers được tìm; vượt quá thì coi như
+// thay toàn bộ để tránh tốn bộ nhớ với văn bản khác nhau quá nhiều
+const maxEditDistance = 2000
+
+// Edit là một đoạn liên tiếp có cùng thao tác
+type Edit struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// Lines so sánh a và b theo từng dòng
+func Lines(a, b string) []Edit {
+	return diff(splitLines(a), splitLines(b))
+}
+
+// Words so sánh a và b theo từng từ (khoảng trắng được giữ nguyên như một token)
+func Words(a, b string) []Edit {
+	return diff(splitWords(a), splitWords(b))
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func splitWords(s string) []string {
+	var tokens []string
+	start, inSpace := 0, false
+	for i, r := range s {
+		space := unicode.IsSpace(r)
+		if i > start && space != inSpace {
+			tokens = append(tokens, s[start:i])
+			start = i
+		}
+		inSpace = space
+	}
+	if start < len(s) {
+		tokens = append(tokens, s[start:])
+	}
+	return tokens
+}
+
+func diff(a, b []string) []Edit {
+	// Bỏ phần đầu và phần cuối giống nhau trước khi chạy Myers
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var out editList
+	out.add(OpEqual, a[:prefix]...)
+	middle, ok := myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+	if !ok {
+		out.add(OpDelete, a[prefix:len(a)-suffix]...)
+		out.add(OpInsert, b[prefix:len(b)-suffix]...)
+	} else {
+		for _, e := range middle {
+			out.add(e.Op, e.Text)
+		}
+	}
+	out.add(OpEqual, a[len(a)-suffix:]...)
+	return out
+}
+
+// myers tìm chuỗi thao tác ngắn nhất (thuật toán O(ND) của Myers).
+// Trả về false nếu số bước sửa vượt maxEditDistance.
+func myers(a, b []string) ([]Edit, bool) {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil, true
+	}
+
+	// v[k] là x xa nhất trên đường chéo k; trace[d] lưu v (đoạn -d..d) trước bước d
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+	for d := 0; d <= n+m; d++ {
+		if d > maxEditDistance {
+			return nil, false
+		}
+		snapshot := make([]int, 2*d+1)
+		copy(snapshot, v[offset-d:offset+d+1])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace), true
+			}
+		}
+	}
+	return nil, false
+}
+
+func backtrack(a, b []string, trace [][]int) []Edit {
+	var edits []Edit
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d] // chỉ số k nằm ở v[k+d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[k-1+d] < v[k+1+d]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[prevK+d]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			edits = append(edits, Edit{OpEqual, a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			edits = append(edits, Edit{OpInsert, b[y-1]})
+		} else {
+			edits = append(edits, Edit{OpDelete, a[x-1]})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		edits = append(edits, Edit{OpEqual, a[x-1]})
+		x--
+		y--
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+// editList gộp các token liền nhau có cùng thao tác
+type editList []Edit
+
+func (l *editList) add(op string, tokens ...string) {
+	for _, token := range tokens {
+		if n := len(*l); n > 0 && (*l)[n-1].Op == op {
+			(*l)[n-1].Text += token
+		} else {
+			*l = append(*l, Edit{Op: op, Text: token})
+		}
+	}
+}
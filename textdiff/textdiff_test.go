@@ -0,0 +1,79 @@
+package textdiff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// apply dựng lại hai văn bản từ kết quả diff
+func apply(edits []Edit) (before, after string) {
+	var a, b strings.Builder
+	for _, e := range edits {
+		if e.Op != OpInsert {
+			a.WriteString(e.Text)
+		}
+		if e.Op != OpDelete {
+			b.WriteString(e.Text)
+		}
+	}
+	return a.String(), b.String()
+}
+
+func TestLines(t *testing.T) {
+	a := "one\ntwo\nthree\nfour\n"
+	b := "one\n2\nthree\nfour\nfive\n"
+
+	edits := Lines(a, b)
+
+	assert.Equal(t, []Edit{
+		{OpEqual, "one\n"},
+		{OpDelete, "two\n"},
+		{OpInsert, "2\n"},
+		{OpEqual, "three\nfour\n"},
+		{OpInsert, "five\n"},
+	}, edits)
+}
+
+func TestWords(t *testing.T) {
+	edits := Words("the quick brown fox", "the slow brown fox jumps")
+
+	assert.Equal(t, []Edit{
+		{OpEqual, "the "},
+		{OpDelete, "quick"},
+		{OpInsert, "slow"},
+		{OpEqual, " brown fox"},
+		{OpInsert, " jumps"},
+	}, edits)
+}
+
+func TestDiff_Reconstructs(t *testing.T) {
+	cases := [][2]string{
+		{"", ""},
+		{"", "new text"},
+		{"old text", ""},
+		{"a b c d e f", "a c e g f b"},
+		{"Xin chào thế giới", "Chào thế giới mới"},
+		{"same", "same"},
+	}
+	for _, tc := range cases {
+		for _, edits := range [][]Edit{Lines(tc[0], tc[1]), Words(tc[0], tc[1])} {
+			before, after := apply(edits)
+			assert.Equal(t, tc[0], before)
+			assert.Equal(t, tc[1], after)
+		}
+	}
+}
+
+func TestDiff_TooManyEditsFallsBackToReplace(t *testing.T) {
+	var a, b strings.Builder
+	for i := 0; i < maxEditDistance; i++ {
+		a.WriteString("a\n")
+		b.WriteString("b\n")
+	}
+
+	edits := Lines(a.String(), b.String())
+
+	assert.Equal(t, []Edit{{OpDelete, a.String()}, {OpInsert, b.String()}}, edits)
+}